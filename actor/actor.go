@@ -1,10 +1,13 @@
 package actor
 
 import (
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	xerror "github.com/75912001/xlib/error"
 	xevent "github.com/75912001/xlib/event"
+	xlog "github.com/75912001/xlib/log"
 	xmap "github.com/75912001/xlib/map"
 	xpool "github.com/75912001/xlib/pool"
 	xruntime "github.com/75912001/xlib/runtime"
@@ -14,26 +17,69 @@ import (
 
 // Actor 表示一个 actor 实例
 type Actor[KEY comparable] struct {
-	key      KEY             // actor 的唯一标识
-	msgMgr   *xevent.ListMgr // 消息-管理器
-	behavior Behavior        // 行为函数
-	parent   *Actor[KEY]     // 父 actor, 可以为 nil
+	key       KEY             // actor 的唯一标识
+	msgMgr    *xevent.ListMgr // 消息-管理器
+	behavior  Behavior        // 行为函数
+	lifecycle ILifecycle      // 生命周期钩子, 可以为 nil
+	options   *Options        // 选项
+	parent    *Actor[KEY]     // 父 actor, 可以为 nil
+	path      string          // 路径 e.g.: /server/scene/42
+	system    *System         // 所属 actor 系统
+	stopped   atomic.Bool     // 是否已停止. 停止后的消息投递到死信
 
-	childMgr *xmap.MapMgr[KEY, *Actor[KEY]] // 子 actor 管理器
+	childMgr       *xmap.MapMgr[KEY, *Actor[KEY]] // 子 actor 管理器
+	restartHistory map[KEY][]time.Time            // 子 actor 重启记录 [监督]
+
+	timerMap map[string]*actorTimer // 定时器 key: 名称
+
+	suspended bool  // 是否挂起. 失败后等待父 actor 处理期间, 用户消息暂存在 stash 中
+	stash     []any // 挂起期间暂存的消息
 
 	Statistics *xstatistics.Statistics // 统计数据
 }
 
 // NewActor 创建一个新的 actor
+//
+//	重启时, 行为恢复为 behavior
 func NewActor[KEY comparable](key KEY, parent *Actor[KEY], behavior Behavior) *Actor[KEY] {
+	return NewActorWithOptions(key, parent, NewOptions().WithFactory(BehaviorFactory(behavior)))
+}
+
+// NewActorWithOptions 创建一个新的 actor
+//
+//	配置错误会 panic
+func NewActorWithOptions[KEY comparable](key KEY, parent *Actor[KEY], opts ...*Options) *Actor[KEY] {
+	opt := mergeOptions(opts...)
+	if err := configureOptions(opt); err != nil {
+		panic(errors.WithMessagef(err, "key:%v %v", key, xruntime.Location()))
+	}
 	actor := &Actor[KEY]{
-		key:        key,
-		behavior:   behavior,
-		parent:     parent,
-		childMgr:   xmap.NewMapMgr[KEY, *Actor[KEY]](),
-		Statistics: xstatistics.NewStatistics(),
+		key:            key,
+		options:        opt,
+		parent:         parent,
+		childMgr:       xmap.NewMapMgr[KEY, *Actor[KEY]](),
+		restartHistory: make(map[KEY][]time.Time),
+		timerMap:       make(map[string]*actorTimer),
+		Statistics:     xstatistics.NewStatistics(),
+	}
+	name := fmt.Sprint(key)
+	if opt.name != nil {
+		name = *opt.name
+	}
+	actor.system = opt.system
+	if parent != nil {
+		actor.path = parent.path + PathSeparator + name
+		if actor.system == nil {
+			actor.system = parent.system
+		}
+	} else {
+		actor.path = PathSeparator + name
+	}
+	if actor.system == nil {
+		actor.system = GSystem
 	}
-	actor.msgMgr = xevent.NewListMgr(1, actor.process)
+	actor.behavior, actor.lifecycle = opt.factory()
+	actor.msgMgr = xevent.NewListMgrWithOptions(1, actor.process, opt.mailbox, xevent.NewListOptions().WithUnboundedFunc(isUnboundedMsg))
 	return actor
 }
 
@@ -41,18 +87,55 @@ func (p *Actor[KEY]) GetKey() KEY {
 	return p.key
 }
 
+// GetPath 获取路径
+func (p *Actor[KEY]) GetPath() string {
+	return p.path
+}
+
+// GetSystem 获取所属 actor 系统
+func (p *Actor[KEY]) GetSystem() *System {
+	return p.system
+}
+
+// Watch 监视 path, path 停止时收到 *Terminated
+func (p *Actor[KEY]) Watch(path string) {
+	p.system.Watch(p, path)
+}
+
+// Unwatch 取消监视 path
+func (p *Actor[KEY]) Unwatch(path string) {
+	p.system.Unwatch(p, path)
+}
+
 // GetParent 获取父 actor
 func (p *Actor[KEY]) GetParent() *Actor[KEY] {
 	return p.parent
 }
 
 // Start 启动actor
+//
+//	注册到 actor 系统(路径重复时记录日志, 不注册),
+//	在处理协程启动前调用 ILifecycle.PreStart, 失败时交由父 actor 监督处理
 func (p *Actor[KEY]) Start() {
+	if err := p.system.register(p); err != nil {
+		xlog.PrintErr(err)
+	}
+	if p.lifecycle != nil {
+		if err := p.lifecycle.PreStart(); err != nil {
+			p.fail(errors.WithMessagef(err, "actor PreStart key:%v %v", p.key, xruntime.Location()))
+		}
+	}
 	p.msgMgr.Start()
 }
 
 // Send 发送消息到 actor (异步)
+//
+//	actor 已停止时, 消息投递到死信
 func (p *Actor[KEY]) Send(messages ...any) {
+	if p.stopped.Load() {
+		p.deadLetter(messages...)
+		return
+	}
 	p.msgMgr.Send(messages...)
 }
 
@@ -63,7 +146,41 @@ func (p *Actor[KEY]) SendMsg(messages ...*Msg) {
 	for i, msg := range messages {
 		messagesAny[i] = msg
 	}
-	p.msgMgr.Send(messagesAny...)
+	p.Send(messagesAny...)
+}
+
+// TrySend 发送消息到 actor (异步)
+//
+//	邮箱满时按邮箱的 FullPolicy 处理, 失败返回错误
+//	已停止时, 消息投递到死信, 同步/Ask 消息响应错误. 同步/Ask 消息不受邮箱容量限制
+func (p *Actor[KEY]) TrySend(messages ...any) error {
+	if p.stopped.Load() {
+		p.deadLetter(messages...)
+		return errors.WithMessagef(xerror.Unavailable, "actor stopped. path:%v %v", p.path, xruntime.Location())
+	}
+	if err := p.msgMgr.TrySend(messages...); err != nil {
+		if errors.Is(err, xerror.ChannelClosed) { // 邮箱已停止, 均未入队
+			p.deadLetter(messages...)
+		}
+		return err
+	}
+	return nil
+}
+
+// TrySendMsg 发送消息到 actor (异步)
+//
+//	邮箱满时按邮箱的 FullPolicy 处理, 失败返回错误
+func (p *Actor[KEY]) TrySendMsg(messages ...*Msg) error {
+	messagesAny := make([]any, len(messages))
+	for i, msg := range messages {
+		messagesAny[i] = msg
+	}
+	return p.TrySend(messagesAny...)
+}
+
+// GetMailboxStatistics 获取邮箱统计数据 (队列长度, 最高水位, 入队到处理的延迟)
+func (p *Actor[KEY]) GetMailboxStatistics() *xstatistics.Mailbox {
+	return p.msgMgr.Statistics
 }
 
 // SendMsgSync 发送消息到 actor, 并等待响应 (同步)
@@ -75,8 +192,13 @@ func (p *Actor[KEY]) SendMsg(messages ...*Msg) {
 //		resp: 响应数据
 //		err: 错误
 func (p *Actor[KEY]) SendMsgSync(msg *Msg) (resp any, err error) {
-	msg.withSyncChan(make(chan *behaviorResponse, 1))
-	p.SendMsg(msg)
+	// 入队后 msg 可能被处理协程修改(e.g.: Forward), 只使用本地的响应通道与命令
+	syncChan := make(chan *behaviorResponse, 1)
+	cmd := msg.Cmd
+	msg.withSyncChan(syncChan)
+	if err = p.TrySendMsg(msg); err != nil {
+		return nil, errors.WithMessagef(err, "SendMsgSync cmd:%v %v", cmd, xruntime.Location())
+	}
 
 	var hasDeadline bool
 	var ctxDone <-chan struct{}
@@ -87,10 +209,10 @@ func (p *Actor[KEY]) SendMsgSync(msg *Msg) (resp any, err error) {
 
 	if hasDeadline { // 设置了超时, 使用 context 的超时, 不加 60 秒上限
 		select {
-		case res := <-msg.syncChan:
+		case res := <-syncChan:
 			return res.respData, res.err
 		case <-ctxDone:
-			return nil, errors.WithMessagef(xerror.Timeout, "SendMsgSync context timeout. msg:%v %v", msg, xruntime.Location())
+			return nil, errors.WithMessagef(xerror.Timeout, "SendMsgSync context timeout. cmd:%v %v", cmd, xruntime.Location())
 		}
 	}
 	// 未设置超时, 使用 60 秒默认超时兜底; ctxDone 为 nil 时永不触发 (nil channel)
@@ -101,11 +223,11 @@ func (p *Actor[KEY]) SendMsgSync(msg *Msg) (resp any, err error) {
 		xpool.Timer.Put(timer)
 	}()
 	select {
-	case res := <-msg.syncChan:
+	case res := <-syncChan:
 		return res.respData, res.err
 	case <-ctxDone:
-		return nil, errors.WithMessagef(xerror.Timeout, "SendMsgSync context timeout. msg:%v %v", msg, xruntime.Location())
+		return nil, errors.WithMessagef(xerror.Timeout, "SendMsgSync context timeout. cmd:%v %v", cmd, xruntime.Location())
 	case <-timer.C:
-		return nil, errors.WithMessagef(xerror.Timeout, "SendMsgSync default timeout after 60 seconds. event:%v %v", msg, xruntime.Location())
+		return nil, errors.WithMessagef(xerror.Timeout, "SendMsgSync default timeout after 60 seconds. cmd:%v %v", cmd, xruntime.Location())
 	}
 }
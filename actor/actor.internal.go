@@ -9,6 +9,7 @@ import (
 )
 
 func (p *Actor[KEY]) stop(msg *Msg) (resp any, err error) {
+	p.stopped.Store(true)
 	p.childMgr.Foreach(func(key KEY, child *Actor[KEY]) bool { // 停止所有子 Actor
 		if msg.IsSync() { // 同步
 			_, _ = child.SendMsgSync(
@@ -22,11 +23,88 @@ func (p *Actor[KEY]) stop(msg *Msg) (resp any, err error) {
 		return true
 	})
 	p.childMgr.Clear()
+	clear(p.restartHistory)
+	p.cancelAllTimers()
+	p.stash = nil
+	if p.lifecycle != nil {
+		p.lifecycle.PostStop()
+	}
+	p.system.unregister(p)
 	// 停止自己的消息管理器
 	p.msgMgr.Stop()
 	return nil, nil
 }
 
+// deadLetter 投递死信
+//
+//	同步/Ask 消息立即响应错误, 避免调用方等待至超时
+func (p *Actor[KEY]) deadLetter(messages ...any) {
+	p.deadLetterWithError(errors.WithMessagef(xerror.Unavailable, "actor stopped. path:%v %v", p.path, xruntime.Location()), messages...)
+}
+
+// deadLetterWithError 投递死信, 同步/Ask 消息响应 err
+func (p *Actor[KEY]) deadLetterWithError(err error, messages ...any) {
+	for _, message := range messages {
+		if msg, ok := message.(*Msg); ok {
+			msg.Reply(nil, err)
+			if isSystemReservedCommand(msg.Cmd) { // 系统消息, 不计入死信
+				continue
+			}
+		}
+		p.system.DeadLetter(p.path, message)
+	}
+}
+
+// restart 重启
+//
+//	停止所有子 actor, 取消所有定时器, 调用旧实例的 PreRestart, 重新调用 Factory 创建实例, 调用新实例的 PreStart,
+//	之后将挂起期间暂存的消息插入邮箱队首, 按邮箱顺序处理. 处理中再次失败时, 剩余消息重新暂存, 等待下一次重启
+func (p *Actor[KEY]) restart(msg *Msg) (resp any, err error) {
+	var reason error
+	if 0 < len(msg.Args) {
+		reason, _ = msg.Args[0].(error)
+	}
+	p.childMgr.Foreach(func(key KEY, child *Actor[KEY]) bool {
+		child.SendMsg(NewMsg(msg.Ctx, SystemReservedCommand_Stop))
+		return true
+	})
+	p.childMgr.Clear()
+	clear(p.restartHistory)
+	p.cancelAllTimers()
+	if p.lifecycle != nil {
+		p.lifecycle.PreRestart(reason)
+	}
+	p.behavior, p.lifecycle = p.options.factory()
+	if p.lifecycle != nil {
+		if err = p.lifecycle.PreStart(); err != nil {
+			err = errors.WithMessagef(err, "actor PreStart key:%v %v", p.key, xruntime.Location())
+			p.fail(err)
+			return nil, err
+		}
+	}
+	p.suspended = false
+	stash := p.stash
+	p.stash = nil
+	if err = p.msgMgr.SendFront(stash...); err != nil { // 邮箱已停止
+		p.deadLetter(stash...)
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (p *Actor[KEY]) failure(msg *Msg) (resp any, err error) {
+	if len(msg.Args) < 2 {
+		return nil, errors.WithMessage(xerror.ParamCountNotMatch, xruntime.Location())
+	}
+	childKey, ok := msg.Args[0].(KEY)
+	if !ok {
+		return nil, fmt.Errorf("invalid child key type %v", xruntime.Location())
+	}
+	reason, _ := msg.Args[1].(error)
+	p.handleFailure(childKey, reason)
+	return nil, nil
+}
+
 func (p *Actor[KEY]) removeChild(msg *Msg) (resp any, err error) {
 	if len(msg.Args) < 1 {
 		return nil, errors.WithMessage(xerror.ParamCountNotMatch, xruntime.Location())
@@ -49,6 +127,7 @@ func (p *Actor[KEY]) removeChild(msg *Msg) (resp any, err error) {
 		)
 	}
 	p.childMgr.Del(childKey)
+	delete(p.restartHistory, childKey)
 	return nil, nil
 }
 
@@ -60,16 +139,31 @@ func (p *Actor[KEY]) spawn(msg *Msg) (resp any, err error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid child key type %v", xruntime.Location())
 	}
-	behavior, ok := msg.Args[1].(Behavior)
-	if !ok {
+	opt := NewOptions()
+	switch v := msg.Args[1].(type) {
+	case Behavior:
+		opt.WithFactory(BehaviorFactory(v))
+	case Factory:
+		opt.WithFactory(v)
+	default:
 		return nil, fmt.Errorf("invalid child behavior type %v", xruntime.Location())
 	}
+	for _, arg := range msg.Args[2:] {
+		childOpt, ok := arg.(*Options)
+		if !ok {
+			return nil, fmt.Errorf("invalid child options type %v", xruntime.Location())
+		}
+		opt = mergeOptions(opt, childOpt)
+	}
+	if err = configureOptions(opt); err != nil {
+		return nil, errors.WithMessagef(err, "child %v %v", key, xruntime.Location())
+	}
 	// 检查是否已存在
 	if p.childMgr.Get(key) != nil {
 		return nil, fmt.Errorf("child %v is already exist %v", key, xruntime.Location())
 	}
 	// 创建并启动子 Actor
-	child := NewActor(key, p, behavior)
+	child := NewActorWithOptions(key, p, opt)
 	p.childMgr.Add(key, child)
 	child.Start()
 	return any(child), nil
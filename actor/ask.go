@@ -0,0 +1,122 @@
+package actor
+
+import (
+	"sync/atomic"
+	"time"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+const askTimeoutDefault = 60 * time.Second // Ask 默认超时时长
+
+var askCorrelationID atomic.Uint64 // Ask 关联ID, 全局自增
+
+// AskResponse Ask 的响应, 作为消息投递到请求方 actor 的邮箱中
+//
+//	在请求方的行为函数中, 通过 CorrelationID 与 Ask 的返回值对应
+type AskResponse struct {
+	CorrelationID uint64 // 关联ID
+	Resp          any    // 响应数据
+	Err           error  // 错误. 超时时为 xerror.Timeout
+}
+
+// Future Ask 的结果
+type Future struct {
+	CorrelationID uint64 // 关联ID
+	doneChan      chan struct{}
+	resp          any
+	err           error
+}
+
+// Done 完成时关闭
+func (p *Future) Done() <-chan struct{} {
+	return p.doneChan
+}
+
+// Result 阻塞等待结果
+//
+//	[❗] 禁止在 actor 的处理协程中调用, 会阻塞该 actor. actor 中请使用 Actor.Ask
+func (p *Future) Result() (resp any, err error) {
+	<-p.doneChan
+	return p.resp, p.err
+}
+
+// 一次 Ask 请求, 响应与超时只生效先到的一个
+type askPending struct {
+	correlationID uint64
+	completed     atomic.Bool
+	timer         *time.Timer
+	onComplete    func(response *AskResponse)
+}
+
+func (p *askPending) complete(resp any, err error) {
+	if !p.completed.CompareAndSwap(false, true) {
+		return
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.onComplete(&AskResponse{
+		CorrelationID: p.correlationID,
+		Resp:          resp,
+		Err:           err,
+	})
+}
+
+// ask 发送消息, 响应或超时时调用 onComplete
+func ask(target IActorMsg, msg *Msg, timeout time.Duration, onComplete func(response *AskResponse)) uint64 {
+	pending := &askPending{
+		correlationID: askCorrelationID.Add(1),
+		onComplete:    onComplete,
+	}
+	if timeout <= 0 {
+		timeout = askTimeoutDefault
+		if msg.Ctx != nil {
+			if deadline, ok := msg.Ctx.Deadline(); ok {
+				timeout = time.Until(deadline)
+			}
+		}
+	}
+	pending.timer = time.AfterFunc(timeout, func() {
+		pending.complete(nil, errors.WithMessagef(xerror.Timeout, "Ask timeout. correlationID:%v msg:%v %v",
+			pending.correlationID, msg, xruntime.Location()))
+	})
+	msg.WithReplyFunc(pending.complete)
+	if err := target.TrySendMsg(msg); err != nil {
+		pending.complete(nil, errors.WithMessagef(err, "Ask correlationID:%v msg:%v %v",
+			pending.correlationID, msg, xruntime.Location()))
+	}
+	return pending.correlationID
+}
+
+// Ask 发送消息到 target, 不阻塞 (异步请求/响应)
+//
+//	响应或超时以 *AskResponse 的形式投递到当前 actor 的邮箱中, 由当前 actor 的行为函数处理
+//	参数:
+//		target: 目标 actor
+//		msg: 消息
+//		timeout: 超时时长. <=0 时, 使用 msg.Ctx 的超时, 未设置则为 60 秒
+//	返回:
+//		关联ID, 与 AskResponse.CorrelationID 对应
+func (p *Actor[KEY]) Ask(target IActorMsg, msg *Msg, timeout time.Duration) (correlationID uint64) {
+	return ask(target, msg, timeout, func(response *AskResponse) {
+		p.Send(response)
+	})
+}
+
+// AskFuture 发送消息到 target, 不阻塞, 返回 Future
+//
+//	用于非 actor 的调用方. 参数同 Actor.Ask
+func AskFuture(target IActorMsg, msg *Msg, timeout time.Duration) *Future {
+	future := &Future{
+		doneChan: make(chan struct{}),
+	}
+	future.CorrelationID = ask(target, msg, timeout, func(response *AskResponse) {
+		future.resp = response.Resp
+		future.err = response.Err
+		close(future.doneChan)
+	})
+	return future
+}
@@ -3,6 +3,7 @@ package actor
 // Behavior 定义 actor 的行为函数类型
 //
 //	每个行为函数接收一个消息,处理它,然后返回下一个行为
+//	返回的 err 响应给调用方(同步/Ask), 不视为失败. panic 时视为失败, 交由父 actor 监督处理
 type Behavior func(messages ...any) (behavior Behavior, resp any, err error)
 
 // 基础行为实现
@@ -14,8 +14,11 @@ const (
 	SystemReservedCommand_Begin       CMD = 4294960000     // 系统保留命令-起始值
 	SystemReservedCommand_Stop        CMD = 4294960001     // 停止 {args:无}
 	SystemReservedCommand_RemoveChild CMD = 4294960002     // 移除子 actor {args: [0]:子 actor key}
-	SystemReservedCommand_Spawn       CMD = 4294960003     // 创建子 actor {args: [0]:子 actor key, [1]:子 actor 行为函数} {返回: response *Actor[KEY]}
+	SystemReservedCommand_Spawn       CMD = 4294960003     // 创建子 actor {args: [0]:子 actor key, [1]:子 actor 行为函数 Behavior 或 工厂 Factory, [2:]:[可选] *Options, 后面的覆盖前面的} {返回: response *Actor[KEY]}
 	SystemReservedCommand_GetChild    CMD = 4294960004     // 获取子 actor {args: [0]:子 actor key} {返回: response *Actor[KEY]}
+	SystemReservedCommand_Failure     CMD = 4294960005     // 子 actor 失败 [子->父] {args: [0]:子 actor key, [1]:失败原因 error}
+	SystemReservedCommand_Restart     CMD = 4294960006     // 重启 [父->子] {args: [0]:失败原因 error}
+	SystemReservedCommand_Timer       CMD = 4294960007     // 定时器到期 [内部] {args: [0]:*xcontrol.Event}
 	SystemReservedCommand_End         CMD = math.MaxUint32 // 系统保留命令-结束值
 )
 
@@ -0,0 +1,478 @@
+package grain
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	xactor "github.com/75912001/xlib/actor"
+	xactorremote "github.com/75912001/xlib/actor/remote"
+	xconfig "github.com/75912001/xlib/config"
+	xerror "github.com/75912001/xlib/error"
+	xetcd "github.com/75912001/xlib/etcd"
+	xlog "github.com/75912001/xlib/log"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// host 消息
+type (
+	delivery struct { // 投递到虚拟 actor
+		key string
+		msg *xactor.Msg
+	}
+	claimed struct { // 目录登记完成
+		key   string
+		owner xactorremote.Node
+		err   error
+	}
+	released struct { // 目录取消登记完成
+		key string
+	}
+	start    struct{} // 启动
+	check    struct{} // 空闲/归属 检查
+	shutdown struct{} // 关闭, 钝化所有虚拟 actor
+)
+
+type askKind uint8
+
+const (
+	askKindSpawn     askKind = 1 // 创建
+	askKindPassivate askKind = 2 // 钝化
+)
+
+type askContext struct {
+	kind askKind
+	key  string
+}
+
+// Cluster 某一类虚拟 actor 的集群
+//
+//	消息按 key 投递, 不存在时按需激活. 放置策略决定 key 由哪个服务承载, 激活目录保证集群内单一激活
+//	空闲超时 或 归属变更(成员变化) 时钝化: 先调用 IGrain.OnPassivate 保存状态, 再停止并取消登记
+//	虚拟 actor 是 host actor 的子 actor. 路径: /grain.${kind}/${key}
+//	跨服务投递时, 消息参数与响应须为 proto.Message
+type Cluster struct {
+	kind    string
+	factory Factory
+	options *Options
+	node    xactorremote.Node // 本服务
+	prefix  string            // 虚拟 actor 路径前缀
+	host    *xactor.Actor[string]
+
+	// 以下只在 host 的处理协程中访问
+	activationMap map[string]*activation // key: 虚拟 actor key
+	askMap        map[uint64]*askContext // key: 关联ID
+	stopping      bool                   // 已关闭, 不再激活
+}
+
+// NewCluster 创建虚拟 actor 集群
+//
+//	kind: 类型, 集群内唯一. e.g.: player
+func NewCluster(kind string, factory Factory, opts ...*Options) (*Cluster, error) {
+	if kind == "" || factory == nil {
+		return nil, errors.WithMessagef(xerror.Param, "kind:%v factory:%v %v", kind, factory != nil, xruntime.Location())
+	}
+	opt := mergeOptions(opts...)
+	if opt.remote == nil {
+		opt.remote = xactorremote.GRemote
+	}
+	var node xactorremote.Node
+	if opt.remote != nil {
+		node = opt.remote.GetNode()
+		if opt.placement == nil {
+			opt.placement = NewPlacement(node.GroupID, node.Name)
+		}
+	}
+	if opt.directory == nil {
+		if opt.remote != nil && xetcd.GEtcd != nil && xconfig.GConfigMgr.Base.ProjectName != nil {
+			opt.directory = NewEtcdDirectory(xetcd.GEtcd, "/"+*xconfig.GConfigMgr.Base.ProjectName+".grain/")
+		} else {
+			opt.directory = NewLocalDirectory()
+		}
+	}
+	if opt.idleTimeout == nil || *opt.idleTimeout <= 0 {
+		opt.WithIdleTimeout(idleTimeoutDefault)
+	}
+	if opt.checkInterval == nil || *opt.checkInterval <= 0 {
+		opt.WithCheckInterval(checkIntervalDefault)
+	}
+	p := &Cluster{
+		kind:          kind,
+		factory:       factory,
+		options:       opt,
+		node:          node,
+		activationMap: make(map[string]*activation),
+		askMap:        make(map[uint64]*askContext),
+	}
+	p.host = xactor.NewActorWithOptions(kind, nil, opt.hostOptions,
+		xactor.NewOptions().
+			WithName("grain."+kind).
+			WithFactory(xactor.BehaviorFactory(p.receive)),
+	)
+	p.prefix = p.host.GetPath() + xactor.PathSeparator
+	return p, nil
+}
+
+// Start 启动
+func (p *Cluster) Start() {
+	p.host.Start()
+	p.host.SendMsg(xactor.NewMsg(context.Background(), 0, &start{}))
+	if p.options.remote != nil {
+		p.options.remote.HandlePrefix(p.prefix, func(path string, msg *xactor.Msg) error {
+			return p.host.TrySend(&delivery{key: strings.TrimPrefix(path, p.prefix), msg: msg})
+		})
+	}
+}
+
+// Stop 停止
+//
+//	钝化所有虚拟 actor, 之后停止 host
+func (p *Cluster) Stop() {
+	if p.options.remote != nil {
+		p.options.remote.UnhandlePrefix(p.prefix)
+	}
+	if _, err := xactor.AskFuture(p.host, xactor.NewMsg(context.Background(), 0, &shutdown{}), 0).Result(); err != nil {
+		xlog.PrintfErr("grain cluster shutdown kind:%v err:%v", p.kind, err)
+	}
+	if _, err := p.host.SendMsgSync(xactor.NewMsg(context.Background(), xactor.SystemReservedCommand_Stop)); err != nil {
+		xlog.PrintfErr("grain cluster stop kind:%v err:%v", p.kind, err)
+	}
+}
+
+// GetKind 获取类型
+func (p *Cluster) GetKind() string {
+	return p.kind
+}
+
+// GrainPath 虚拟 actor 的路径
+func (p *Cluster) GrainPath(key string) string {
+	return p.prefix + key
+}
+
+// Ref 获取虚拟 actor 的引用
+//
+//	引用不绑定服务, 每次投递时按放置策略决定目标
+func (p *Cluster) Ref(key string) xactor.IActorRef {
+	return &Ref{
+		cluster: p,
+		key:     key,
+	}
+}
+
+// 投递
+//
+//	失败时返回错误, 同步/Ask 消息已响应错误
+func (p *Cluster) deliver(key string, msg *xactor.Msg) error {
+	if p.options.remote != nil {
+		if owner, ok := p.options.placement.Owner(key); ok && owner != p.node {
+			return p.options.remote.ActorOf(owner, p.GrainPath(key)).TrySendMsg(msg)
+		}
+	}
+	return p.deliverLocal(key, msg)
+}
+
+// 投递到本服务. 已激活时直接投递, 否则交由 host 激活
+func (p *Cluster) deliverLocal(key string, msg *xactor.Msg) error {
+	if ref, ok := p.host.GetSystem().Lookup(p.GrainPath(key)); ok {
+		return ref.TrySendMsg(msg)
+	}
+	if err := p.host.TrySend(&delivery{key: key, msg: msg}); err != nil {
+		msg.Reply(nil, err)
+		return err
+	}
+	return nil
+}
+
+// host 的行为函数
+func (p *Cluster) receive(messages ...any) (xactor.Behavior, any, error) {
+	switch message := messages[0].(type) {
+	case *delivery:
+		p.onDelivery(message)
+	case *claimed:
+		p.onClaimed(message)
+	case *released:
+		p.onReleased(message)
+	case *xactor.AskResponse:
+		p.onAskResponse(message)
+	case *xactor.Terminated:
+		p.onTerminated(message)
+	case *xactor.Msg:
+		if len(message.Args) != 1 {
+			break
+		}
+		switch message.Args[0].(type) {
+		case *start:
+			interval := *p.options.checkInterval
+			if err := p.host.ScheduleRepeat("grain.check", interval, interval, xactor.NewMsg(context.Background(), 0, &check{})); err != nil {
+				xlog.PrintfErr("grain cluster kind:%v schedule check err:%v", p.kind, err)
+			}
+		case *check:
+			p.onCheck()
+		case *shutdown:
+			p.onShutdown(message.Forward())
+		}
+	default:
+		xlog.PrintfErr("grain cluster kind:%v unknown message:%v", p.kind, message)
+	}
+	return p.receive, nil, nil
+}
+
+func (p *Cluster) onDelivery(d *delivery) {
+	act, ok := p.activationMap[d.key]
+	if !ok && p.stopping {
+		if d.msg.NeedReply() {
+			d.msg.Reply(nil, errors.WithMessagef(xerror.Unavailable, "grain cluster shutdown. kind:%v %v", p.kind, xruntime.Location()))
+		} else {
+			p.host.GetSystem().DeadLetter(p.GrainPath(d.key), d.msg)
+		}
+		return
+	}
+	if !ok {
+		act = &activation{
+			key:   d.key,
+			state: activationStateClaiming,
+		}
+		act.buffer = append(act.buffer, d.msg)
+		p.activationMap[d.key] = act
+		go func() { // 登记可能阻塞(etcd), 不在 host 的处理协程中执行
+			ctx, cancel := context.WithTimeout(context.Background(), directoryTimeoutDefault)
+			defer cancel()
+			owner, err := p.options.directory.Claim(ctx, p.kind, d.key, p.node)
+			p.host.Send(&claimed{key: d.key, owner: owner, err: err})
+		}()
+		return
+	}
+	if act.state == activationStateActive {
+		if act.passivated.Load() { // 钝化超时后, 钝化又完成了
+			p.stopActivation(act)
+			act.buffer = append(act.buffer, d.msg)
+			return
+		}
+		_ = act.actor.TrySendMsg(d.msg) // 失败时已响应
+		return
+	}
+	act.buffer = append(act.buffer, d.msg)
+}
+
+func (p *Cluster) onClaimed(c *claimed) {
+	act, ok := p.activationMap[c.key]
+	if !ok || act.state != activationStateClaiming {
+		return
+	}
+	if c.err != nil {
+		delete(p.activationMap, c.key)
+		p.replyBuffer(act, errors.WithMessagef(c.err, "grain claim kind:%v key:%v %v", p.kind, c.key, xruntime.Location()))
+		return
+	}
+	if c.owner != p.node { // 已在其它服务激活, 转发
+		delete(p.activationMap, c.key)
+		if p.options.remote == nil {
+			p.replyBuffer(act, errors.WithMessagef(xerror.Unavailable, "grain kind:%v key:%v owner:%v %v", p.kind, c.key, c.owner, xruntime.Location()))
+			return
+		}
+		ref := p.options.remote.ActorOf(c.owner, p.GrainPath(c.key))
+		for _, msg := range act.buffer {
+			_ = ref.TrySendMsg(msg)
+		}
+		return
+	}
+	act.state = activationStateSpawning
+	var factory xactor.Factory = func() (xactor.Behavior, xactor.ILifecycle) {
+		b, g := p.factory(act.key)
+		w := &behavior{
+			cluster:    p,
+			activation: act,
+			behavior:   b,
+			grain:      g,
+		}
+		return w.receive, &lifecycle{grain: g}
+	}
+	args := []any{c.key, factory}
+	if p.options.actorOptions != nil { // factory, name 不可被覆盖
+		args = append(args, p.options.actorOptions, xactor.NewOptions().WithFactory(factory).WithName(c.key))
+	}
+	correlationID := p.host.Ask(p.host, xactor.NewMsg(context.Background(), xactor.SystemReservedCommand_Spawn, args...), 0)
+	p.askMap[correlationID] = &askContext{kind: askKindSpawn, key: c.key}
+}
+
+func (p *Cluster) onAskResponse(response *xactor.AskResponse) {
+	ask, ok := p.askMap[response.CorrelationID]
+	if !ok {
+		return
+	}
+	delete(p.askMap, response.CorrelationID)
+	act, ok := p.activationMap[ask.key]
+	if !ok {
+		return
+	}
+	switch ask.kind {
+	case askKindSpawn:
+		if act.state != activationStateSpawning {
+			return
+		}
+		child, _ := response.Resp.(*xactor.Actor[string])
+		if response.Err != nil || child == nil {
+			err := errors.WithMessagef(xerror.Fail, "grain spawn kind:%v key:%v err:%v %v", p.kind, ask.key, response.Err, xruntime.Location())
+			xlog.PrintErr(err)
+			act.state = activationStatePassivating
+			p.replyBuffer(act, err)
+			p.release(act)
+			return
+		}
+		act.actor = child
+		act.state = activationStateActive
+		act.touch()
+		p.host.Watch(child.GetPath())
+		p.flushBuffer(act)
+	case askKindPassivate:
+		if act.state != activationStatePassivating {
+			return
+		}
+		if response.Err != nil { // 保存失败, 保持激活
+			xlog.PrintfErr("grain passivate kind:%v key:%v err:%v", p.kind, ask.key, response.Err)
+			act.state = activationStateActive
+			p.flushBuffer(act)
+			return
+		}
+		p.stopActivation(act)
+	}
+}
+
+// 停止已钝化的虚拟 actor, 停止后(Terminated)取消登记
+func (p *Cluster) stopActivation(act *activation) {
+	act.state = activationStatePassivating
+	p.host.SendMsg(xactor.NewMsg(context.Background(), xactor.SystemReservedCommand_RemoveChild, act.key))
+}
+
+func (p *Cluster) onTerminated(t *xactor.Terminated) {
+	key := strings.TrimPrefix(t.Path, p.prefix)
+	act, ok := p.activationMap[key]
+	if !ok || act.actor == nil || act.actor.GetPath() != t.Path {
+		return
+	}
+	act.state = activationStatePassivating
+	p.release(act)
+}
+
+// 取消登记, 完成后(released)重新投递暂存的消息
+func (p *Cluster) release(act *activation) {
+	key := act.key
+	go func() {
+		p.releaseDirectory(key)
+		p.host.Send(&released{key: key})
+	}()
+}
+
+// 取消登记 [不在 host 的处理协程中调用]
+func (p *Cluster) releaseDirectory(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), directoryTimeoutDefault)
+	defer cancel()
+	if err := p.options.directory.Release(ctx, p.kind, key, p.node); err != nil {
+		xlog.PrintfErr("grain release kind:%v key:%v err:%v", p.kind, key, err)
+	}
+}
+
+func (p *Cluster) onReleased(r *released) {
+	act, ok := p.activationMap[r.key]
+	if !ok || act.state != activationStatePassivating {
+		return
+	}
+	delete(p.activationMap, r.key)
+	for _, msg := range act.buffer {
+		_ = p.deliver(r.key, msg) // 失败时已响应
+	}
+}
+
+// 空闲超时 或 不再归属本服务 时钝化
+func (p *Cluster) onCheck() {
+	now := time.Now().UnixNano()
+	idleTimeout := int64(*p.options.idleTimeout)
+	for key, act := range p.activationMap {
+		if act.state != activationStateActive {
+			continue
+		}
+		if idleTimeout <= now-act.lastActive.Load() {
+			p.passivate(act)
+			continue
+		}
+		if p.options.remote != nil {
+			if owner, ok := p.options.placement.Owner(key); ok && owner != p.node {
+				p.passivate(act)
+			}
+		}
+	}
+}
+
+func (p *Cluster) passivate(act *activation) {
+	act.state = activationStatePassivating
+	correlationID := p.host.Ask(act.actor, xactor.NewMsg(context.Background(), 0, &passivate{}), passivateTimeoutDefault)
+	p.askMap[correlationID] = &askContext{kind: askKindPassivate, key: act.key}
+}
+
+// 钝化所有虚拟 actor, 并取消登记
+//
+//	钝化与取消登记可能阻塞, 在各自的协程中并行执行, 全部完成后响应 msg. 不阻塞 host 的处理协程
+func (p *Cluster) onShutdown(msg *xactor.Msg) {
+	p.stopping = true
+	err := errors.WithMessagef(xerror.Unavailable, "grain cluster shutdown. kind:%v %v", p.kind, xruntime.Location())
+	var waitGroup sync.WaitGroup
+	for key, act := range p.activationMap {
+		var actor *xactor.Actor[string]
+		if act.actor != nil {
+			p.host.Unwatch(act.actor.GetPath())
+			if !act.passivated.Load() {
+				actor = act.actor
+			}
+		}
+		release := act.state != activationStateClaiming
+		p.replyBuffer(act, err)
+		if actor == nil && !release {
+			continue
+		}
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			if actor != nil {
+				ctx, cancel := context.WithTimeout(context.Background(), passivateTimeoutDefault)
+				if _, errPassivate := actor.SendMsgSync(xactor.NewMsg(ctx, 0, &passivate{})); errPassivate != nil {
+					xlog.PrintfErr("grain passivate kind:%v key:%v err:%v", p.kind, key, errPassivate)
+				}
+				cancel()
+			}
+			if release {
+				p.releaseDirectory(key)
+			}
+		}()
+	}
+	clear(p.activationMap)
+	clear(p.askMap)
+	p.host.Cancel("grain.check")
+	go func() {
+		waitGroup.Wait()
+		msg.Reply(nil, nil)
+	}()
+}
+
+// 投递暂存的消息
+func (p *Cluster) flushBuffer(act *activation) {
+	buffer := act.buffer
+	act.buffer = nil
+	for _, msg := range buffer {
+		_ = act.actor.TrySendMsg(msg) // 失败时已响应
+	}
+}
+
+// 暂存的消息响应错误
+func (p *Cluster) replyBuffer(act *activation, err error) {
+	buffer := act.buffer
+	act.buffer = nil
+	for _, msg := range buffer {
+		if msg.NeedReply() {
+			msg.Reply(nil, err)
+		} else {
+			p.host.GetSystem().DeadLetter(p.GrainPath(act.key), msg)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package grain
+
+import (
+	"context"
+	"sync"
+
+	xactorremote "github.com/75912001/xlib/actor/remote"
+	xerror "github.com/75912001/xlib/error"
+	xetcd "github.com/75912001/xlib/etcd"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// IDirectory 激活目录, 记录虚拟 actor 所在的服务. 保证集群内单一激活
+//
+//	可能阻塞(e.g.: etcd), 不在 host 的处理协程中调用. ctx 带超时 directoryTimeoutDefault
+type IDirectory interface {
+	// Claim 登记 node 为 key 的所在服务. 已被其它服务登记时, 返回该服务
+	Claim(ctx context.Context, kind string, key string, node xactorremote.Node) (owner xactorremote.Node, err error)
+	// Release 取消登记, 只取消 node 自己的登记
+	Release(ctx context.Context, kind string, key string, node xactorremote.Node) error
+}
+
+// LocalDirectory 进程内的激活目录
+//
+//	只保证进程内单一激活, 用于单服务部署
+type LocalDirectory struct {
+	mu      sync.Mutex
+	dataMap map[string]xactorremote.Node
+}
+
+func NewLocalDirectory() *LocalDirectory {
+	return &LocalDirectory{
+		dataMap: make(map[string]xactorremote.Node),
+	}
+}
+
+func (p *LocalDirectory) Claim(_ context.Context, kind string, key string, node xactorremote.Node) (xactorremote.Node, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := kind + "/" + key
+	if owner, ok := p.dataMap[k]; ok {
+		return owner, nil
+	}
+	p.dataMap[k] = node
+	return node, nil
+}
+
+func (p *LocalDirectory) Release(_ context.Context, kind string, key string, node xactorremote.Node) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := kind + "/" + key
+	if owner, ok := p.dataMap[k]; ok && owner == node {
+		delete(p.dataMap, k)
+	}
+	return nil
+}
+
+// EtcdDirectory etcd 激活目录
+//
+//	登记带本服务的租约, 服务宕机后随租约过期清除
+//	key: ${prefix}${kind}/${key} value: Node.String()
+type EtcdDirectory struct {
+	etcd   xetcd.IEtcd
+	prefix string
+}
+
+// NewEtcdDirectory 创建 etcd 激活目录
+//
+//	prefix: 键前缀. 不可在 etcd 监视的前缀下, 否则会被当做服务信息. e.g.: /${projectName}.grain/
+func NewEtcdDirectory(etcd xetcd.IEtcd, prefix string) *EtcdDirectory {
+	return &EtcdDirectory{
+		etcd:   etcd,
+		prefix: prefix,
+	}
+}
+
+func (p *EtcdDirectory) Claim(ctx context.Context, kind string, key string, node xactorremote.Node) (xactorremote.Node, error) {
+	const retryMax = 3 // 登记时已有的记录恰好被删除, 重试
+	k := p.prefix + kind + "/" + key
+	for i := 0; i < retryMax; i++ {
+		ok, current, err := p.etcd.PutIfAbsentWithLease(ctx, k, node.String())
+		if err != nil {
+			return xactorremote.Node{}, errors.WithMessage(err, xruntime.Location())
+		}
+		if ok {
+			return node, nil
+		}
+		if current == "" {
+			continue
+		}
+		owner, err := xactorremote.ParseNode(current)
+		if err != nil {
+			return xactorremote.Node{}, errors.WithMessagef(err, "key:%v %v", k, xruntime.Location())
+		}
+		return owner, nil
+	}
+	return xactorremote.Node{}, errors.WithMessagef(xerror.Retry, "key:%v %v", k, xruntime.Location())
+}
+
+func (p *EtcdDirectory) Release(ctx context.Context, kind string, key string, node xactorremote.Node) error {
+	k := p.prefix + kind + "/" + key
+	if _, err := p.etcd.DelIfValue(ctx, k, node.String()); err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package grain
+
+import (
+	"sync/atomic"
+	"time"
+
+	xactor "github.com/75912001/xlib/actor"
+)
+
+// IGrain 虚拟 actor 的持久化钩子
+//
+//	在虚拟 actor 的处理协程中调用
+type IGrain interface {
+	OnActivate() error  // 激活, 加载状态. 失败时交由监督策略重启
+	OnPassivate() error // 钝化前, 保存状态. 失败时取消本次钝化, 保持激活, 下次检查时重试
+}
+
+// Factory 虚拟 actor 的工厂
+//
+//	每次激活(包括重启)时调用, 返回该 key 的行为函数与持久化钩子 [可以为 nil]
+type Factory func(key string) (xactor.Behavior, IGrain)
+
+// 钝化请求 [host->虚拟 actor]
+type passivate struct{}
+
+func isPassivate(msg *xactor.Msg) bool {
+	if len(msg.Args) != 1 {
+		return false
+	}
+	_, ok := msg.Args[0].(*passivate)
+	return ok
+}
+
+type activationState uint8
+
+const (
+	activationStateClaiming    activationState = 1 // 目录登记中
+	activationStateSpawning    activationState = 2 // 创建中
+	activationStateActive      activationState = 3 // 已激活
+	activationStatePassivating activationState = 4 // 钝化中
+)
+
+// 一次激活
+//
+//	state, actor, buffer 只在 host 的处理协程中访问
+type activation struct {
+	key        string
+	state      activationState
+	actor      *xactor.Actor[string]
+	buffer     []*xactor.Msg // 非激活状态时, 暂存的消息
+	lastActive atomic.Int64  // 最后处理消息的时间 [UnixNano] 由虚拟 actor 更新
+	passivated atomic.Bool   // OnPassivate 成功. 之后的消息转交 host
+}
+
+func (p *activation) touch() {
+	p.lastActive.Store(time.Now().UnixNano())
+}
+
+// 虚拟 actor 的行为函数 [包装]
+type behavior struct {
+	cluster    *Cluster
+	activation *activation
+	behavior   xactor.Behavior
+	grain      IGrain
+}
+
+func (p *behavior) receive(messages ...any) (xactor.Behavior, any, error) {
+	if msg, ok := messages[0].(*xactor.Msg); ok {
+		if isPassivate(msg) {
+			if p.grain != nil {
+				if err := p.grain.OnPassivate(); err != nil {
+					return p.receive, nil, err
+				}
+			}
+			p.activation.passivated.Store(true)
+			return p.receive, nil, nil
+		}
+		if p.activation.passivated.Load() { // 已钝化, 转交 host 重新投递
+			p.cluster.host.Send(&delivery{key: p.activation.key, msg: msg.Forward()})
+			return p.receive, nil, nil
+		}
+	}
+	p.activation.touch()
+	var resp any
+	var err error
+	p.behavior, resp, err = p.behavior(messages...)
+	return p.receive, resp, err
+}
+
+// 生命周期
+type lifecycle struct {
+	grain IGrain
+}
+
+func (p *lifecycle) PreStart() error {
+	if p.grain == nil {
+		return nil
+	}
+	return p.grain.OnActivate()
+}
+
+func (p *lifecycle) PostStop() {
+}
+
+func (p *lifecycle) PreRestart(reason error) {
+}
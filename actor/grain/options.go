@@ -0,0 +1,96 @@
+package grain
+
+import (
+	"time"
+
+	xactor "github.com/75912001/xlib/actor"
+	xactorremote "github.com/75912001/xlib/actor/remote"
+)
+
+const (
+	idleTimeoutDefault      = 10 * time.Minute // 空闲超时
+	checkIntervalDefault    = 10 * time.Second // 空闲/归属 检查间隔
+	passivateTimeoutDefault = 30 * time.Second // 钝化超时
+	directoryTimeoutDefault = 5 * time.Second  // 激活目录 登记/取消登记 超时
+)
+
+type Options struct {
+	remote        *xactorremote.Remote // [optional] 远程 actor 管理器 [default]:xactorremote.GRemote, nil 时只在本服务激活
+	placement     *Placement           // [optional] 放置策略 [default]:与本服务 groupID, name 相同的服务
+	directory     IDirectory           // [optional] 激活目录 [default]:有 remote 且 etcd.GEtcd 非 nil 时 EtcdDirectory, 否则 LocalDirectory
+	idleTimeout   *time.Duration       // [optional] 空闲超时, 超时后钝化 [default]:idleTimeoutDefault
+	checkInterval *time.Duration       // [optional] 空闲/归属 检查间隔 [default]:checkIntervalDefault
+	actorOptions  *xactor.Options      // [optional] 虚拟 actor 的选项(邮箱等), factory, name 不生效
+	hostOptions   *xactor.Options      // [optional] host actor 的选项(监督策略等), factory, name 不生效
+}
+
+// NewOptions 创建 Options
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func (p *Options) WithRemote(remote *xactorremote.Remote) *Options {
+	p.remote = remote
+	return p
+}
+
+func (p *Options) WithPlacement(placement *Placement) *Options {
+	p.placement = placement
+	return p
+}
+
+func (p *Options) WithDirectory(directory IDirectory) *Options {
+	p.directory = directory
+	return p
+}
+
+func (p *Options) WithIdleTimeout(timeout time.Duration) *Options {
+	p.idleTimeout = &timeout
+	return p
+}
+
+func (p *Options) WithCheckInterval(interval time.Duration) *Options {
+	p.checkInterval = &interval
+	return p
+}
+
+func (p *Options) WithActorOptions(opts *xactor.Options) *Options {
+	p.actorOptions = opts
+	return p
+}
+
+func (p *Options) WithHostOptions(opts *xactor.Options) *Options {
+	p.hostOptions = opts
+	return p
+}
+
+func mergeOptions(opts ...*Options) *Options {
+	so := NewOptions()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.remote != nil {
+			so.WithRemote(opt.remote)
+		}
+		if opt.placement != nil {
+			so.WithPlacement(opt.placement)
+		}
+		if opt.directory != nil {
+			so.WithDirectory(opt.directory)
+		}
+		if opt.idleTimeout != nil {
+			so.WithIdleTimeout(*opt.idleTimeout)
+		}
+		if opt.checkInterval != nil {
+			so.WithCheckInterval(*opt.checkInterval)
+		}
+		if opt.actorOptions != nil {
+			so.WithActorOptions(opt.actorOptions)
+		}
+		if opt.hostOptions != nil {
+			so.WithHostOptions(opt.hostOptions)
+		}
+	}
+	return so
+}
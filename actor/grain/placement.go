@@ -0,0 +1,78 @@
+package grain
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	xactorremote "github.com/75912001/xlib/actor/remote"
+	xetcd "github.com/75912001/xlib/etcd"
+	xhashring "github.com/75912001/xlib/hashring"
+)
+
+const placementRefreshIntervalDefault = time.Second // 成员刷新间隔
+
+// Placement 放置策略, 决定 key 由哪个服务承载
+//
+//	成员为 etcd.GRegistry 中 groupID, name 相同且提供 gRPC 服务的服务, 按一致性哈希分配
+//	并发安全
+type Placement struct {
+	groupID         uint32
+	name            string
+	refreshInterval time.Duration
+
+	mu          sync.Mutex
+	ring        *xhashring.HashRing[xactorremote.Node]
+	refreshTime time.Time
+}
+
+// NewPlacement 创建放置策略
+//
+//	groupID, name: 承载虚拟 actor 的服务
+func NewPlacement(groupID uint32, name string) *Placement {
+	return &Placement{
+		groupID:         groupID,
+		name:            name,
+		refreshInterval: placementRefreshIntervalDefault,
+		ring:            xhashring.NewHashRing[xactorremote.Node](),
+	}
+}
+
+// Owner 获取 key 所在的服务
+//
+//	成员超过刷新间隔时, 先刷新. 没有成员时返回 false
+func (p *Placement) Owner(key string) (xactorremote.Node, bool) {
+	p.mu.Lock()
+	if p.refreshInterval <= time.Since(p.refreshTime) {
+		p.refresh()
+	}
+	ring := p.ring
+	p.mu.Unlock()
+	return ring.GetNode(key)
+}
+
+// Refresh 立即刷新成员 [e.g.: etcd 回调中调用]
+func (p *Placement) Refresh() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.refresh()
+}
+
+func (p *Placement) refresh() {
+	p.refreshTime = time.Now()
+	var nodes []xactorremote.Node
+	for _, info := range xetcd.GRegistry.FindByGroupName(p.groupID, p.name) {
+		if info.ValueJson == nil || info.ValueJson.GrpcService == nil {
+			continue
+		}
+		nodes = append(nodes, xactorremote.NewNode(p.groupID, info.ServiceName, info.ServiceID))
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ServiceID < nodes[j].ServiceID
+	})
+	ring := xhashring.NewHashRing[xactorremote.Node]()
+	for _, node := range nodes {
+		ring = ring.AddNode(node)
+	}
+	p.ring = ring
+}
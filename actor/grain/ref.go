@@ -0,0 +1,66 @@
+package grain
+
+import (
+	xactor "github.com/75912001/xlib/actor"
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// Ref 虚拟 actor 引用
+//
+//	实现 xactor.IActorRef, 可作为 Actor.Ask / xactor.AskFuture 的目标
+type Ref struct {
+	cluster *Cluster
+	key     string
+}
+
+// GetKey 获取虚拟 actor key
+func (p *Ref) GetKey() string {
+	return p.key
+}
+
+func (p *Ref) GetPath() string {
+	return p.cluster.GrainPath(p.key)
+}
+
+// Send 发送消息 (异步)
+//
+//	只支持 *xactor.Msg
+func (p *Ref) Send(messages ...any) {
+	for _, message := range messages {
+		msg, ok := message.(*xactor.Msg)
+		if !ok {
+			p.cluster.host.GetSystem().DeadLetter(p.GetPath(), message)
+			continue
+		}
+		_ = p.TrySendMsg(msg)
+	}
+}
+
+func (p *Ref) SendMsg(messages ...*xactor.Msg) {
+	_ = p.TrySendMsg(messages...)
+}
+
+// TrySendMsg 发送消息 (异步)
+//
+//	无法投递时, 响应错误并返回
+func (p *Ref) TrySendMsg(messages ...*xactor.Msg) error {
+	var errs []error
+	for _, msg := range messages {
+		if err := p.cluster.deliver(p.key, msg); err != nil { // 失败时已响应
+			errs = append(errs, errors.WithMessagef(err, "grain ref path:%v %v", p.GetPath(), xruntime.Location()))
+		}
+	}
+	if len(errs) != 0 {
+		return errors.WithMessagef(xerror.Fail, "grain ref errs:%v %v", errs, xruntime.Location())
+	}
+	return nil
+}
+
+// SendMsgSync 发送消息, 并等待响应 (同步)
+//
+//	[❗] 禁止在 actor 的处理协程中调用, 请使用 Actor.Ask
+func (p *Ref) SendMsgSync(msg *xactor.Msg) (resp any, err error) {
+	return xactor.AskFuture(p, msg, 0).Result()
+}
@@ -6,5 +6,13 @@ type IActor[Key comparable] interface {
 
 type IActorMsg interface {
 	SendMsg(msg ...*Msg)
+	TrySendMsg(msg ...*Msg) error // 失败时, 同步/Ask 消息已响应错误, 调用方无需再响应
 	SendMsgSync(msg *Msg) (resp any, err error)
 }
+
+// IActorRef actor 引用 [类型无关], 用于 System 中按路径查找/监视
+type IActorRef interface {
+	IActorMsg
+	Send(messages ...any)
+	GetPath() string
+}
@@ -0,0 +1,25 @@
+package actor
+
+// ILifecycle actor 生命周期钩子 [可选]
+//
+//	首次 PreStart 在 Start 中(处理协程启动前)调用, 其余钩子均在该 actor 自己的处理协程中调用
+type ILifecycle interface {
+	PreStart() error         // 启动前/重启后. 返回错误视为失败, 交由父 actor 监督处理
+	PostStop()               // 停止后. 此时子 actor 已停止
+	PreRestart(reason error) // 重启前. reason: 失败原因. 调用后旧实例被丢弃
+}
+
+// Factory actor 实例的工厂
+//
+//	创建/重启时调用, 返回全新的行为函数与生命周期钩子(可为 nil)
+//	[❗] 重启时不会复用旧的 Behavior, 每次都重新调用 Factory 创建
+type Factory func() (behavior Behavior, lifecycle ILifecycle)
+
+// BehaviorFactory 由行为函数创建工厂
+//
+//	重启时, 行为恢复为 behavior (丢弃运行中切换的行为)
+func BehaviorFactory(behavior Behavior) Factory {
+	return func() (Behavior, ILifecycle) {
+		return behavior, nil
+	}
+}
@@ -5,10 +5,11 @@ import (
 )
 
 type Msg struct {
-	Ctx      context.Context        // 上下文, 可用于取消或设置超时
-	Cmd      CMD                    // 消息命令
-	Args     []any                  // 消息参数
-	syncChan chan *behaviorResponse // 消息响应通道, 用于同步 [nil 时表示异步,非 nil 时表示同步]
+	Ctx       context.Context           // 上下文, 可用于取消或设置超时
+	Cmd       CMD                       // 消息命令
+	Args      []any                     // 消息参数
+	syncChan  chan *behaviorResponse    // 消息响应通道, 用于同步 [nil 时表示异步,非 nil 时表示同步]
+	replyFunc func(resp any, err error) // 消息响应回调, 用于 Ask [nil 时表示无需响应]
 }
 
 // NewMsg 创建新的 Msg
@@ -28,6 +29,44 @@ func (p *Msg) withSyncChan(responseChan chan *behaviorResponse) *Msg {
 	return p
 }
 
+// WithReplyFunc 设置响应回调
+//
+//	用于扩展投递方式 (e.g.: 远程 actor), 普通调用请使用 SendMsgSync/Ask
+func (p *Msg) WithReplyFunc(replyFunc func(resp any, err error)) *Msg {
+	p.replyFunc = replyFunc
+	return p
+}
+
+// Reply 响应 [同步/Ask]
+//
+//	由 actor 在处理完成后调用. 扩展投递方式在无法投递时调用, 以响应错误
+func (p *Msg) Reply(resp any, err error) {
+	if p.syncChan != nil {
+		p.syncChan <- &behaviorResponse{
+			respData: resp,
+			err:      err,
+		}
+	}
+	if p.replyFunc != nil {
+		p.replyFunc(resp, err)
+	}
+}
+
+// Forward 转发
+//
+//	返回携带响应通道/回调的副本, 原消息不再需要响应. 用于行为函数中将消息转交给其它 actor
+func (p *Msg) Forward() *Msg {
+	msg := *p
+	p.syncChan = nil
+	p.replyFunc = nil
+	return &msg
+}
+
+// NeedReply 是否需要响应 [同步/Ask]
+func (p *Msg) NeedReply() bool {
+	return p.syncChan != nil || p.replyFunc != nil
+}
+
 // IsSync 是否同步
 //
 //	如果事件有响应通道, 则认为是同步调用
@@ -0,0 +1,97 @@
+package actor
+
+import (
+	xerror "github.com/75912001/xlib/error"
+	xevent "github.com/75912001/xlib/event"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// StashCapacityDefault 挂起期间暂存消息的最大数量 [default]
+const StashCapacityDefault uint32 = 1000
+
+type Options struct {
+	factory            Factory             // [required] actor 实例的工厂
+	supervisorStrategy *SupervisorStrategy // [optional] 对子 actor 的监督策略 [default]:NewSupervisorStrategy(StrategyOneForOne)
+	mailbox            *xevent.ListOptions // [optional] 邮箱选项(容量, 满时策略) [default]:不限制容量. 系统消息, 需要响应的消息不受容量限制
+	name               *string             // [optional] 名称, 作为路径中的一段 [default]:fmt.Sprint(key)
+	system             *System             // [optional] 所属 actor 系统 [default]:父 actor 的系统, 根 actor 为 GSystem
+	stashCapacity      *uint32             // [optional] 挂起期间暂存消息的最大数量, 超出的投递到死信. 0:不限制 [default]:StashCapacityDefault
+}
+
+// NewOptions 创建 Options
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func (p *Options) WithFactory(factory Factory) *Options {
+	p.factory = factory
+	return p
+}
+
+func (p *Options) WithSupervisorStrategy(strategy *SupervisorStrategy) *Options {
+	p.supervisorStrategy = strategy
+	return p
+}
+
+func (p *Options) WithMailbox(mailbox *xevent.ListOptions) *Options {
+	p.mailbox = mailbox
+	return p
+}
+
+func (p *Options) WithName(name string) *Options {
+	p.name = &name
+	return p
+}
+
+func (p *Options) WithSystem(system *System) *Options {
+	p.system = system
+	return p
+}
+
+func (p *Options) WithStashCapacity(stashCapacity uint32) *Options {
+	p.stashCapacity = &stashCapacity
+	return p
+}
+
+func mergeOptions(opts ...*Options) *Options {
+	so := NewOptions()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.factory != nil {
+			so.WithFactory(opt.factory)
+		}
+		if opt.supervisorStrategy != nil {
+			so.WithSupervisorStrategy(opt.supervisorStrategy)
+		}
+		if opt.mailbox != nil {
+			so.WithMailbox(opt.mailbox)
+		}
+		if opt.name != nil {
+			so.WithName(*opt.name)
+		}
+		if opt.system != nil {
+			so.WithSystem(opt.system)
+		}
+		if opt.stashCapacity != nil {
+			so.WithStashCapacity(*opt.stashCapacity)
+		}
+	}
+	return so
+}
+
+// 配置
+func configureOptions(opts *Options) error {
+	if opts.factory == nil {
+		return errors.WithMessagef(xerror.Param, "factory is nil. %v", xruntime.Location())
+	}
+	if opts.supervisorStrategy == nil {
+		opts.supervisorStrategy = NewSupervisorStrategy(StrategyOneForOne)
+	}
+	if opts.stashCapacity == nil {
+		opts.WithStashCapacity(StashCapacityDefault)
+	}
+	return nil
+}
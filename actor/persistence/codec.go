@@ -0,0 +1,34 @@
+package persistence
+
+import (
+	"encoding/json"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// ICodec 编解码
+type ICodec[T any] interface {
+	Marshal(v T) ([]byte, error)
+	Unmarshal(data []byte) (T, error)
+}
+
+// JsonCodec json 编解码 [default]
+type JsonCodec[T any] struct{}
+
+func (p JsonCodec[T]) Marshal(v T) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.WithMessagef(xerror.Marshal, "json err:%v %v", err, xruntime.Location())
+	}
+	return data, nil
+}
+
+func (p JsonCodec[T]) Unmarshal(data []byte) (T, error) {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, errors.WithMessagef(xerror.Unmarshal, "json err:%v %v", err, xruntime.Location())
+	}
+	return v, nil
+}
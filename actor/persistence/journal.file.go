@@ -0,0 +1,312 @@
+package persistence
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	xerror "github.com/75912001/xlib/error"
+	xlog "github.com/75912001/xlib/log"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// 记录格式: [长度 uint32][crc32 uint32][序号 uint64][时间 int64][数据]
+//
+//	长度, crc32 均针对 [序号 时间 数据]. 小端
+const (
+	recordHeaderSize = 8  // 长度 + crc32
+	recordFixedSize  = 16 // 序号 + 时间
+	recordMaxSize    = 64 << 20
+)
+
+// FileJournal 本地文件事件日志
+//
+//	每个 persistenceID 一个文件: ${dir}/${persistenceID}.journal, 追加写入, 每次追加后 fsync
+//	打开时校验记录, 尾部不完整的记录(写入时崩溃)会被截断. 追加失败时截断本次写入的记录, 并重新扫描
+//	DeleteTo 保留最大序号的事件, 重新打开后序号仍连续
+//	并发安全
+type FileJournal struct {
+	dir     string
+	mu      sync.Mutex
+	fileMap map[string]*journalFile // key: persistenceID
+}
+
+type journalFile struct {
+	file    *os.File
+	highest uint64 // 最大序号
+}
+
+// NewFileJournal 创建本地文件事件日志
+func NewFileJournal(dir string) (*FileJournal, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, errors.WithMessagef(err, "dir:%v %v", dir, xruntime.Location())
+	}
+	return &FileJournal{
+		dir:     dir,
+		fileMap: make(map[string]*journalFile),
+	}, nil
+}
+
+func (p *FileJournal) fileName(persistenceID string) string {
+	return filepath.Join(p.dir, url.PathEscape(persistenceID)+".journal")
+}
+
+// 打开文件, 校验并截断不完整的尾部
+func (p *FileJournal) open(persistenceID string) (*journalFile, error) {
+	if jf, ok := p.fileMap[persistenceID]; ok {
+		return jf, nil
+	}
+	name := p.fileName(persistenceID)
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "open journal:%v %v", name, xruntime.Location())
+	}
+	jf := &journalFile{file: file}
+	validSize, err := scanRecords(file, func(sequenceNr uint64, time int64, data []byte) error {
+		jf.highest = sequenceNr
+		return nil
+	})
+	if err != nil {
+		_ = file.Close()
+		return nil, errors.WithMessagef(err, "scan journal:%v %v", name, xruntime.Location())
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, errors.WithMessagef(err, "stat journal:%v %v", name, xruntime.Location())
+	}
+	if validSize < stat.Size() {
+		xlog.PrintfErr("journal:%v truncate incomplete tail. size:%v valid:%v", name, stat.Size(), validSize)
+		if err = file.Truncate(validSize); err != nil {
+			_ = file.Close()
+			return nil, errors.WithMessagef(err, "truncate journal:%v %v", name, xruntime.Location())
+		}
+	}
+	if _, err = file.Seek(validSize, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, errors.WithMessagef(err, "seek journal:%v %v", name, xruntime.Location())
+	}
+	p.fileMap[persistenceID] = jf
+	return jf, nil
+}
+
+// 从头读取记录, 返回完整记录的总长度
+func scanRecords(file *os.File, f func(sequenceNr uint64, time int64, data []byte) error) (int64, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, errors.WithMessage(err, xruntime.Location())
+	}
+	reader := bufio.NewReader(file)
+	var validSize int64
+	header := make([]byte, recordHeaderSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return validSize, nil // EOF 或 不完整的头
+		}
+		length := binary.LittleEndian.Uint32(header[0:4])
+		checksum := binary.LittleEndian.Uint32(header[4:8])
+		if length < recordFixedSize || recordMaxSize < length {
+			return validSize, nil
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			return validSize, nil
+		}
+		if crc32.ChecksumIEEE(body) != checksum {
+			return validSize, nil
+		}
+		sequenceNr := binary.LittleEndian.Uint64(body[0:8])
+		time := int64(binary.LittleEndian.Uint64(body[8:16]))
+		if err := f(sequenceNr, time, body[recordFixedSize:]); err != nil {
+			return validSize, err
+		}
+		validSize += int64(recordHeaderSize) + int64(length)
+	}
+}
+
+func appendRecord(buf []byte, event *Event) []byte {
+	length := recordFixedSize + len(event.Data)
+	body := make([]byte, length)
+	binary.LittleEndian.PutUint64(body[0:8], event.SequenceNr)
+	binary.LittleEndian.PutUint64(body[8:16], uint64(event.Time))
+	copy(body[recordFixedSize:], event.Data)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(length))
+	buf = binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(body))
+	return append(buf, body...)
+}
+
+// Append 追加事件
+//
+//	同一 persistenceID 的序号须与已有事件连续(没有事件时从 1 开始), 否则返回 xerror.Conflict [e.g.: 同一 actor 被重复激活]
+func (p *FileJournal) Append(events ...*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	persistenceID := events[0].PersistenceID
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	jf, err := p.open(persistenceID)
+	if err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	var buf []byte
+	expect := jf.highest + 1
+	for _, event := range events {
+		if event.PersistenceID != persistenceID || event.SequenceNr != expect {
+			return errors.WithMessagef(xerror.Conflict, "persistenceID:%v sequenceNr:%v expect:%v %v",
+				event.PersistenceID, event.SequenceNr, expect, xruntime.Location())
+		}
+		buf = appendRecord(buf, event)
+		expect++
+	}
+	size, err := jf.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		p.closeFile(persistenceID)
+		return errors.WithMessagef(err, "seek persistenceID:%v %v", persistenceID, xruntime.Location())
+	}
+	if _, err = jf.file.Write(buf); err != nil {
+		p.rollback(persistenceID, jf, size)
+		return errors.WithMessagef(err, "write persistenceID:%v %v", persistenceID, xruntime.Location())
+	}
+	if err = jf.file.Sync(); err != nil { // 记录可能已在磁盘上
+		p.rollback(persistenceID, jf, size)
+		return errors.WithMessagef(err, "sync persistenceID:%v %v", persistenceID, xruntime.Location())
+	}
+	jf.highest = expect - 1
+	return nil
+}
+
+// 追加失败, 截断至写入前的大小, 重新打开并扫描. 截断失败时, 最大序号以扫描结果为准
+func (p *FileJournal) rollback(persistenceID string, jf *journalFile, size int64) {
+	if err := jf.file.Truncate(size); err != nil {
+		xlog.PrintfErr("journal persistenceID:%v truncate:%v err:%v", persistenceID, size, err)
+	} else if err = jf.file.Sync(); err != nil {
+		xlog.PrintfErr("journal persistenceID:%v sync err:%v", persistenceID, err)
+	}
+	p.closeFile(persistenceID)
+	if _, err := p.open(persistenceID); err != nil {
+		xlog.PrintfErr("journal persistenceID:%v reopen err:%v", persistenceID, err)
+	}
+}
+
+func (p *FileJournal) Replay(persistenceID string, fromSequenceNr uint64, f func(event *Event) error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	jf, err := p.open(persistenceID)
+	if err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	validSize, err := scanRecords(jf.file, func(sequenceNr uint64, time int64, data []byte) error {
+		if sequenceNr < fromSequenceNr {
+			return nil
+		}
+		return f(&Event{
+			PersistenceID: persistenceID,
+			SequenceNr:    sequenceNr,
+			Time:          time,
+			Data:          data,
+		})
+	})
+	if _, errSeek := jf.file.Seek(validSize, io.SeekStart); errSeek != nil {
+		p.closeFile(persistenceID)
+		return errors.WithMessagef(errSeek, "seek persistenceID:%v %v", persistenceID, xruntime.Location())
+	}
+	if err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	return nil
+}
+
+func (p *FileJournal) HighestSequenceNr(persistenceID string) (uint64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	jf, err := p.open(persistenceID)
+	if err != nil {
+		return 0, errors.WithMessage(err, xruntime.Location())
+	}
+	return jf.highest, nil
+}
+
+// DeleteTo 删除序号 <= toSequenceNr 的事件, 保留最大序号的事件
+//
+//	重写到临时文件, 再替换
+func (p *FileJournal) DeleteTo(persistenceID string, toSequenceNr uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	jf, err := p.open(persistenceID)
+	if err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	var buf []byte
+	_, err = scanRecords(jf.file, func(sequenceNr uint64, time int64, data []byte) error {
+		if toSequenceNr < sequenceNr || sequenceNr == jf.highest { // 保留最大序号, 重新打开后序号连续
+			buf = appendRecord(buf, &Event{SequenceNr: sequenceNr, Time: time, Data: data})
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	name := p.fileName(persistenceID)
+	if err = writeFileAtomic(name, buf); err != nil {
+		return errors.WithMessagef(err, "persistenceID:%v %v", persistenceID, xruntime.Location())
+	}
+	p.closeFile(persistenceID)
+	if _, err = p.open(persistenceID); err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	return nil
+}
+
+// Close 关闭所有文件
+func (p *FileJournal) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs []error
+	for persistenceID, jf := range p.fileMap {
+		if err := jf.file.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(p.fileMap, persistenceID)
+	}
+	if len(errs) != 0 {
+		return errors.WithMessagef(xerror.Fail, "close errs:%v %v", errs, xruntime.Location())
+	}
+	return nil
+}
+
+func (p *FileJournal) closeFile(persistenceID string) {
+	if jf, ok := p.fileMap[persistenceID]; ok {
+		_ = jf.file.Close()
+		delete(p.fileMap, persistenceID)
+	}
+}
+
+// 写入临时文件, fsync 后替换
+func writeFileAtomic(name string, data []byte) error {
+	tmpName := name + ".tmp"
+	file, err := os.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	if _, err = file.Write(data); err != nil {
+		_ = file.Close()
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	if err = file.Sync(); err != nil {
+		_ = file.Close()
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	if err = file.Close(); err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	if err = os.Rename(tmpName, name); err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	return nil
+}
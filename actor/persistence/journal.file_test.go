@@ -0,0 +1,199 @@
+package persistence
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	xerror "github.com/75912001/xlib/error"
+	"github.com/pkg/errors"
+)
+
+const testPersistenceID = "test.1"
+
+func newTestEvent(sequenceNr uint64) *Event {
+	return &Event{
+		PersistenceID: testPersistenceID,
+		SequenceNr:    sequenceNr,
+		Time:          int64(sequenceNr),
+		Data:          []byte{byte(sequenceNr)},
+	}
+}
+
+func appendTestEvents(t *testing.T, journal *FileJournal, sequenceNrs ...uint64) {
+	t.Helper()
+	for _, sequenceNr := range sequenceNrs {
+		if err := journal.Append(newTestEvent(sequenceNr)); err != nil {
+			t.Fatalf("append:%v err:%v", sequenceNr, err)
+		}
+	}
+}
+
+// 重放的序号
+func replayTestEvents(t *testing.T, journal *FileJournal, fromSequenceNr uint64) []uint64 {
+	t.Helper()
+	var got []uint64
+	err := journal.Replay(testPersistenceID, fromSequenceNr, func(event *Event) error {
+		if event.Time != int64(event.SequenceNr) || !slices.Equal(event.Data, []byte{byte(event.SequenceNr)}) {
+			t.Fatalf("event:%+v", event)
+		}
+		got = append(got, event.SequenceNr)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+// 关闭后重新打开
+func reopenTestJournal(t *testing.T, journal *FileJournal) *FileJournal {
+	t.Helper()
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	journal, err := NewFileJournal(journal.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return journal
+}
+
+func checkHighest(t *testing.T, journal *FileJournal, want uint64) {
+	t.Helper()
+	highest, err := journal.HighestSequenceNr(testPersistenceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if highest != want {
+		t.Fatalf("highest:%v want:%v", highest, want)
+	}
+}
+
+func TestFileJournalRecover(t *testing.T) {
+	journal, err := NewFileJournal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = journal.Append(newTestEvent(1), newTestEvent(2)); err != nil {
+		t.Fatal(err)
+	}
+	appendTestEvents(t, journal, 3)
+
+	journal = reopenTestJournal(t, journal)
+	defer func() { _ = journal.Close() }()
+	checkHighest(t, journal, 3)
+	if got := replayTestEvents(t, journal, 2); !slices.Equal(got, []uint64{2, 3}) {
+		t.Fatalf("got:%v", got)
+	}
+	appendTestEvents(t, journal, 4)
+}
+
+// 序号须连续, 没有事件时从 1 开始
+func TestFileJournalAppendConflict(t *testing.T) {
+	journal, err := NewFileJournal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = journal.Close() }()
+	if err = journal.Append(newTestEvent(5)); !errors.Is(err, xerror.Conflict) {
+		t.Fatalf("err:%v", err)
+	}
+	checkHighest(t, journal, 0)
+	appendTestEvents(t, journal, 1, 2)
+	for _, sequenceNr := range []uint64{1, 2, 4} {
+		if err = journal.Append(newTestEvent(sequenceNr)); !errors.Is(err, xerror.Conflict) {
+			t.Fatalf("sequenceNr:%v err:%v", sequenceNr, err)
+		}
+	}
+	// 同一批中不连续, 均不追加
+	if err = journal.Append(newTestEvent(3), newTestEvent(5)); !errors.Is(err, xerror.Conflict) {
+		t.Fatalf("err:%v", err)
+	}
+	checkHighest(t, journal, 2)
+	appendTestEvents(t, journal, 3)
+}
+
+// 重新打开时, 截断尾部不完整或损坏的记录
+func TestFileJournalTruncateTail(t *testing.T) {
+	record := appendRecord(nil, newTestEvent(3))
+	tests := []struct {
+		name   string
+		modify func(data []byte) []byte
+	}{
+		{"partial header", func(data []byte) []byte { return append(data, record[:recordHeaderSize-1]...) }},
+		{"partial body", func(data []byte) []byte { return append(data, record[:len(record)-1]...) }},
+		{"garbage", func(data []byte) []byte { return append(data, 0xff, 0xff, 0xff, 0xff, 0x01, 0x02, 0x03, 0x04, 0x05) }},
+		{"crc", func(data []byte) []byte {
+			corrupt := slices.Clone(record)
+			corrupt[len(corrupt)-1] ^= 0xff
+			return append(data, corrupt...)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			journal, err := NewFileJournal(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			appendTestEvents(t, journal, 1, 2)
+			if err = journal.Close(); err != nil {
+				t.Fatal(err)
+			}
+			name := journal.fileName(testPersistenceID)
+			data, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			validSize := len(data)
+			if err = os.WriteFile(name, test.modify(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			journal = reopenTestJournal(t, journal)
+			checkHighest(t, journal, 2)
+			if stat, err := os.Stat(name); err != nil || stat.Size() != int64(validSize) {
+				t.Fatalf("stat:%v err:%v want:%v", stat, err, validSize)
+			}
+			appendTestEvents(t, journal, 3)
+
+			journal = reopenTestJournal(t, journal)
+			defer func() { _ = journal.Close() }()
+			if got := replayTestEvents(t, journal, 1); !slices.Equal(got, []uint64{1, 2, 3}) {
+				t.Fatalf("got:%v", got)
+			}
+		})
+	}
+}
+
+// 删除后保留最大序号的事件, 重新打开后序号仍连续
+func TestFileJournalDeleteTo(t *testing.T) {
+	journal, err := NewFileJournal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendTestEvents(t, journal, 1, 2, 3)
+	if err = journal.DeleteTo(testPersistenceID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := replayTestEvents(t, journal, 1); !slices.Equal(got, []uint64{2, 3}) {
+		t.Fatalf("got:%v", got)
+	}
+	if err = journal.DeleteTo(testPersistenceID, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := replayTestEvents(t, journal, 1); !slices.Equal(got, []uint64{3}) {
+		t.Fatalf("got:%v", got)
+	}
+
+	journal = reopenTestJournal(t, journal)
+	defer func() { _ = journal.Close() }()
+	checkHighest(t, journal, 3)
+	if got := replayTestEvents(t, journal, 4); len(got) != 0 {
+		t.Fatalf("got:%v", got)
+	}
+	if err = journal.Append(newTestEvent(2)); !errors.Is(err, xerror.Conflict) {
+		t.Fatalf("err:%v", err)
+	}
+	appendTestEvents(t, journal, 4)
+}
@@ -0,0 +1,75 @@
+package persistence
+
+import (
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+type Options struct {
+	journal          IJournal       // [required] 事件日志
+	snapshotStore    ISnapshotStore // [optional] 快照存储 [default]:nil, 不保存快照
+	snapshotEvery    *uint64        // [optional] 每多少个事件保存一次快照 [default]:0, 不保存快照
+	deleteOnSnapshot *bool          // [optional] 保存快照后, 删除快照之前的事件 [default]:false
+}
+
+// NewOptions 创建 Options
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func (p *Options) WithJournal(journal IJournal) *Options {
+	p.journal = journal
+	return p
+}
+
+func (p *Options) WithSnapshotStore(store ISnapshotStore) *Options {
+	p.snapshotStore = store
+	return p
+}
+
+func (p *Options) WithSnapshotEvery(every uint64) *Options {
+	p.snapshotEvery = &every
+	return p
+}
+
+func (p *Options) WithDeleteOnSnapshot(enable bool) *Options {
+	p.deleteOnSnapshot = &enable
+	return p
+}
+
+func mergeOptions(opts ...*Options) *Options {
+	so := NewOptions()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.journal != nil {
+			so.WithJournal(opt.journal)
+		}
+		if opt.snapshotStore != nil {
+			so.WithSnapshotStore(opt.snapshotStore)
+		}
+		if opt.snapshotEvery != nil {
+			so.WithSnapshotEvery(*opt.snapshotEvery)
+		}
+		if opt.deleteOnSnapshot != nil {
+			so.WithDeleteOnSnapshot(*opt.deleteOnSnapshot)
+		}
+	}
+	return so
+}
+
+// 配置
+func configure(opts *Options) error {
+	if opts.journal == nil {
+		return errors.WithMessagef(xerror.Param, "journal is nil. %v", xruntime.Location())
+	}
+	if opts.snapshotEvery == nil {
+		opts.WithSnapshotEvery(0)
+	}
+	if opts.deleteOnSnapshot == nil {
+		opts.WithDeleteOnSnapshot(false)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package persistence
+
+// Event 日志中的事件
+type Event struct {
+	PersistenceID string // 持久化ID
+	SequenceNr    uint64 // 序号, 从 1 开始连续递增
+	Time          int64  // 时间戳-毫秒
+	Data          []byte // 编码后的事件
+}
+
+// Snapshot 快照
+type Snapshot struct {
+	PersistenceID string // 持久化ID
+	SequenceNr    uint64 // 快照包含的最后一个事件的序号
+	Time          int64  // 时间戳-毫秒
+	Data          []byte // 编码后的状态
+}
+
+// IJournal 事件日志
+type IJournal interface {
+	// Append 追加事件, 返回时须已落盘
+	Append(events ...*Event) error
+	// Replay 按序号顺序重放 [fromSequenceNr, 最大序号] 的事件
+	Replay(persistenceID string, fromSequenceNr uint64, f func(event *Event) error) error
+	// HighestSequenceNr 最大序号, 没有事件时为 0
+	HighestSequenceNr(persistenceID string) (uint64, error)
+	// DeleteTo 删除序号 <= toSequenceNr 的事件 [快照之后]. HighestSequenceNr 须不变
+	DeleteTo(persistenceID string, toSequenceNr uint64) error
+}
+
+// ISnapshotStore 快照存储
+type ISnapshotStore interface {
+	// Save 保存快照, 替换旧快照
+	Save(snapshot *Snapshot) error
+	// Load 加载最新快照, 不存在时返回 nil
+	Load(persistenceID string) (*Snapshot, error)
+}
+
+// IHandler 事件溯源的处理器
+//
+//	在 actor 的处理协程中调用
+type IHandler[STATE any, EVENT any] interface {
+	// Init 初始状态
+	Init() STATE
+	// Command 处理消息, 返回需要持久化的事件与响应. 不可修改 state
+	Command(state STATE, messages ...any) (events []EVENT, resp any, err error)
+	// Apply 应用事件, 返回新状态. 事件落盘后 与 恢复时调用, 须是确定性的
+	Apply(state STATE, event EVENT) STATE
+}
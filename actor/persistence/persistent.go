@@ -0,0 +1,179 @@
+package persistence
+
+import (
+	"time"
+
+	xactor "github.com/75912001/xlib/actor"
+	xlog "github.com/75912001/xlib/log"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// Persistent 事件溯源的 actor 行为
+//
+//	处理消息时, IHandler.Command 产生的事件先追加到日志(落盘), 成功后才应用到状态, 失败时状态不变并响应错误
+//	追加失败时, 事件可能已落盘, 处理下一条消息前从存储重新恢复
+//	启动/重启时, 加载最新快照, 再重放快照之后的事件, 恢复状态
+type Persistent[STATE any, EVENT any] struct {
+	persistenceID string
+	handler       IHandler[STATE, EVENT]
+	options       *Options
+	eventCodec    ICodec[EVENT]
+	stateCodec    ICodec[STATE]
+}
+
+// NewPersistent 创建事件溯源的 actor 行为
+//
+//	persistenceID: 持久化ID, 全局唯一. e.g.: trade.42
+//	默认使用 JsonCodec 编解码事件与状态
+func NewPersistent[STATE any, EVENT any](persistenceID string, handler IHandler[STATE, EVENT], opts ...*Options) (*Persistent[STATE, EVENT], error) {
+	opt := mergeOptions(opts...)
+	if err := configure(opt); err != nil {
+		return nil, errors.WithMessagef(err, "persistenceID:%v %v", persistenceID, xruntime.Location())
+	}
+	return &Persistent[STATE, EVENT]{
+		persistenceID: persistenceID,
+		handler:       handler,
+		options:       opt,
+		eventCodec:    JsonCodec[EVENT]{},
+		stateCodec:    JsonCodec[STATE]{},
+	}, nil
+}
+
+// WithCodec 设置编解码 [e.g.: protobuf]
+func (p *Persistent[STATE, EVENT]) WithCodec(eventCodec ICodec[EVENT], stateCodec ICodec[STATE]) *Persistent[STATE, EVENT] {
+	p.eventCodec = eventCodec
+	p.stateCodec = stateCodec
+	return p
+}
+
+// Factory actor 工厂, 用于 xactor.Options.WithFactory 或 Spawn
+//
+//	每次启动/重启创建新的实例, 从存储中恢复状态
+func (p *Persistent[STATE, EVENT]) Factory() xactor.Factory {
+	return func() (xactor.Behavior, xactor.ILifecycle) {
+		i := &instance[STATE, EVENT]{
+			persistent: p,
+		}
+		return i.receive, i
+	}
+}
+
+// 实例
+type instance[STATE any, EVENT any] struct {
+	persistent         *Persistent[STATE, EVENT]
+	state              STATE
+	sequenceNr         uint64 // 已应用的最后一个事件的序号
+	snapshotSequenceNr uint64 // 最新快照的序号
+	needRecover        bool   // 追加失败, 状态可能与日志不一致
+}
+
+// PreStart 恢复
+func (p *instance[STATE, EVENT]) PreStart() error {
+	persistent := p.persistent
+	id := persistent.persistenceID
+	p.state = persistent.handler.Init()
+	p.sequenceNr = 0
+	p.snapshotSequenceNr = 0
+	p.needRecover = false
+	if persistent.options.snapshotStore != nil {
+		snapshot, err := persistent.options.snapshotStore.Load(id)
+		if err != nil {
+			return errors.WithMessagef(err, "load snapshot persistenceID:%v %v", id, xruntime.Location())
+		}
+		if snapshot != nil {
+			if p.state, err = persistent.stateCodec.Unmarshal(snapshot.Data); err != nil {
+				return errors.WithMessagef(err, "snapshot persistenceID:%v sequenceNr:%v %v", id, snapshot.SequenceNr, xruntime.Location())
+			}
+			p.sequenceNr = snapshot.SequenceNr
+			p.snapshotSequenceNr = snapshot.SequenceNr
+		}
+	}
+	err := persistent.options.journal.Replay(id, p.sequenceNr+1, func(event *Event) error {
+		e, err := persistent.eventCodec.Unmarshal(event.Data)
+		if err != nil {
+			return errors.WithMessagef(err, "event persistenceID:%v sequenceNr:%v %v", id, event.SequenceNr, xruntime.Location())
+		}
+		p.state = persistent.handler.Apply(p.state, e)
+		p.sequenceNr = event.SequenceNr
+		return nil
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "replay persistenceID:%v %v", id, xruntime.Location())
+	}
+	return nil
+}
+
+func (p *instance[STATE, EVENT]) PostStop() {
+}
+
+func (p *instance[STATE, EVENT]) PreRestart(reason error) {
+}
+
+func (p *instance[STATE, EVENT]) receive(messages ...any) (xactor.Behavior, any, error) {
+	persistent := p.persistent
+	if p.needRecover {
+		if err := p.PreStart(); err != nil {
+			p.needRecover = true
+			return p.receive, nil, errors.WithMessagef(err, "recover persistenceID:%v %v", persistent.persistenceID, xruntime.Location())
+		}
+	}
+	events, resp, err := persistent.handler.Command(p.state, messages...)
+	if err != nil || len(events) == 0 {
+		return p.receive, resp, err
+	}
+	now := time.Now().UnixMilli()
+	journalEvents := make([]*Event, 0, len(events))
+	for i, e := range events {
+		data, err := persistent.eventCodec.Marshal(e)
+		if err != nil {
+			return p.receive, nil, errors.WithMessagef(err, "persistenceID:%v %v", persistent.persistenceID, xruntime.Location())
+		}
+		journalEvents = append(journalEvents, &Event{
+			PersistenceID: persistent.persistenceID,
+			SequenceNr:    p.sequenceNr + uint64(i) + 1,
+			Time:          now,
+			Data:          data,
+		})
+	}
+	if err = persistent.options.journal.Append(journalEvents...); err != nil { // 状态不变
+		p.needRecover = true
+		return p.receive, nil, errors.WithMessagef(err, "persistenceID:%v %v", persistent.persistenceID, xruntime.Location())
+	}
+	for _, e := range events {
+		p.state = persistent.handler.Apply(p.state, e)
+	}
+	p.sequenceNr += uint64(len(events))
+	p.trySnapshot(now)
+	return p.receive, resp, nil
+}
+
+// 达到间隔时保存快照. 失败只记录日志, 恢复时重放更多事件
+func (p *instance[STATE, EVENT]) trySnapshot(now int64) {
+	persistent := p.persistent
+	every := *persistent.options.snapshotEvery
+	if persistent.options.snapshotStore == nil || every == 0 || p.sequenceNr-p.snapshotSequenceNr < every {
+		return
+	}
+	data, err := persistent.stateCodec.Marshal(p.state)
+	if err != nil {
+		xlog.PrintfErr("snapshot persistenceID:%v err:%v", persistent.persistenceID, err)
+		return
+	}
+	err = persistent.options.snapshotStore.Save(&Snapshot{
+		PersistenceID: persistent.persistenceID,
+		SequenceNr:    p.sequenceNr,
+		Time:          now,
+		Data:          data,
+	})
+	if err != nil {
+		xlog.PrintfErr("snapshot persistenceID:%v err:%v", persistent.persistenceID, err)
+		return
+	}
+	p.snapshotSequenceNr = p.sequenceNr
+	if *persistent.options.deleteOnSnapshot {
+		if err = persistent.options.journal.DeleteTo(persistent.persistenceID, p.sequenceNr); err != nil {
+			xlog.PrintfErr("journal delete persistenceID:%v err:%v", persistent.persistenceID, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package persistence
+
+import (
+	"testing"
+
+	xerror "github.com/75912001/xlib/error"
+	"github.com/pkg/errors"
+)
+
+// 累加. 消息: 增量, 响应: 当前值
+type testCounter struct{}
+
+func (p testCounter) Init() int {
+	return 0
+}
+
+func (p testCounter) Command(state int, messages ...any) ([]int, any, error) {
+	delta := messages[0].(int)
+	return []int{delta}, state + delta, nil
+}
+
+func (p testCounter) Apply(state int, event int) int {
+	return state + event
+}
+
+// 事件落盘后返回错误 [e.g.: fsync 失败]
+type testFailJournal struct {
+	IJournal
+	fail int // 剩余失败次数
+}
+
+func (p *testFailJournal) Append(events ...*Event) error {
+	if err := p.IJournal.Append(events...); err != nil {
+		return err
+	}
+	if 0 < p.fail {
+		p.fail--
+		return errors.WithMessage(xerror.Fail, "test sync")
+	}
+	return nil
+}
+
+// 追加失败后, 处理下一条消息前从日志恢复, 序号连续
+func TestPersistentRecoverAfterAppendError(t *testing.T) {
+	fileJournal, err := NewFileJournal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = fileJournal.Close() }()
+	journal := &testFailJournal{IJournal: fileJournal}
+	persistent, err := NewPersistent[int, int]("counter.1", testCounter{}, NewOptions().WithJournal(journal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	behavior, lifecycle := persistent.Factory()()
+	if err = lifecycle.PreStart(); err != nil {
+		t.Fatal(err)
+	}
+	if _, resp, err := behavior(1); err != nil || resp != 1 {
+		t.Fatalf("resp:%v err:%v", resp, err)
+	}
+	journal.fail = 1
+	if _, _, err = behavior(10); !errors.Is(err, xerror.Fail) {
+		t.Fatalf("err:%v", err)
+	}
+	// 失败的事件已落盘, 恢复后包含
+	if _, resp, err := behavior(100); err != nil || resp != 111 {
+		t.Fatalf("resp:%v err:%v", resp, err)
+	}
+	if highest, err := fileJournal.HighestSequenceNr("counter.1"); err != nil || highest != 3 {
+		t.Fatalf("highest:%v err:%v", highest, err)
+	}
+}
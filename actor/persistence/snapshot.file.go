@@ -0,0 +1,75 @@
+package persistence
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// FileSnapshotStore 本地文件快照存储
+//
+//	每个 persistenceID 一个文件: ${dir}/${persistenceID}.snapshot, 写入临时文件后替换, 不会出现不完整的快照
+//	格式: [crc32 uint32][序号 uint64][时间 int64][数据], crc32 针对其后的内容. 小端
+//	并发安全
+type FileSnapshotStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileSnapshotStore 创建本地文件快照存储
+func NewFileSnapshotStore(dir string) (*FileSnapshotStore, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, errors.WithMessagef(err, "dir:%v %v", dir, xruntime.Location())
+	}
+	return &FileSnapshotStore{
+		dir: dir,
+	}, nil
+}
+
+func (p *FileSnapshotStore) fileName(persistenceID string) string {
+	return filepath.Join(p.dir, url.PathEscape(persistenceID)+".snapshot")
+}
+
+func (p *FileSnapshotStore) Save(snapshot *Snapshot) error {
+	body := make([]byte, recordFixedSize+len(snapshot.Data))
+	binary.LittleEndian.PutUint64(body[0:8], snapshot.SequenceNr)
+	binary.LittleEndian.PutUint64(body[8:16], uint64(snapshot.Time))
+	copy(body[recordFixedSize:], snapshot.Data)
+	data := binary.LittleEndian.AppendUint32(make([]byte, 0, 4+len(body)), crc32.ChecksumIEEE(body))
+	data = append(data, body...)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := writeFileAtomic(p.fileName(snapshot.PersistenceID), data); err != nil {
+		return errors.WithMessagef(err, "persistenceID:%v %v", snapshot.PersistenceID, xruntime.Location())
+	}
+	return nil
+}
+
+func (p *FileSnapshotStore) Load(persistenceID string) (*Snapshot, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	data, err := os.ReadFile(p.fileName(persistenceID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithMessagef(err, "persistenceID:%v %v", persistenceID, xruntime.Location())
+	}
+	if len(data) < 4+recordFixedSize || crc32.ChecksumIEEE(data[4:]) != binary.LittleEndian.Uint32(data[0:4]) {
+		return nil, errors.WithMessagef(xerror.Invalid, "snapshot checksum. persistenceID:%v %v", persistenceID, xruntime.Location())
+	}
+	body := data[4:]
+	return &Snapshot{
+		PersistenceID: persistenceID,
+		SequenceNr:    binary.LittleEndian.Uint64(body[0:8]),
+		Time:          int64(binary.LittleEndian.Uint64(body[8:16])),
+		Data:          body[recordFixedSize:],
+	}, nil
+}
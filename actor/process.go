@@ -13,28 +13,42 @@ import (
 
 // process 处理消息的主循环
 func (p *Actor[KEY]) process(args ...any) (err error) {
+	msg := args[0]
+	if p.stopped.Load() { // 已停止, 停止前已入队的消息投递到死信
+		p.deadLetter(msg)
+		return nil
+	}
+	if p.suspended && !isSystemMsg(msg) { // 挂起中, 暂存用户消息, 等待父 actor 处理(重启)后再处理
+		if capacity := *p.options.stashCapacity; 0 < capacity && capacity <= uint32(len(p.stash)) { // 暂存已满, 投递到死信
+			p.deadLetterWithError(errors.WithMessagef(xerror.ChannelFull, "actor stash full. path:%v capacity:%v %v", p.path, capacity, xruntime.Location()), msg)
+			return nil
+		}
+		p.stash = append(p.stash, msg)
+		return nil
+	}
 	start := time.Now()
 	var resp any
-	var syncMsg *Msg // 非 nil 表示当前在处理 *Msg，defer 中需在 recover 后补发同步响应
+	var syncMsg *Msg // 非 nil 表示当前在处理 *Msg，defer 中需在 recover 后补发同步/Ask 响应
+	var panicked bool
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("panic: %v stack:%v", r, string(debug.Stack()))
 			xlog.PrintfErr("Actor process panic key:%v err:%v", p.key, err)
+			panicked = true
 		}
 		// 同步消息必须在 recover 之后发送，否则 behavior 等路径 panic 会跳过原 syncChan 发送，导致 SendMsgSync 误判超时
-		if syncMsg != nil && syncMsg.IsSync() {
-			syncMsg.syncChan <- &behaviorResponse{
-				respData: resp,
-				err:      err,
-			}
+		if syncMsg != nil {
+			syncMsg.Reply(resp, err)
 		}
 		p.Statistics.ProcessTime += time.Since(start)
 		p.Statistics.Count++
 		if err != nil {
 			p.Statistics.ErrorCount++
 		}
+		if panicked { // 行为可能已损坏, 交由父 actor 监督处理
+			p.fail(err)
+		}
 	}()
-	msg := args[0]
 	switch message := msg.(type) {
 	case *Msg: // 处理 Msg
 		syncMsg = message
@@ -48,6 +62,12 @@ func (p *Actor[KEY]) process(args ...any) (err error) {
 				resp, err = p.spawn(message)
 			case SystemReservedCommand_GetChild:
 				resp, err = p.getChild(message)
+			case SystemReservedCommand_Failure:
+				resp, err = p.failure(message)
+			case SystemReservedCommand_Restart:
+				resp, err = p.restart(message)
+			case SystemReservedCommand_Timer:
+				resp, err = p.timer(message)
 			default:
 				err = fmt.Errorf("unknown system reserved command: %v %v", message.Cmd, xruntime.Location())
 			}
@@ -75,3 +95,17 @@ func (p *Actor[KEY]) process(args ...any) (err error) {
 		return
 	}
 }
+
+// 是否-系统保留命令消息
+func isSystemMsg(msg any) bool {
+	message, ok := msg.(*Msg)
+	return ok && isSystemReservedCommand(message.Cmd)
+}
+
+// 是否-不受邮箱容量限制的消息: 系统保留命令消息(停止, 重启, 监督, 定时器), 需要响应的消息(同步/Ask)
+//
+//	避免邮箱满时丢失生命周期消息, 或调用方等待不到响应
+func isUnboundedMsg(msg any) bool {
+	message, ok := msg.(*Msg)
+	return ok && (isSystemReservedCommand(message.Cmd) || message.NeedReply())
+}
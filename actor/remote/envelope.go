@@ -0,0 +1,35 @@
+package remote
+
+import (
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// 消息参数/响应 -> anypb.Any
+//
+//	必须为 proto.Message
+func toAny(v any) (*anypb.Any, error) {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return nil, errors.WithMessagef(xerror.ParamNotSupport, "not proto.Message. type:%T %v", v, xruntime.Location())
+	}
+	a, err := anypb.New(message)
+	if err != nil {
+		return nil, errors.WithMessagef(xerror.Marshal, "type:%T err:%v %v", v, err, xruntime.Location())
+	}
+	return a, nil
+}
+
+// anypb.Any -> 消息参数/响应
+//
+//	消息类型须已注册 [由 protoc 生成的代码注册]
+func fromAny(a *anypb.Any) (any, error) {
+	message, err := a.UnmarshalNew()
+	if err != nil {
+		return nil, errors.WithMessagef(xerror.Unmarshal, "typeUrl:%v err:%v %v", a.GetTypeUrl(), err, xruntime.Location())
+	}
+	return message, nil
+}
@@ -0,0 +1,120 @@
+package remote
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	xremoteproto "github.com/75912001/xlib/actor/remote/proto"
+	xerror "github.com/75912001/xlib/error"
+	xlog "github.com/75912001/xlib/log"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// 流 [grpc.ClientStream, grpc.ServerStream]
+type frameStream interface {
+	SendMsg(m any) error
+	RecvMsg(m any) error
+}
+
+// 链接
+//
+//	发送经由发送通道, 由发送协程写入流, 不阻塞调用方
+type link struct {
+	name     string           // 名称 [出站: 节点, 入站: 对端]
+	stream   frameStream      // 流
+	conn     *grpc.ClientConn // 出站连接 [入站: nil]
+	cancel   context.CancelFunc
+	sendChan chan []byte
+	doneChan chan struct{}
+	once     sync.Once
+	closed   atomic.Bool
+}
+
+func newLink(name string, stream frameStream, conn *grpc.ClientConn, cancel context.CancelFunc, sendChanCapacity uint32) *link {
+	return &link{
+		name:     name,
+		stream:   stream,
+		conn:     conn,
+		cancel:   cancel,
+		sendChan: make(chan []byte, sendChanCapacity),
+		doneChan: make(chan struct{}),
+	}
+}
+
+// 启动发送协程
+func (p *link) start() {
+	go func() {
+		defer func() {
+			if xruntime.IsRelease() {
+				if err := recover(); err != nil {
+					xlog.PrintErr(xerror.GoroutinePanic, err, debug.Stack())
+				}
+			}
+			xlog.PrintInfo(xerror.GoroutineDone, p.name)
+		}()
+		for {
+			select {
+			case <-p.doneChan:
+				return
+			case data := <-p.sendChan:
+				if err := p.stream.SendMsg(&wrapperspb.BytesValue{Value: data}); err != nil {
+					xlog.PrintfErr("remote link send err. link:%v err:%v", p.name, err)
+					p.close()
+					return
+				}
+			}
+		}
+	}()
+}
+
+// 发送
+func (p *link) send(env *xremoteproto.Envelope) error {
+	if p.closed.Load() {
+		return errors.WithMessagef(xerror.Link, "remote link closed. link:%v %v", p.name, xruntime.Location())
+	}
+	data, err := proto.Marshal(env)
+	if err != nil {
+		return errors.WithMessagef(xerror.Marshal, "envelope err:%v %v", err, xruntime.Location())
+	}
+	select {
+	case p.sendChan <- data:
+		return nil
+	default:
+		return errors.WithMessagef(xerror.ChannelFull, "remote link. link:%v %v", p.name, xruntime.Location())
+	}
+}
+
+// 接收
+func (p *link) recv() (*xremoteproto.Envelope, error) {
+	frame := &wrapperspb.BytesValue{}
+	if err := p.stream.RecvMsg(frame); err != nil {
+		return nil, err
+	}
+	env := &xremoteproto.Envelope{}
+	if err := proto.Unmarshal(frame.GetValue(), env); err != nil {
+		return nil, errors.WithMessagef(xerror.Unmarshal, "envelope err:%v %v", err, xruntime.Location())
+	}
+	return env, nil
+}
+
+// 关闭, 可重复调用
+func (p *link) close() {
+	p.once.Do(func() {
+		p.closed.Store(true)
+		close(p.doneChan)
+		if p.cancel != nil {
+			p.cancel()
+		}
+		if p.conn != nil {
+			if err := p.conn.Close(); err != nil {
+				xlog.PrintfErr("remote link close conn err. link:%v err:%v", p.name, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package remote
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	xerror "github.com/75912001/xlib/error"
+	xetcd "github.com/75912001/xlib/etcd"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// Node 服务标识, 与 etcd key 中的 groupID/serviceName/serviceID 对应
+type Node struct {
+	GroupID   uint32 // 组ID
+	Name      string // 服务名称
+	ServiceID uint32 // 服务ID
+}
+
+// NewNode 创建服务标识
+func NewNode(groupID uint32, name string, serviceID uint32) Node {
+	return Node{
+		GroupID:   groupID,
+		Name:      name,
+		ServiceID: serviceID,
+	}
+}
+
+func (p Node) String() string {
+	return fmt.Sprintf("%v.%v.%v", p.GroupID, p.Name, p.ServiceID)
+}
+
+// ParseNode 解析 Node.String 的结果
+func ParseNode(s string) (Node, error) {
+	first := strings.Index(s, ".")
+	last := strings.LastIndex(s, ".")
+	if first < 0 || last <= first {
+		return Node{}, errors.WithMessagef(xerror.Format, "node:%v %v", s, xruntime.Location())
+	}
+	groupID, err := strconv.ParseUint(s[:first], 10, 32)
+	if err != nil {
+		return Node{}, errors.WithMessagef(xerror.Format, "node:%v err:%v %v", s, err, xruntime.Location())
+	}
+	serviceID, err := strconv.ParseUint(s[last+1:], 10, 32)
+	if err != nil {
+		return Node{}, errors.WithMessagef(xerror.Format, "node:%v err:%v %v", s, err, xruntime.Location())
+	}
+	return NewNode(uint32(groupID), s[first+1:last], uint32(serviceID)), nil
+}
+
+// 通过 etcd.GRegistry 解析服务的 gRPC 地址
+func resolveAddr(node Node) (string, error) {
+	for _, value := range xetcd.GRegistry.FindByGroupNameID(node.GroupID, node.Name, node.ServiceID) {
+		if value.GrpcService != nil && value.GrpcService.Addr != nil && *value.GrpcService.Addr != "" {
+			return *value.GrpcService.Addr, nil
+		}
+	}
+	return "", errors.WithMessagef(xerror.NotExist, "remote node grpc addr. node:%v %v", node, xruntime.Location())
+}
@@ -0,0 +1,56 @@
+package remote
+
+import (
+	"time"
+)
+
+const (
+	requestTimeoutDefault   = 60 * time.Second // 请求响应的超时时长
+	sendChanCapacityDefault = 10000            // 链接发送通道容量
+)
+
+type Options struct {
+	requestTimeout   *time.Duration // [optional] 等待响应的超时时长, 超时后清除请求记录并响应 xerror.Timeout [default]:requestTimeoutDefault
+	sendChanCapacity *uint32        // [optional] 链接发送通道容量, 满时发送失败 [default]:sendChanCapacityDefault
+}
+
+// NewOptions 创建 Options
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func (p *Options) WithRequestTimeout(timeout time.Duration) *Options {
+	p.requestTimeout = &timeout
+	return p
+}
+
+func (p *Options) WithSendChanCapacity(capacity uint32) *Options {
+	p.sendChanCapacity = &capacity
+	return p
+}
+
+func mergeOptions(opts ...*Options) *Options {
+	so := NewOptions()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.requestTimeout != nil {
+			so.WithRequestTimeout(*opt.requestTimeout)
+		}
+		if opt.sendChanCapacity != nil {
+			so.WithSendChanCapacity(*opt.sendChanCapacity)
+		}
+	}
+	return so
+}
+
+// 配置
+func configure(opts *Options) {
+	if opts.requestTimeout == nil || *opts.requestTimeout <= 0 {
+		opts.WithRequestTimeout(requestTimeoutDefault)
+	}
+	if opts.sendChanCapacity == nil || *opts.sendChanCapacity == 0 {
+		opts.WithSendChanCapacity(sendChanCapacityDefault)
+	}
+}
@@ -0,0 +1,239 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v4.25.5
+// source: remote.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 信封-类型
+type EnvelopeKind int32
+
+const (
+	EnvelopeKind_EnvelopeKind_Unspecified EnvelopeKind = 0
+	EnvelopeKind_EnvelopeKind_Request     EnvelopeKind = 1 // 请求 [Send/Ask]
+	EnvelopeKind_EnvelopeKind_Response    EnvelopeKind = 2 // 响应
+)
+
+// Enum value maps for EnvelopeKind.
+var (
+	EnvelopeKind_name = map[int32]string{
+		0: "EnvelopeKind_Unspecified",
+		1: "EnvelopeKind_Request",
+		2: "EnvelopeKind_Response",
+	}
+	EnvelopeKind_value = map[string]int32{
+		"EnvelopeKind_Unspecified": 0,
+		"EnvelopeKind_Request":     1,
+		"EnvelopeKind_Response":    2,
+	}
+)
+
+func (x EnvelopeKind) Enum() *EnvelopeKind {
+	p := new(EnvelopeKind)
+	*p = x
+	return p
+}
+
+func (x EnvelopeKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EnvelopeKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_remote_proto_enumTypes[0].Descriptor()
+}
+
+func (EnvelopeKind) Type() protoreflect.EnumType {
+	return &file_remote_proto_enumTypes[0]
+}
+
+func (x EnvelopeKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EnvelopeKind.Descriptor instead.
+func (EnvelopeKind) EnumDescriptor() ([]byte, []int) {
+	return file_remote_proto_rawDescGZIP(), []int{0}
+}
+
+// 信封, 链接上传输的消息
+type Envelope struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          EnvelopeKind           `protobuf:"varint,1,opt,name=kind,proto3,enum=xlib.actor.remote.EnvelopeKind" json:"kind,omitempty"`
+	CorrelationID uint64                 `protobuf:"varint,2,opt,name=correlationID,proto3" json:"correlationID,omitempty"` // 关联ID [0: 无需响应]
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`                    // 目标 actor 路径 [请求]
+	Cmd           uint32                 `protobuf:"varint,4,opt,name=cmd,proto3" json:"cmd,omitempty"`                     // 消息命令 [请求]
+	Args          []*anypb.Any           `protobuf:"bytes,5,rep,name=args,proto3" json:"args,omitempty"`                    // 消息参数 [请求]
+	Resp          *anypb.Any             `protobuf:"bytes,6,opt,name=resp,proto3" json:"resp,omitempty"`                    // 响应数据 [响应]
+	Err           string                 `protobuf:"bytes,7,opt,name=err,proto3" json:"err,omitempty"`                      // 错误 [响应]
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_remote_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_remote_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Envelope) GetKind() EnvelopeKind {
+	if x != nil {
+		return x.Kind
+	}
+	return EnvelopeKind_EnvelopeKind_Unspecified
+}
+
+func (x *Envelope) GetCorrelationID() uint64 {
+	if x != nil {
+		return x.CorrelationID
+	}
+	return 0
+}
+
+func (x *Envelope) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Envelope) GetCmd() uint32 {
+	if x != nil {
+		return x.Cmd
+	}
+	return 0
+}
+
+func (x *Envelope) GetArgs() []*anypb.Any {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *Envelope) GetResp() *anypb.Any {
+	if x != nil {
+		return x.Resp
+	}
+	return nil
+}
+
+func (x *Envelope) GetErr() string {
+	if x != nil {
+		return x.Err
+	}
+	return ""
+}
+
+var File_remote_proto protoreflect.FileDescriptor
+
+const file_remote_proto_rawDesc = "" +
+	"\n" +
+	"\fremote.proto\x12\x11xlib.actor.remote\x1a\x19google/protobuf/any.proto\"\xf1\x01\n" +
+	"\bEnvelope\x123\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1f.xlib.actor.remote.EnvelopeKindR\x04kind\x12$\n" +
+	"\rcorrelationID\x18\x02 \x01(\x04R\rcorrelationID\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x10\n" +
+	"\x03cmd\x18\x04 \x01(\rR\x03cmd\x12(\n" +
+	"\x04args\x18\x05 \x03(\v2\x14.google.protobuf.AnyR\x04args\x12(\n" +
+	"\x04resp\x18\x06 \x01(\v2\x14.google.protobuf.AnyR\x04resp\x12\x10\n" +
+	"\x03err\x18\a \x01(\tR\x03err*a\n" +
+	"\fEnvelopeKind\x12\x1c\n" +
+	"\x18EnvelopeKind_Unspecified\x10\x00\x12\x18\n" +
+	"\x14EnvelopeKind_Request\x10\x01\x12\x19\n" +
+	"\x15EnvelopeKind_Response\x10\x02B3Z1github.com/75912001/xlib/actor/remote/proto;protob\x06proto3"
+
+var (
+	file_remote_proto_rawDescOnce sync.Once
+	file_remote_proto_rawDescData []byte
+)
+
+func file_remote_proto_rawDescGZIP() []byte {
+	file_remote_proto_rawDescOnce.Do(func() {
+		file_remote_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_remote_proto_rawDesc), len(file_remote_proto_rawDesc)))
+	})
+	return file_remote_proto_rawDescData
+}
+
+var file_remote_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_remote_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_remote_proto_goTypes = []any{
+	(EnvelopeKind)(0), // 0: xlib.actor.remote.EnvelopeKind
+	(*Envelope)(nil),  // 1: xlib.actor.remote.Envelope
+	(*anypb.Any)(nil), // 2: google.protobuf.Any
+}
+var file_remote_proto_depIdxs = []int32{
+	0, // 0: xlib.actor.remote.Envelope.kind:type_name -> xlib.actor.remote.EnvelopeKind
+	2, // 1: xlib.actor.remote.Envelope.args:type_name -> google.protobuf.Any
+	2, // 2: xlib.actor.remote.Envelope.resp:type_name -> google.protobuf.Any
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_remote_proto_init() }
+func file_remote_proto_init() {
+	if File_remote_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_remote_proto_rawDesc), len(file_remote_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_remote_proto_goTypes,
+		DependencyIndexes: file_remote_proto_depIdxs,
+		EnumInfos:         file_remote_proto_enumTypes,
+		MessageInfos:      file_remote_proto_msgTypes,
+	}.Build()
+	File_remote_proto = out.File
+	file_remote_proto_goTypes = nil
+	file_remote_proto_depIdxs = nil
+}
@@ -0,0 +1,80 @@
+package remote
+
+import (
+	xactor "github.com/75912001/xlib/actor"
+	xerror "github.com/75912001/xlib/error"
+	xlog "github.com/75912001/xlib/log"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// Ref actor 引用, 位置透明
+//
+//	实现 xactor.IActorRef, 可作为 Actor.Ask / xactor.AskFuture 的目标
+//	无法投递时: 同步/Ask 消息响应错误, 其它消息记录日志
+type Ref struct {
+	remote *Remote
+	node   Node   // actor 所在服务
+	path   string // actor 路径
+}
+
+// GetNode 获取 actor 所在服务
+func (p *Ref) GetNode() Node {
+	return p.node
+}
+
+func (p *Ref) GetPath() string {
+	return p.path
+}
+
+// IsLocal 是否-本服务的 actor
+func (p *Ref) IsLocal() bool {
+	return p.node == p.remote.node
+}
+
+// Send 发送消息 (异步)
+//
+//	只支持 *xactor.Msg
+func (p *Ref) Send(messages ...any) {
+	for _, message := range messages {
+		msg, ok := message.(*xactor.Msg)
+		if !ok {
+			p.remote.system.DeadLetter(p.path, message)
+			continue
+		}
+		_ = p.TrySendMsg(msg)
+	}
+}
+
+func (p *Ref) SendMsg(messages ...*xactor.Msg) {
+	for _, msg := range messages {
+		_ = p.TrySendMsg(msg)
+	}
+}
+
+// TrySendMsg 发送消息 (异步)
+//
+//	无法投递时返回错误, 同步/Ask 消息已响应错误
+func (p *Ref) TrySendMsg(messages ...*xactor.Msg) error {
+	var errs []error
+	for _, msg := range messages {
+		if err := p.remote.deliver(p.node, p.path, msg); err != nil {
+			err = errors.WithMessagef(err, "remote ref node:%v path:%v %v", p.node, p.path, xruntime.Location())
+			if !msg.NeedReply() && !p.IsLocal() {
+				xlog.PrintfErr("remote ref undeliverable. msg:%v err:%v", msg, err)
+			}
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		return errors.WithMessagef(xerror.Fail, "remote ref errs:%v %v", errs, xruntime.Location())
+	}
+	return nil
+}
+
+// SendMsgSync 发送消息, 并等待响应 (同步)
+//
+//	[❗] 禁止在 actor 的处理协程中调用, 请使用 Actor.Ask
+func (p *Ref) SendMsgSync(msg *xactor.Msg) (resp any, err error) {
+	return xactor.AskFuture(p, msg, 0).Result()
+}
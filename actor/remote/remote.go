@@ -0,0 +1,454 @@
+package remote
+
+import (
+	"context"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	xactor "github.com/75912001/xlib/actor"
+	xremoteproto "github.com/75912001/xlib/actor/remote/proto"
+	xerror "github.com/75912001/xlib/error"
+	xlog "github.com/75912001/xlib/log"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+)
+
+// GRemote 默认的远程 actor 管理器. server 启用 grpc 时创建
+var GRemote *Remote
+
+// 等待响应的请求
+type pending struct {
+	msg   *xactor.Msg
+	link  *link
+	timer *time.Timer
+}
+
+// Remote 远程 actor 管理器
+//
+//	出站: 按节点建立到对端内部 gRPC 服务的链接, 发送请求, 接收响应
+//	入站: 接收请求, 按路径投递到本地 actor 系统, 响应经由入站流返回
+//	消息参数与响应须为 proto.Message
+type Remote struct {
+	node    Node           // 本服务
+	system  *xactor.System // 本地 actor 系统
+	options *Options
+
+	linkMu  sync.Mutex
+	linkMap map[Node]*link // 出站链接
+
+	pendingMu     sync.Mutex
+	pendingMap    map[uint64]*pending // key: 关联ID
+	correlationID atomic.Uint64
+
+	prefixMu         sync.RWMutex
+	prefixHandlerMap map[string]PrefixHandler // key: 路径前缀
+
+	quitChan chan struct{}
+	quitOnce sync.Once
+}
+
+// PrefixHandler 路径前缀处理函数
+//
+//	本地 actor 系统中不存在 path 时调用, 用于按需创建 actor [e.g.: 虚拟 actor]
+//	返回错误时不响应 msg, 由调用方响应
+type PrefixHandler func(path string, msg *xactor.Msg) error
+
+// NewRemote 创建远程 actor 管理器
+//
+//	node: 本服务, system: 本地 actor 系统 [nil: xactor.GSystem]
+func NewRemote(node Node, system *xactor.System, opts ...*Options) *Remote {
+	if system == nil {
+		system = xactor.GSystem
+	}
+	opt := mergeOptions(opts...)
+	configure(opt)
+	return &Remote{
+		node:             node,
+		system:           system,
+		options:          opt,
+		linkMap:          make(map[Node]*link),
+		pendingMap:       make(map[uint64]*pending),
+		prefixHandlerMap: make(map[string]PrefixHandler),
+		quitChan:         make(chan struct{}),
+	}
+}
+
+// Register 注册内部 gRPC 服务, 须在 grpc.Server.Serve 之前调用
+func (p *Remote) Register(server *grpc.Server) {
+	server.RegisterService(&serviceDesc, p)
+}
+
+// GetNode 获取本服务
+func (p *Remote) GetNode() Node {
+	return p.node
+}
+
+// HandlePrefix 设置路径前缀处理函数, 同一前缀会被替换
+func (p *Remote) HandlePrefix(prefix string, handler PrefixHandler) {
+	p.prefixMu.Lock()
+	defer p.prefixMu.Unlock()
+	p.prefixHandlerMap[prefix] = handler
+}
+
+// UnhandlePrefix 移除路径前缀处理函数
+func (p *Remote) UnhandlePrefix(prefix string) {
+	p.prefixMu.Lock()
+	defer p.prefixMu.Unlock()
+	delete(p.prefixHandlerMap, prefix)
+}
+
+// ActorOf 获取 actor 引用
+//
+//	本服务的 actor 直接投递到本地 actor 系统, 其它服务的 actor 经由链接投递
+func (p *Remote) ActorOf(node Node, path string) xactor.IActorRef {
+	return &Ref{
+		remote: p,
+		node:   node,
+		path:   path,
+	}
+}
+
+// Stop 停止
+//
+//	关闭所有链接, 等待中的请求响应 xerror.Unavailable. 须在 grpc.Server.Stop 之前调用, 否则入站流会阻塞 GracefulStop
+func (p *Remote) Stop() {
+	p.quitOnce.Do(func() {
+		close(p.quitChan)
+	})
+	p.linkMu.Lock()
+	links := p.linkMap
+	p.linkMap = make(map[Node]*link)
+	p.linkMu.Unlock()
+	for _, l := range links {
+		l.close()
+	}
+	p.failPending(nil, errors.WithMessagef(xerror.Unavailable, "remote stopped. %v", xruntime.Location()))
+}
+
+func (p *Remote) isStopped() bool {
+	select {
+	case <-p.quitChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// 投递
+//
+//	失败时返回错误, 同步/Ask 消息已响应错误
+func (p *Remote) deliver(node Node, path string, msg *xactor.Msg) error {
+	if node == p.node { // 本服务
+		return p.deliverLocal(path, msg)
+	}
+	if p.isStopped() {
+		err := errors.WithMessagef(xerror.Unavailable, "remote stopped. %v", xruntime.Location())
+		msg.Reply(nil, err)
+		return err
+	}
+	env := &xremoteproto.Envelope{
+		Kind: xremoteproto.EnvelopeKind_EnvelopeKind_Request,
+		Path: path,
+		Cmd:  uint32(msg.Cmd),
+	}
+	for _, arg := range msg.Args {
+		a, err := toAny(arg)
+		if err != nil {
+			err = errors.WithMessagef(err, "node:%v path:%v cmd:%v %v", node, path, msg.Cmd, xruntime.Location())
+			msg.Reply(nil, err)
+			return err
+		}
+		env.Args = append(env.Args, a)
+	}
+	l, err := p.getLink(node)
+	if err != nil {
+		err = errors.WithMessagef(err, "node:%v path:%v %v", node, path, xruntime.Location())
+		msg.Reply(nil, err)
+		return err
+	}
+	if msg.NeedReply() {
+		env.CorrelationID = p.addPending(msg, l)
+	}
+	if err = l.send(env); err != nil {
+		err = errors.WithMessagef(err, "node:%v path:%v %v", node, path, xruntime.Location())
+		if env.CorrelationID == 0 || p.takePending(env.CorrelationID) != nil { // 未超时, 未响应
+			msg.Reply(nil, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// 投递到本地 actor 系统
+//
+//	失败时返回错误, 同步/Ask 消息已响应错误
+func (p *Remote) deliverLocal(path string, msg *xactor.Msg) error {
+	if ref, ok := p.system.Lookup(path); ok {
+		return ref.TrySendMsg(msg)
+	}
+	var err error
+	if handler := p.findPrefixHandler(path); handler != nil {
+		err = handler(path, msg)
+	} else {
+		p.system.DeadLetter(path, msg)
+		err = errors.WithMessagef(xerror.NotExist, "actor path:%v %v", path, xruntime.Location())
+	}
+	if err != nil {
+		msg.Reply(nil, err)
+	}
+	return err
+}
+
+// 查找前缀匹配的处理函数, 最长前缀优先
+func (p *Remote) findPrefixHandler(path string) PrefixHandler {
+	p.prefixMu.RLock()
+	defer p.prefixMu.RUnlock()
+	var handler PrefixHandler
+	var matched int
+	for prefix, h := range p.prefixHandlerMap {
+		if matched < len(prefix) && strings.HasPrefix(path, prefix) {
+			handler, matched = h, len(prefix)
+		}
+	}
+	return handler
+}
+
+// 获取出站链接, 不存在时建立
+func (p *Remote) getLink(node Node) (*link, error) {
+	p.linkMu.Lock()
+	l, ok := p.linkMap[node]
+	p.linkMu.Unlock()
+	if ok && !l.closed.Load() {
+		return l, nil
+	}
+	addr, err := resolveAddr(node)
+	if err != nil {
+		return nil, errors.WithMessage(err, xruntime.Location())
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, errors.WithMessagef(xerror.Link, "remote dial addr:%v err:%v %v", addr, err, xruntime.Location())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := conn.NewStream(ctx, &linkStreamDesc, linkMethodName)
+	if err != nil {
+		cancel()
+		_ = conn.Close()
+		return nil, errors.WithMessagef(xerror.Link, "remote stream addr:%v err:%v %v", addr, err, xruntime.Location())
+	}
+	l = newLink(node.String(), stream, conn, cancel, *p.options.sendChanCapacity)
+	p.linkMu.Lock()
+	if exist, ok := p.linkMap[node]; ok && !exist.closed.Load() { // 并发建立, 使用先建立的
+		p.linkMu.Unlock()
+		l.close()
+		return exist, nil
+	}
+	if p.isStopped() {
+		p.linkMu.Unlock()
+		l.close()
+		return nil, errors.WithMessagef(xerror.Unavailable, "remote stopped. %v", xruntime.Location())
+	}
+	p.linkMap[node] = l
+	p.linkMu.Unlock()
+	l.start()
+	go p.recvResponse(node, l)
+	return l, nil
+}
+
+// 出站链接, 接收响应. 断开时, 经由该链接等待中的请求响应 xerror.Link
+func (p *Remote) recvResponse(node Node, l *link) {
+	defer func() {
+		if xruntime.IsRelease() {
+			if err := recover(); err != nil {
+				xlog.PrintErr(xerror.GoroutinePanic, err, debug.Stack())
+			}
+		}
+		l.close()
+		p.linkMu.Lock()
+		if current, ok := p.linkMap[node]; ok && current == l {
+			delete(p.linkMap, node)
+		}
+		p.linkMu.Unlock()
+		p.failPending(l, errors.WithMessagef(xerror.Link, "remote link broken. node:%v %v", node, xruntime.Location()))
+		xlog.PrintInfo(xerror.GoroutineDone, node)
+	}()
+	for {
+		env, err := l.recv()
+		if err != nil {
+			if !l.closed.Load() {
+				xlog.PrintfErr("remote link recv err. node:%v err:%v", node, err)
+			}
+			return
+		}
+		if env.GetKind() != xremoteproto.EnvelopeKind_EnvelopeKind_Response {
+			xlog.PrintfErr("remote link unexpected envelope kind:%v node:%v", env.GetKind(), node)
+			continue
+		}
+		p.onResponse(env)
+	}
+}
+
+func (p *Remote) onResponse(env *xremoteproto.Envelope) {
+	pend := p.takePending(env.GetCorrelationID())
+	if pend == nil { // 已超时
+		return
+	}
+	var resp any
+	var err error
+	if env.GetResp() != nil {
+		resp, err = fromAny(env.GetResp())
+	}
+	if env.GetErr() != "" {
+		err = errors.WithMessagef(xerror.Fail, "remote err:%v", env.GetErr())
+	}
+	pend.msg.Reply(resp, err)
+}
+
+// 入站流
+func (p *Remote) serve(stream grpc.ServerStream) error {
+	name := "inbound"
+	if pr, ok := peer.FromContext(stream.Context()); ok {
+		name = pr.Addr.String()
+	}
+	l := newLink(name, stream, nil, nil, *p.options.sendChanCapacity)
+	l.start()
+	defer l.close()
+	recvDone := make(chan struct{})
+	go func() {
+		defer func() {
+			if xruntime.IsRelease() {
+				if err := recover(); err != nil {
+					xlog.PrintErr(xerror.GoroutinePanic, err, debug.Stack())
+				}
+			}
+			close(recvDone)
+		}()
+		for {
+			env, err := l.recv()
+			if err != nil {
+				return
+			}
+			if env.GetKind() != xremoteproto.EnvelopeKind_EnvelopeKind_Request {
+				xlog.PrintfErr("remote link unexpected envelope kind:%v link:%v", env.GetKind(), name)
+				continue
+			}
+			p.onRequest(l, env)
+		}
+	}()
+	select { // 返回后流被取消, 接收协程随之退出
+	case <-recvDone:
+	case <-p.quitChan:
+	case <-l.doneChan:
+	}
+	return nil
+}
+
+func (p *Remote) onRequest(l *link, env *xremoteproto.Envelope) {
+	args := make([]any, 0, len(env.GetArgs()))
+	var err error
+	for _, a := range env.GetArgs() {
+		var arg any
+		if arg, err = fromAny(a); err != nil {
+			break
+		}
+		args = append(args, arg)
+	}
+	msg := xactor.NewMsg(context.Background(), xactor.CMD(env.GetCmd()), args...)
+	if env.GetCorrelationID() != 0 {
+		var once sync.Once
+		correlationID := env.GetCorrelationID()
+		msg.WithReplyFunc(func(resp any, err error) {
+			once.Do(func() {
+				p.respond(l, correlationID, resp, err)
+			})
+		})
+	}
+	if err != nil {
+		msg.Reply(nil, errors.WithMessagef(err, "path:%v %v", env.GetPath(), xruntime.Location()))
+		return
+	}
+	_ = p.deliverLocal(env.GetPath(), msg) // 失败时已响应
+}
+
+// 响应
+func (p *Remote) respond(l *link, correlationID uint64, resp any, err error) {
+	env := &xremoteproto.Envelope{
+		Kind:          xremoteproto.EnvelopeKind_EnvelopeKind_Response,
+		CorrelationID: correlationID,
+	}
+	if resp != nil && err == nil {
+		var errAny error
+		if env.Resp, errAny = toAny(resp); errAny != nil {
+			err = errAny
+		}
+	}
+	if err != nil {
+		env.Err = strings.ToValidUTF8(err.Error(), "\uFFFD")
+	}
+	if errSend := l.send(env); errSend != nil {
+		xlog.PrintfErr("remote respond err. link:%v correlationID:%v err:%v", l.name, correlationID, errSend)
+	}
+}
+
+// 记录等待响应的请求, 超时后响应 xerror.Timeout
+func (p *Remote) addPending(msg *xactor.Msg, l *link) uint64 {
+	correlationID := p.correlationID.Add(1)
+	pend := &pending{
+		msg:  msg,
+		link: l,
+	}
+	p.pendingMu.Lock()
+	p.pendingMap[correlationID] = pend
+	p.pendingMu.Unlock()
+	pend.timer = time.AfterFunc(*p.options.requestTimeout, func() {
+		if p.takePending(correlationID) != nil {
+			msg.Reply(nil, errors.WithMessagef(xerror.Timeout, "remote request correlationID:%v %v", correlationID, xruntime.Location()))
+		}
+	})
+	return correlationID
+}
+
+// 取出等待响应的请求
+func (p *Remote) takePending(correlationID uint64) *pending {
+	if correlationID == 0 {
+		return nil
+	}
+	p.pendingMu.Lock()
+	pend, ok := p.pendingMap[correlationID]
+	if ok {
+		delete(p.pendingMap, correlationID)
+	}
+	p.pendingMu.Unlock()
+	if !ok {
+		return nil
+	}
+	if pend.timer != nil {
+		pend.timer.Stop()
+	}
+	return pend
+}
+
+// 响应错误. l: 只处理经由该链接的请求 [nil: 所有]
+func (p *Remote) failPending(l *link, err error) {
+	var failed []*pending
+	p.pendingMu.Lock()
+	for correlationID, pend := range p.pendingMap {
+		if l == nil || pend.link == l {
+			delete(p.pendingMap, correlationID)
+			failed = append(failed, pend)
+		}
+	}
+	p.pendingMu.Unlock()
+	for _, pend := range failed {
+		if pend.timer != nil {
+			pend.timer.Stop()
+		}
+		pend.msg.Reply(nil, err)
+	}
+}
@@ -0,0 +1,37 @@
+package remote
+
+import (
+	"google.golang.org/grpc"
+)
+
+// 内部 gRPC 服务, 双向流. 每个方向使用各自的出站链接: 请求 客户端->服务端, 响应 服务端->客户端
+//
+//	帧为 wrapperspb.BytesValue, 内容为 xremoteproto.Envelope
+const (
+	serviceName    = "xlib.actor.Remote"
+	linkStreamName = "Link"
+	linkMethodName = "/" + serviceName + "/" + linkStreamName
+)
+
+var linkStreamDesc = grpc.StreamDesc{
+	StreamName:    linkStreamName,
+	ServerStreams: true,
+	ClientStreams: true,
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*any)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    linkStreamName,
+			Handler:       linkHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+}
+
+func linkHandler(srv any, stream grpc.ServerStream) error {
+	return srv.(*Remote).serve(stream)
+}
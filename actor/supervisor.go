@@ -0,0 +1,156 @@
+package actor
+
+import (
+	"context"
+	"time"
+
+	xlog "github.com/75912001/xlib/log"
+)
+
+// StrategyType 监督策略-类型
+type StrategyType uint32
+
+const (
+	StrategyOneForOne StrategyType = 0 // 只重启失败的子 actor
+	StrategyAllForOne StrategyType = 1 // 重启所有子 actor
+	StrategyEscalate  StrategyType = 2 // 上报, 父 actor 自身视为失败, 交由祖父 actor 处理. 根 actor 按 StrategyOneForOne 处理
+)
+
+// SupervisorStrategy 监督策略
+//
+//	子 actor 失败: 处理消息时 panic, 或 ILifecycle.PreStart 返回错误. 行为函数返回的 error 只响应给调用方, 不视为失败
+//	在 Within 时间窗口内, 某子 actor 重启次数超过 MaxRestarts, 则停止该子 actor(StrategyAllForOne 时停止所有子 actor)
+//	重启退避、时间窗口使用 xtimer.GTimer 的时钟, 未设置 GTimer 时使用系统时间
+type SupervisorStrategy struct {
+	Type        StrategyType  // 策略类型
+	MaxRestarts uint32        // 时间窗口内最大重启次数. 0:不限制 [default]: 10
+	Within      time.Duration // 时间窗口 [default]: 1 分钟
+	Backoff     time.Duration // 重启退避-初始时长, 连续失败时翻倍. 0:立即重启 [default]: 0
+	MaxBackoff  time.Duration // 重启退避-最大时长 [default]: 10 秒
+}
+
+// NewSupervisorStrategy 创建监督策略
+func NewSupervisorStrategy(strategyType StrategyType) *SupervisorStrategy {
+	return &SupervisorStrategy{
+		Type:        strategyType,
+		MaxRestarts: 10,
+		Within:      time.Minute,
+		Backoff:     0,
+		MaxBackoff:  10 * time.Second,
+	}
+}
+
+func (p *SupervisorStrategy) WithMaxRestarts(maxRestarts uint32, within time.Duration) *SupervisorStrategy {
+	p.MaxRestarts = maxRestarts
+	p.Within = within
+	return p
+}
+
+func (p *SupervisorStrategy) WithBackoff(backoff time.Duration, maxBackoff time.Duration) *SupervisorStrategy {
+	p.Backoff = backoff
+	p.MaxBackoff = maxBackoff
+	return p
+}
+
+// 重启-退避时长
+//
+//	restartCnt: 时间窗口内的重启次数(含本次) 1,2,3...
+func (p *SupervisorStrategy) backoff(restartCnt int) time.Duration {
+	if p.Backoff <= 0 || restartCnt <= 0 {
+		return 0
+	}
+	d := p.Backoff
+	for i := 1; i < restartCnt && i < 32 && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d <<= 1
+	}
+	if 0 < p.MaxBackoff && p.MaxBackoff < d {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+// 记录一次重启, 返回时间窗口内的重启次数
+func (p *SupervisorStrategy) record(history []time.Time, now time.Time) ([]time.Time, int) {
+	if 0 < p.Within {
+		idx := 0
+		for ; idx < len(history); idx++ {
+			if now.Sub(history[idx]) <= p.Within {
+				break
+			}
+		}
+		history = history[idx:]
+	}
+	history = append(history, now)
+	return history, len(history)
+}
+
+// 是否超过重启次数限制
+func (p *SupervisorStrategy) isExceeded(restartCnt int) bool {
+	return 0 < p.MaxRestarts && int(p.MaxRestarts) < restartCnt
+}
+
+// fail 当前 actor 失败 (panic, PreStart 返回错误)
+//
+//	挂起当前 actor(之后的用户消息暂存), 并通知父 actor 处理. 根 actor 仅记录日志, 保持原行为
+func (p *Actor[KEY]) fail(reason error) {
+	if p.parent == nil {
+		xlog.PrintfErr("root actor failure key:%v reason:%v", p.key, reason)
+		return
+	}
+	p.suspended = true
+	p.parent.SendMsg(NewMsg(context.Background(), SystemReservedCommand_Failure, p.key, reason))
+}
+
+// handleFailure 父 actor 处理子 actor 的失败
+func (p *Actor[KEY]) handleFailure(childKey KEY, reason error) {
+	child, ok := p.childMgr.Find(childKey)
+	if !ok { // 子 actor 已被移除
+		return
+	}
+	strategy := p.options.supervisorStrategy
+	switch strategy.Type {
+	case StrategyEscalate:
+		if p.parent != nil {
+			xlog.PrintfErr("actor escalate key:%v child:%v reason:%v", p.key, childKey, reason)
+			p.fail(reason)
+			return
+		}
+		p.supervise(strategy, childKey, reason, map[KEY]*Actor[KEY]{childKey: child})
+	case StrategyAllForOne:
+		children := make(map[KEY]*Actor[KEY], p.childMgr.Len())
+		p.childMgr.Foreach(func(key KEY, value *Actor[KEY]) bool {
+			children[key] = value
+			return true
+		})
+		p.supervise(strategy, childKey, reason, children)
+	default:
+		p.supervise(strategy, childKey, reason, map[KEY]*Actor[KEY]{childKey: child})
+	}
+}
+
+// supervise 按策略重启或停止 targets
+func (p *Actor[KEY]) supervise(strategy *SupervisorStrategy, childKey KEY, reason error, targets map[KEY]*Actor[KEY]) {
+	var restartCnt int
+	p.restartHistory[childKey], restartCnt = strategy.record(p.restartHistory[childKey], now())
+	if strategy.isExceeded(restartCnt) { // 超过重启次数限制, 停止
+		xlog.PrintfErr("actor key:%v child:%v restart %v times within %v, stop. reason:%v",
+			p.key, childKey, restartCnt-1, strategy.Within, reason)
+		for key, target := range targets {
+			target.SendMsg(NewMsg(context.Background(), SystemReservedCommand_Stop))
+			p.childMgr.Del(key)
+			delete(p.restartHistory, key)
+		}
+		return
+	}
+	backoff := strategy.backoff(restartCnt)
+	xlog.PrintfErr("actor key:%v restart child:%v count:%v backoff:%v reason:%v",
+		p.key, childKey, restartCnt, backoff, reason)
+	for _, target := range targets {
+		restartMsg := NewMsg(context.Background(), SystemReservedCommand_Restart, reason)
+		if backoff <= 0 {
+			target.SendMsg(restartMsg)
+			continue
+		}
+		target.sendAfter(backoff, restartMsg)
+	}
+}
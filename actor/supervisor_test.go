@@ -0,0 +1,158 @@
+package actor
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	xclock "github.com/75912001/xlib/clock"
+	xerror "github.com/75912001/xlib/error"
+	xlog "github.com/75912001/xlib/log"
+	xtimer "github.com/75912001/xlib/timer"
+	"github.com/pkg/errors"
+)
+
+const (
+	testCmdPanic CMD = 10 // 处理时 panic
+	testCmdError CMD = 11 // 处理时返回错误
+	testCmdWork1 CMD = 21 // 记录
+	testCmdWork2 CMD = 22 // 记录
+	testCmdWork3 CMD = 23 // 记录
+)
+
+func TestMain(m *testing.M) {
+	// 邮箱处理返回错误时, 通过 xlog.GLog 记录
+	logger, err := xlog.NewMgr(xlog.NewOptions().WithIsWriteFile(false))
+	if err != nil {
+		panic(err)
+	}
+	xlog.GLog = logger
+	code := m.Run()
+	_ = logger.Stop()
+	os.Exit(code)
+}
+
+// 子 actor 的工厂. created: 创建次数, got: 已处理的记录消息. 均只在子 actor 的处理协程中修改
+type testChild struct {
+	created int
+	got     []CMD
+}
+
+func (p *testChild) factory() (Behavior, ILifecycle) {
+	p.created++
+	var behavior Behavior
+	behavior = func(messages ...any) (Behavior, any, error) {
+		msg := messages[0].(*Msg)
+		switch msg.Cmd {
+		case testCmdPanic:
+			panic("test panic")
+		case testCmdError:
+			return behavior, nil, errors.WithMessage(xerror.Param, "test error")
+		case testCmdBarrier:
+			return behavior, slices.Clone(p.got), nil
+		}
+		p.got = append(p.got, msg.Cmd)
+		return behavior, nil, nil
+	}
+	return behavior, nil
+}
+
+func newTestParent(t *testing.T, strategy *SupervisorStrategy, child *testChild, childOptions *Options) (*Actor[uint64], *Actor[uint64]) {
+	parent := NewActorWithOptions(uint64(1), nil, NewOptions().
+		WithName("supervisor.test").
+		WithSystem(NewSystem()).
+		WithSupervisorStrategy(strategy).
+		WithFactory(BehaviorFactory(EmptyBehavior)))
+	parent.Start()
+	resp, err := parent.SendMsgSync(NewMsg(context.Background(), SystemReservedCommand_Spawn, uint64(2), Factory(child.factory), childOptions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parent, resp.(*Actor[uint64])
+}
+
+// 等待 actor 已处理之前的消息. 系统消息, 挂起时也会处理
+func systemBarrier(actor *Actor[uint64]) {
+	_, _ = actor.SendMsgSync(NewMsg(context.Background(), SystemReservedCommand_GetChild, uint64(0)))
+}
+
+func TestActorRestartReplayStash(t *testing.T) {
+	child := &testChild{}
+	parent, actor := newTestParent(t, NewSupervisorStrategy(StrategyOneForOne), child, nil)
+	defer func() { _, _ = parent.SendMsgSync(NewMsg(context.Background(), SystemReservedCommand_Stop)) }()
+
+	// 返回的错误不视为失败, 不重启
+	if _, err := actor.SendMsgSync(NewMsg(context.Background(), testCmdError)); !errors.Is(err, xerror.Param) {
+		t.Fatalf("err:%v", err)
+	}
+	// panic 后挂起, 之后的消息暂存. 重启后重新入队, 处理中再次 panic 时, 剩余的消息等待下一次重启
+	actor.SendMsg(
+		NewMsg(context.Background(), testCmdPanic),
+		NewMsg(context.Background(), testCmdWork1),
+		NewMsg(context.Background(), testCmdPanic),
+		NewMsg(context.Background(), testCmdWork2),
+	)
+	resp, err := actor.SendMsgSync(NewMsg(context.Background(), testCmdBarrier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.([]CMD); !slices.Equal(got, []CMD{testCmdWork1, testCmdWork2}) {
+		t.Fatalf("got:%v", got)
+	}
+	if child.created != 3 {
+		t.Fatalf("created:%v want:3", child.created)
+	}
+}
+
+func TestActorRestartBackoffStashCapacity(t *testing.T) {
+	clock := xclock.NewVirtual(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	timer := xtimer.NewTimerWithClock(clock)
+	if err := timer.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer timer.Stop()
+	old := xtimer.GTimer
+	xtimer.GTimer = timer
+	defer func() { xtimer.GTimer = old }()
+
+	child := &testChild{}
+	strategy := NewSupervisorStrategy(StrategyOneForOne).WithBackoff(time.Second, 10*time.Second)
+	parent, actor := newTestParent(t, strategy, child, NewOptions().WithStashCapacity(2))
+	defer func() { _, _ = parent.SendMsgSync(NewMsg(context.Background(), SystemReservedCommand_Stop)) }()
+
+	actor.SendMsg(
+		NewMsg(context.Background(), testCmdPanic),
+		NewMsg(context.Background(), testCmdWork1),
+		NewMsg(context.Background(), testCmdWork2),
+	)
+	// 暂存已满, 投递到死信
+	if _, err := actor.SendMsgSync(NewMsg(context.Background(), testCmdWork3)); !errors.Is(err, xerror.ChannelFull) {
+		t.Fatalf("err:%v", err)
+	}
+	if cnt := parent.GetSystem().GetDeadLetterCount(); cnt != 1 {
+		t.Fatalf("dead letter:%v want:1", cnt)
+	}
+	systemBarrier(parent) // 父 actor 已处理失败, 安排重启
+
+	// 退避按虚拟时钟
+	clock.Advance(999 * time.Millisecond)
+	systemBarrier(actor)
+	if child.created != 1 || len(child.got) != 0 {
+		t.Fatalf("created:%v got:%v before backoff", child.created, child.got)
+	}
+	clock.Advance(time.Millisecond)
+	systemBarrier(actor) // 到期, 投递重启
+	systemBarrier(actor) // 重启
+	resp, err := actor.SendMsgSync(NewMsg(context.Background(), testCmdBarrier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.([]CMD); !slices.Equal(got, []CMD{testCmdWork1, testCmdWork2}) {
+		t.Fatalf("got:%v", got)
+	}
+	if child.created != 2 {
+		t.Fatalf("created:%v want:2", child.created)
+	}
+}
@@ -0,0 +1,208 @@
+package actor
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	xcontrol "github.com/75912001/xlib/control"
+	xerror "github.com/75912001/xlib/error"
+	xlog "github.com/75912001/xlib/log"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// PathSeparator 路径分隔符. e.g.: /server/scene/42
+const PathSeparator = "/"
+
+// GSystem 默认的 actor 系统. 未指定 System 的根 actor 注册到该系统中, 子 actor 继承父 actor 的系统
+var GSystem = NewSystem()
+
+// Terminated 被监视的 actor 已停止, 投递到监视者的邮箱中
+type Terminated struct {
+	Path string // 已停止的 actor 路径
+}
+
+// DeadLetter 死信. 发送给已停止(或不存在)的 actor 的消息
+type DeadLetter struct {
+	Path    string    // 目标 actor 路径
+	Message any       // 消息
+	Time    time.Time // 时间
+}
+
+// System actor 系统
+//
+//	按层级路径记录所有存活的 actor, 提供查找, 监视 与 死信收集
+//	并发安全
+type System struct {
+	mu          sync.RWMutex
+	actorMap    map[string]IActorRef            // key: 路径
+	watcherMap  map[string]map[string]IActorRef // 监视 key: 被监视者路径, value: {key: 监视者路径}
+	watchingMap map[string]map[string]struct{}  // 监视(反向) key: 监视者路径, value: {被监视者路径}
+
+	deadLetterSink  atomic.Pointer[deadLetterSink] // 死信输出
+	deadLetterCount atomic.Uint64                  // 死信数量
+}
+
+type deadLetterSink struct {
+	out xcontrol.IOut
+}
+
+func NewSystem() *System {
+	return &System{
+		actorMap:    make(map[string]IActorRef),
+		watcherMap:  make(map[string]map[string]IActorRef),
+		watchingMap: make(map[string]map[string]struct{}),
+	}
+}
+
+// SetDeadLetterSink 设置死信输出
+//
+//	死信以 *DeadLetter 的形式发送到 out. nil: 只记录日志 [default]
+func (p *System) SetDeadLetterSink(out xcontrol.IOut) {
+	if out == nil {
+		p.deadLetterSink.Store(nil)
+		return
+	}
+	p.deadLetterSink.Store(&deadLetterSink{out: out})
+}
+
+// GetDeadLetterCount 死信数量
+func (p *System) GetDeadLetterCount() uint64 {
+	return p.deadLetterCount.Load()
+}
+
+// Lookup 按路径查找存活的 actor
+func (p *System) Lookup(path string) (IActorRef, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	ref, ok := p.actorMap[path]
+	return ref, ok
+}
+
+// Len 存活的 actor 数量
+func (p *System) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.actorMap)
+}
+
+// Foreach 遍历所有存活的 actor
+//
+//	[⚠️] f 中禁止调用 System 的方法
+func (p *System) Foreach(f func(path string, ref IActorRef) (isContinue bool)) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for path, ref := range p.actorMap {
+		if !f(path, ref) {
+			break
+		}
+	}
+}
+
+// Watch watcher 监视 path
+//
+//	path 停止时, watcher 收到 *Terminated. path 不存在时, 立即收到 *Terminated
+func (p *System) Watch(watcher IActorRef, path string) {
+	p.mu.Lock()
+	if _, ok := p.actorMap[path]; !ok {
+		p.mu.Unlock()
+		watcher.Send(&Terminated{Path: path})
+		return
+	}
+	watcherPath := watcher.GetPath()
+	watchers, ok := p.watcherMap[path]
+	if !ok {
+		watchers = make(map[string]IActorRef)
+		p.watcherMap[path] = watchers
+	}
+	watchers[watcherPath] = watcher
+	watching, ok := p.watchingMap[watcherPath]
+	if !ok {
+		watching = make(map[string]struct{})
+		p.watchingMap[watcherPath] = watching
+	}
+	watching[path] = struct{}{}
+	p.mu.Unlock()
+}
+
+// Unwatch watcher 取消监视 path
+func (p *System) Unwatch(watcher IActorRef, path string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	watcherPath := watcher.GetPath()
+	if watchers, ok := p.watcherMap[path]; ok {
+		delete(watchers, watcherPath)
+		if len(watchers) == 0 {
+			delete(p.watcherMap, path)
+		}
+	}
+	if watching, ok := p.watchingMap[watcherPath]; ok {
+		delete(watching, path)
+		if len(watching) == 0 {
+			delete(p.watchingMap, watcherPath)
+		}
+	}
+}
+
+// DeadLetter 投递死信
+func (p *System) DeadLetter(path string, message any) {
+	p.deadLetterCount.Add(1)
+	deadLetter := &DeadLetter{
+		Path:    path,
+		Message: message,
+		Time:    time.Now(),
+	}
+	if sink := p.deadLetterSink.Load(); sink != nil {
+		sink.out.Send(deadLetter)
+		return
+	}
+	xlog.PrintfInfo("actor dead letter path:%v message:%+v", path, message)
+}
+
+// 注册
+func (p *System) register(ref IActorRef) error {
+	path := ref.GetPath()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.actorMap[path]; ok {
+		return errors.WithMessagef(xerror.Exist, "actor path:%v %v", path, xruntime.Location())
+	}
+	p.actorMap[path] = ref
+	return nil
+}
+
+// 注销, 并通知监视者
+func (p *System) unregister(ref IActorRef) {
+	path := ref.GetPath()
+	p.mu.Lock()
+	if current, ok := p.actorMap[path]; !ok || current != ref {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.actorMap, path)
+	watchers := p.watcherMap[path]
+	delete(p.watcherMap, path)
+	for watcherPath := range watchers {
+		if watching, ok := p.watchingMap[watcherPath]; ok {
+			delete(watching, path)
+			if len(watching) == 0 {
+				delete(p.watchingMap, watcherPath)
+			}
+		}
+	}
+	// 自身作为监视者的记录
+	for targetPath := range p.watchingMap[path] {
+		if targetWatchers, ok := p.watcherMap[targetPath]; ok {
+			delete(targetWatchers, path)
+			if len(targetWatchers) == 0 {
+				delete(p.watcherMap, targetPath)
+			}
+		}
+	}
+	delete(p.watchingMap, path)
+	p.mu.Unlock()
+	for _, watcher := range watchers {
+		watcher.Send(&Terminated{Path: path})
+	}
+}
@@ -0,0 +1,178 @@
+package actor
+
+import (
+	"context"
+	"time"
+
+	xcontrol "github.com/75912001/xlib/control"
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	xtimer "github.com/75912001/xlib/timer"
+	"github.com/pkg/errors"
+)
+
+// actor 定时器
+type actorTimer struct {
+	name        string
+	msg         *Msg          // 到期时处理的消息
+	interval    time.Duration // 重复间隔. 0:只执行一次
+	expire      int64         // 到期时间戳-毫秒
+	millisecond *xtimer.Millisecond
+}
+
+// 定时器到期-输出
+//
+//	xtimer 到期时在定时器协程中调用, 转为系统保留命令消息投递到 actor 邮箱, 在 actor 的处理协程中处理
+type timerOut[KEY comparable] struct {
+	actor *Actor[KEY]
+}
+
+func (p *timerOut[KEY]) Send(events ...any) {
+	for _, event := range events {
+		p.actor.SendMsg(NewMsg(context.Background(), SystemReservedCommand_Timer, event))
+	}
+}
+
+// ScheduleOnce 延迟 delay 后, 处理一次 msg
+//
+//	[⚠️] 须在该 actor 的处理协程中调用. 同名定时器会被替换
+//	msg 按邮箱顺序, 由该 actor 的行为函数处理
+func (p *Actor[KEY]) ScheduleOnce(name string, delay time.Duration, msg *Msg) error {
+	return p.schedule(name, delay, 0, msg)
+}
+
+// ScheduleRepeat 延迟 delay 后, 每隔 interval 处理一次 msg, 直到 Cancel 或 actor 停止
+//
+//	[⚠️] 须在该 actor 的处理协程中调用. 同名定时器会被替换
+func (p *Actor[KEY]) ScheduleRepeat(name string, delay time.Duration, interval time.Duration, msg *Msg) error {
+	if interval <= 0 {
+		return errors.WithMessagef(xerror.Param, "interval:%v %v", interval, xruntime.Location())
+	}
+	return p.schedule(name, delay, interval, msg)
+}
+
+// Cancel 取消定时器
+//
+//	[⚠️] 须在该 actor 的处理协程中调用
+//	返回: 是否存在该定时器
+func (p *Actor[KEY]) Cancel(name string) bool {
+	t, ok := p.timerMap[name]
+	if !ok {
+		return false
+	}
+	t.millisecond.Delete()
+	delete(p.timerMap, name)
+	return true
+}
+
+// IsScheduled 定时器是否存在
+//
+//	[⚠️] 须在该 actor 的处理协程中调用
+func (p *Actor[KEY]) IsScheduled(name string) bool {
+	_, ok := p.timerMap[name]
+	return ok
+}
+
+// 取消所有定时器
+func (p *Actor[KEY]) cancelAllTimers() {
+	for _, t := range p.timerMap {
+		t.millisecond.Delete()
+	}
+	clear(p.timerMap)
+}
+
+func (p *Actor[KEY]) schedule(name string, delay time.Duration, interval time.Duration, msg *Msg) error {
+	if xtimer.GTimer == nil {
+		return errors.WithMessagef(xerror.Nil, "GTimer is nil. %v", xruntime.Location())
+	}
+	if msg == nil || isSystemReservedCommand(msg.Cmd) {
+		return errors.WithMessagef(xerror.Param, "msg:%v %v", msg, xruntime.Location())
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	p.Cancel(name)
+	t := &actorTimer{
+		name:     name,
+		msg:      msg,
+		interval: interval,
+		expire:   xtimer.GTimer.GetClock().Now().UnixMilli() + delay.Milliseconds(),
+	}
+	p.arm(t)
+	p.timerMap[name] = t
+	return nil
+}
+
+func (p *Actor[KEY]) arm(t *actorTimer) {
+	t.millisecond = xtimer.GTimer.AddMillisecond(
+		xcontrol.NewCallBack(p.onTimer, t),
+		t.expire,
+		&timerOut[KEY]{actor: p},
+	)
+}
+
+// onTimer 定时器到期 [actor 的处理协程中]
+func (p *Actor[KEY]) onTimer(args ...any) error {
+	t := args[0].(*actorTimer)
+	if p.timerMap[t.name] != t { // 已取消或已被替换
+		return nil
+	}
+	if 0 < t.interval { // 重复, 以上次到期时间为基准, 避免累计误差
+		t.expire += t.interval.Milliseconds()
+		if now := xtimer.GTimer.GetClock().Now().UnixMilli(); t.expire < now {
+			t.expire = now
+		}
+		p.arm(t)
+	} else {
+		delete(p.timerMap, t.name)
+	}
+	// 投递到邮箱, 按邮箱顺序处理. 不在当前(定时器)消息的处理中嵌套处理
+	p.SendMsg(NewMsg(t.msg.Ctx, t.msg.Cmd, t.msg.Args...))
+	return nil
+}
+
+// 当前时间. 使用 xtimer.GTimer 的时钟, 未设置时为系统时间
+func now() time.Time {
+	if xtimer.GTimer == nil {
+		return time.Now()
+	}
+	return xtimer.GTimer.GetClock().Now()
+}
+
+// sendAfter 延迟 delay 后, 投递 msg 到邮箱. 不可取消
+//
+//	使用 xtimer.GTimer, 未设置时使用 time.AfterFunc. 可在任意协程中调用
+func (p *Actor[KEY]) sendAfter(delay time.Duration, msg *Msg) {
+	if xtimer.GTimer == nil {
+		time.AfterFunc(delay, func() {
+			p.SendMsg(msg)
+		})
+		return
+	}
+	xtimer.GTimer.AddMillisecond(
+		xcontrol.NewCallBack(p.onSendAfter, msg),
+		now().UnixMilli()+delay.Milliseconds(),
+		&timerOut[KEY]{actor: p},
+	)
+}
+
+// onSendAfter sendAfter 到期 [actor 的处理协程中]
+func (p *Actor[KEY]) onSendAfter(args ...any) error {
+	p.SendMsg(args[0].(*Msg))
+	return nil
+}
+
+// 定时器到期事件 {args: [0]:*xcontrol.Event}
+func (p *Actor[KEY]) timer(msg *Msg) (resp any, err error) {
+	if len(msg.Args) < 1 {
+		return nil, errors.WithMessage(xerror.ParamCountNotMatch, xruntime.Location())
+	}
+	event, ok := msg.Args[0].(*xcontrol.Event)
+	if !ok {
+		return nil, errors.WithMessagef(xerror.Param, "invalid timer event type %v", xruntime.Location())
+	}
+	if event.ISwitch.IsOff() {
+		return nil, nil
+	}
+	return nil, event.ICallBack.Execute()
+}
@@ -0,0 +1,72 @@
+package actor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	xclock "github.com/75912001/xlib/clock"
+	xtimer "github.com/75912001/xlib/timer"
+)
+
+const (
+	testCmdSchedule CMD = 1 // 安排定时器
+	testCmdTick     CMD = 2 // 定时器到期
+	testCmdBarrier  CMD = 3 // 等待之前的消息处理完
+)
+
+func TestActorTimerVirtualClock(t *testing.T) {
+	clock := xclock.NewVirtual(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	timer := xtimer.NewTimerWithClock(clock)
+	if err := timer.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer timer.Stop()
+	old := xtimer.GTimer
+	xtimer.GTimer = timer
+	defer func() { xtimer.GTimer = old }()
+
+	var ticks int
+	var actor *Actor[uint64]
+	var behavior Behavior
+	behavior = func(messages ...any) (Behavior, any, error) {
+		msg := messages[0].(*Msg)
+		switch msg.Cmd {
+		case testCmdSchedule:
+			return behavior, nil, actor.ScheduleRepeat("tick", 100*time.Millisecond, time.Second, NewMsg(context.Background(), testCmdTick))
+		case testCmdTick:
+			ticks++
+		}
+		return behavior, ticks, nil
+	}
+	actor = NewActorWithOptions(uint64(1), nil, NewOptions().WithName("timer.test").WithFactory(BehaviorFactory(behavior)))
+	actor.Start()
+	defer func() { _, _ = actor.SendMsgSync(NewMsg(context.Background(), SystemReservedCommand_Stop)) }()
+
+	// 到期的定时器消息在 Advance 中入队, 其处理时再投递 tick. 两次同步消息后 tick 已处理
+	getTicks := func() int {
+		_, _ = actor.SendMsgSync(NewMsg(context.Background(), testCmdBarrier))
+		resp, err := actor.SendMsgSync(NewMsg(context.Background(), testCmdBarrier))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.(int)
+	}
+	if _, err := actor.SendMsgSync(NewMsg(context.Background(), testCmdSchedule)); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(99 * time.Millisecond)
+	if got := getTicks(); got != 0 {
+		t.Fatalf("ticks:%v before delay", got)
+	}
+	clock.Advance(time.Millisecond)
+	if got := getTicks(); got != 1 {
+		t.Fatalf("ticks:%v want:1", got)
+	}
+	for i := 2; i <= 4; i++ {
+		clock.Advance(time.Second)
+		if got := getTicks(); got != i {
+			t.Fatalf("ticks:%v want:%v", got, i)
+		}
+	}
+}
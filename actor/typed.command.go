@@ -0,0 +1,80 @@
+package actor
+
+import (
+	"context"
+	"time"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// Command 强类型命令
+//
+//	参数 ARG 放在 Msg.Args[0], 响应为 RESP. 多个参数请使用结构体
+//	e.g.: var CmdLogin = NewCommand[*LoginReq, *LoginRes](1001)
+type Command[ARG any, RESP any] struct {
+	cmd CMD
+}
+
+func NewCommand[ARG any, RESP any](cmd CMD) *Command[ARG, RESP] {
+	return &Command[ARG, RESP]{
+		cmd: cmd,
+	}
+}
+
+func (p *Command[ARG, RESP]) GetCmd() CMD {
+	return p.cmd
+}
+
+// NewMsg 创建消息
+func (p *Command[ARG, RESP]) NewMsg(ctx context.Context, arg ARG) *Msg {
+	return NewMsg(ctx, p.cmd, arg)
+}
+
+// Call 发送消息, 并等待响应 (同步)
+//
+//	[❗] 禁止在 actor 的处理协程中调用, 请使用 Actor.Ask
+func (p *Command[ARG, RESP]) Call(target IActorMsg, ctx context.Context, arg ARG) (RESP, error) {
+	return CastResponse[RESP](target.SendMsgSync(p.NewMsg(ctx, arg)))
+}
+
+// AskFuture 发送消息, 不阻塞, 返回 Future. 使用 FutureResult 获取强类型响应
+func (p *Command[ARG, RESP]) AskFuture(target IActorMsg, ctx context.Context, arg ARG, timeout time.Duration) *Future {
+	return AskFuture(target, p.NewMsg(ctx, arg), timeout)
+}
+
+// 取参数
+func (p *Command[ARG, RESP]) arg(msg *Msg) (arg ARG, err error) {
+	if len(msg.Args) != 1 {
+		return arg, errors.WithMessagef(xerror.ParamCountNotMatch, "cmd:%v args:%v %v", p.cmd, len(msg.Args), xruntime.Location())
+	}
+	arg, ok := msg.Args[0].(ARG)
+	if !ok {
+		return arg, errors.WithMessagef(xerror.Mismatch, "cmd:%v arg type:%T expect:%T %v", p.cmd, msg.Args[0], arg, xruntime.Location())
+	}
+	return arg, nil
+}
+
+// CastResponse 响应转换为 RESP
+//
+//	e.g.: CastResponse[*LoginRes](askResponse.Resp, askResponse.Err)
+func CastResponse[RESP any](resp any, err error) (RESP, error) {
+	var zero RESP
+	if err != nil {
+		return zero, err
+	}
+	if resp == nil {
+		return zero, nil
+	}
+	r, ok := resp.(RESP)
+	if !ok {
+		return zero, errors.WithMessagef(xerror.Mismatch, "resp type:%T expect:%T %v", resp, zero, xruntime.Location())
+	}
+	return r, nil
+}
+
+// FutureResult 阻塞等待 Future 的强类型结果
+func FutureResult[RESP any](future *Future) (RESP, error) {
+	return CastResponse[RESP](future.Result())
+}
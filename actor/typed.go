@@ -0,0 +1,156 @@
+package actor
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// 强类型处理函数 [注册时由泛型生成, 处理时无需反射]
+type typedHandler[STATE any] func(state *STATE, message any) (resp any, err error)
+
+// Router 强类型消息路由
+//
+//	按 Go 类型(HandleType) 或 按命令(HandleCommand) 注册处理函数, 类型不匹配, 重复注册等在注册时返回错误
+//	被 actor 使用后不可再注册
+type Router[STATE any] struct {
+	typeMap        map[reflect.Type]typedHandler[STATE] // key: 消息类型
+	cmdMap         map[CMD]typedHandler[STATE]          // key: 命令
+	defaultHandler func(state *STATE, messages ...any) (resp any, err error)
+	sealed         atomic.Bool // 已被 actor 使用
+}
+
+func NewRouter[STATE any]() *Router[STATE] {
+	return &Router[STATE]{
+		typeMap: make(map[reflect.Type]typedHandler[STATE]),
+		cmdMap:  make(map[CMD]typedHandler[STATE]),
+	}
+}
+
+// HandleDefault 设置未注册消息的处理函数 [default]: 返回 xerror.NotSupport
+func (p *Router[STATE]) HandleDefault(handler func(state *STATE, messages ...any) (resp any, err error)) error {
+	if p.sealed.Load() {
+		return errors.WithMessagef(xerror.InvalidOperation, "router sealed. %v", xruntime.Location())
+	}
+	p.defaultHandler = handler
+	return nil
+}
+
+// HandleType 注册 MSG 类型消息的处理函数
+//
+//	MSG 须为具体类型(按动态类型精确匹配), 不可为接口 或 *Msg (*Msg 请使用 HandleCommand)
+//	e.g.: HandleType(router, func(state *Player, msg *LoginReq) (*LoginRes, error) {...})
+func HandleType[STATE any, MSG any, RESP any](router *Router[STATE], handler func(state *STATE, msg MSG) (RESP, error)) error {
+	if router.sealed.Load() {
+		return errors.WithMessagef(xerror.InvalidOperation, "router sealed. %v", xruntime.Location())
+	}
+	if handler == nil {
+		return errors.WithMessagef(xerror.Param, "handler is nil. %v", xruntime.Location())
+	}
+	t := reflect.TypeFor[MSG]()
+	if t.Kind() == reflect.Interface {
+		return errors.WithMessagef(xerror.ParamNotSupport, "interface type:%v can not be dispatched. %v", t, xruntime.Location())
+	}
+	if t == reflect.TypeFor[*Msg]() {
+		return errors.WithMessagef(xerror.ParamNotSupport, "*Msg should use HandleCommand. %v", xruntime.Location())
+	}
+	if _, ok := router.typeMap[t]; ok {
+		return errors.WithMessagef(xerror.Duplicate, "type:%v %v", t, xruntime.Location())
+	}
+	router.typeMap[t] = func(state *STATE, message any) (any, error) {
+		return handler(state, message.(MSG))
+	}
+	return nil
+}
+
+// HandleCommand 注册命令的处理函数
+//
+//	参数/响应类型由 command 决定, 与发送方共用同一个 Command, 保证两端类型一致
+func HandleCommand[STATE any, ARG any, RESP any](router *Router[STATE], command *Command[ARG, RESP],
+	handler func(ctx context.Context, state *STATE, arg ARG) (RESP, error)) error {
+	if router.sealed.Load() {
+		return errors.WithMessagef(xerror.InvalidOperation, "router sealed. %v", xruntime.Location())
+	}
+	if handler == nil || command == nil {
+		return errors.WithMessagef(xerror.Param, "handler or command is nil. %v", xruntime.Location())
+	}
+	if !isCustomCommand(command.cmd) {
+		return errors.WithMessagef(xerror.OutOfRange, "cmd:%v is not custom command. %v", command.cmd, xruntime.Location())
+	}
+	if _, ok := router.cmdMap[command.cmd]; ok {
+		return errors.WithMessagef(xerror.Duplicate, "cmd:%v %v", command.cmd, xruntime.Location())
+	}
+	router.cmdMap[command.cmd] = func(state *STATE, message any) (any, error) {
+		msg := message.(*Msg)
+		arg, err := command.arg(msg)
+		if err != nil {
+			return nil, err
+		}
+		return handler(msg.Ctx, state, arg)
+	}
+	return nil
+}
+
+// 分发
+func (p *Router[STATE]) dispatch(state *STATE, messages ...any) (resp any, err error) {
+	message := messages[0]
+	if msg, ok := message.(*Msg); ok {
+		if handler, ok := p.cmdMap[msg.Cmd]; ok {
+			return handler(state, msg)
+		}
+	} else if handler, ok := p.typeMap[reflect.TypeOf(message)]; ok {
+		return handler(state, message)
+	}
+	if p.defaultHandler != nil {
+		return p.defaultHandler(state, messages...)
+	}
+	return nil, errors.WithMessagef(xerror.NotSupport, "unhandled message type:%T %v", message, xruntime.Location())
+}
+
+// TypedFactory 强类型 actor 的工厂
+//
+//	每次启动/重启调用 init 创建新状态. *STATE 或 STATE 实现 ILifecycle 时, 作为生命周期钩子
+//	router 在此之后不可再注册
+func TypedFactory[STATE any](init func() STATE, router *Router[STATE]) Factory {
+	router.sealed.Store(true)
+	return func() (Behavior, ILifecycle) {
+		state := init()
+		var behavior Behavior
+		behavior = func(messages ...any) (Behavior, any, error) {
+			resp, err := router.dispatch(&state, messages...)
+			return behavior, resp, err
+		}
+		if lifecycle, ok := any(&state).(ILifecycle); ok {
+			return behavior, lifecycle
+		}
+		if lifecycle, ok := any(state).(ILifecycle); ok {
+			return behavior, lifecycle
+		}
+		return behavior, nil
+	}
+}
+
+// TypedActor 强类型 actor
+//
+//	消息由 Router 按类型/命令分发到强类型的处理函数
+type TypedActor[KEY comparable, STATE any] struct {
+	*Actor[KEY]
+}
+
+// NewTypedActor 创建强类型 actor
+//
+//	init: 创建初始状态. opts 中的 factory 不生效
+//	配置错误会 panic
+func NewTypedActor[KEY comparable, STATE any](key KEY, parent *Actor[KEY], init func() STATE, router *Router[STATE], opts ...*Options) *TypedActor[KEY, STATE] {
+	if init == nil || router == nil {
+		panic(errors.WithMessagef(xerror.Param, "init or router is nil. key:%v %v", key, xruntime.Location()))
+	}
+	opts = append(opts[:len(opts):len(opts)], NewOptions().WithFactory(TypedFactory(init, router)))
+	return &TypedActor[KEY, STATE]{
+		Actor: NewActorWithOptions(key, parent, opts...),
+	}
+}
@@ -0,0 +1,41 @@
+// Package clock 时钟
+//
+//	IClock 可注入 xtimer, time.Mgr, log 等, 默认使用 GClock(真实时钟)
+//	测试时使用 Virtual 虚拟时钟, Advance 推进时间, 同步触发到期的定时器
+package clock
+
+import "time"
+
+// GClock 默认时钟, 组件创建时未指定时钟则使用
+//
+//	[⚠️] 在创建组件之前设置
+var GClock IClock = NewReal()
+
+// IClock 时钟
+type IClock interface {
+	Now() time.Time // 当前时间
+}
+
+// IVirtualSubscriber 虚拟时钟的订阅者 e.g.: 定时器
+type IVirtualSubscriber interface {
+	OnAdvance(now time.Time)         // 时间推进到 now, 在 Advance 的调用协程中同步执行
+	NextDeadline() (time.Time, bool) // 下一个需要推进到的时刻(e.g.: 最早的到期时间), 可以提前, 不可推后. false:没有
+}
+
+// IVirtual 虚拟时钟
+type IVirtual interface {
+	IClock
+	Subscribe(subscriber IVirtualSubscriber)
+	Unsubscribe(subscriber IVirtualSubscriber)
+}
+
+// Real 真实时钟
+type Real struct{}
+
+func NewReal() *Real {
+	return &Real{}
+}
+
+func (p *Real) Now() time.Time {
+	return time.Now()
+}
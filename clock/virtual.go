@@ -0,0 +1,95 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// VirtualStepMin 虚拟时钟每次推进的最小步长
+const VirtualStepMin = time.Millisecond
+
+// Virtual 虚拟时钟
+//
+//	只由 Advance/AdvanceTo 推进. 推进时依次跳到各订阅者的 NextDeadline, 同步通知所有订阅者,
+//	因此到期的定时器按到期时间顺序, 在 Advance 返回前全部触发
+type Virtual struct {
+	mu          sync.Mutex
+	now         time.Time
+	subscribers []IVirtualSubscriber
+
+	advanceMu sync.Mutex // 串行 Advance
+}
+
+// NewVirtual 新的虚拟时钟
+//
+//	start: 起始时间
+func NewVirtual(start time.Time) *Virtual {
+	return &Virtual{
+		now: start,
+	}
+}
+
+func (p *Virtual) Now() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.now
+}
+
+// Subscribe 订阅
+func (p *Virtual) Subscribe(subscriber IVirtualSubscriber) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.subscribers = append(p.subscribers, subscriber)
+}
+
+// Unsubscribe 取消订阅
+func (p *Virtual) Unsubscribe(subscriber IVirtualSubscriber) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, v := range p.subscribers {
+		if v == subscriber {
+			p.subscribers = append(p.subscribers[:i], p.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Advance 推进 d
+//
+//	[⚠️] 不能在订阅者的回调中调用
+func (p *Virtual) Advance(d time.Duration) {
+	p.AdvanceTo(p.Now().Add(d))
+}
+
+// AdvanceTo 推进到 target, target 不晚于当前时间时不推进
+func (p *Virtual) AdvanceTo(target time.Time) {
+	p.advanceMu.Lock()
+	defer p.advanceMu.Unlock()
+	for {
+		p.mu.Lock()
+		now := p.now
+		subscribers := append([]IVirtualSubscriber(nil), p.subscribers...)
+		p.mu.Unlock()
+		if !now.Before(target) {
+			return
+		}
+		next := target
+		for _, subscriber := range subscribers {
+			if deadline, ok := subscriber.NextDeadline(); ok && deadline.Before(next) {
+				next = deadline
+			}
+		}
+		if minNext := now.Add(VirtualStepMin); next.Before(minNext) { // 保证推进
+			next = minNext
+		}
+		if target.Before(next) {
+			next = target
+		}
+		p.mu.Lock()
+		p.now = next
+		p.mu.Unlock()
+		for _, subscriber := range subscribers {
+			subscriber.OnAdvance(next)
+		}
+	}
+}
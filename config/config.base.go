@@ -10,25 +10,32 @@ import (
 )
 
 type Base struct {
-	ProjectName                 *string                          `yaml:"projectName"`                 // 项目名称		[default]:constants.ProjectNameDefault
-	GroupID                     *uint32                          `yaml:"groupID"`                     // 分组ID
-	Name                        *string                          `yaml:"name"`                        // 服务名称
-	ServerID                    *uint32                          `yaml:"serverID"`                    // 服务ID
-	Version                     *string                          `yaml:"version"`                     // 版本号		[default]: constants.VersionDefault
-	PprofHttpPort               *uint16                          `yaml:"pprofHttpPort"`               // pprof性能分析 http端口		[default]: nil 不使用
-	GoMaxProcess                *int                             `yaml:"goMaxProcess"`                // [default]: runtime.NumCPU()
-	PacketLengthMax             *uint32                          `yaml:"packetLengthMax"`             // bytes,用户 上行 每个包的最大长度		[default]: math.MaxUint32
-	SendChannelCapacity         *uint32                          `yaml:"sendChannelCapacity"`         // bytes,每个链接的发送chan容量		[default]: 1000000
-	RunMode                     *uint32                          `yaml:"runMode"`                     // 运行模式 [0:release 1:debug]		[default]: 1
-	AvailableLoad               *uint32                          `yaml:"availableLoad"`               // 可用资源数		[default]: 1000000
-	PacketLimitRecvCntPreSecond *uint32                          `yaml:"packetLimitRecvCntPreSecond"` // 每秒接收包数限制		[default]: math.MaxUint32
-	ProcessingMode              *xconfigconstants.ProcessingMode `yaml:"processingMode"`              // 处理-模式 [0:bus 1:actor]		[default]: ProcessingModeBus
+	ProjectName                 *string                           `yaml:"projectName"`                 // 项目名称		[default]:constants.ProjectNameDefault
+	GroupID                     *uint32                           `yaml:"groupID"`                     // 分组ID
+	Name                        *string                           `yaml:"name"`                        // 服务名称
+	ServerID                    *uint32                           `yaml:"serverID"`                    // 服务ID
+	Version                     *string                           `yaml:"version"`                     // 版本号		[default]: constants.VersionDefault
+	PprofHttpPort               *uint16                           `yaml:"pprofHttpPort"`               // pprof性能分析 http端口		[default]: nil 不使用
+	GoMaxProcess                *int                              `yaml:"goMaxProcess"`                // [default]: runtime.NumCPU()
+	PacketLengthMax             *uint32                           `yaml:"packetLengthMax"`             // bytes,用户 上行 每个包的最大长度		[default]: math.MaxUint32
+	SendChannelCapacity         *uint32                           `yaml:"sendChannelCapacity"`         // bytes,每个链接的发送chan容量		[default]: 1000000
+	RunMode                     *uint32                           `yaml:"runMode"`                     // 运行模式 [0:release 1:debug]		[default]: 1
+	AvailableLoad               *uint32                           `yaml:"availableLoad"`               // 可用资源数		[default]: 1000000
+	PacketLimitRecvCntPreSecond *uint32                           `yaml:"packetLimitRecvCntPreSecond"` // 每秒接收包数限制		[default]: math.MaxUint32
+	ProcessingMode              *xconfigconstants.ProcessingMode  `yaml:"processingMode"`              // 处理-模式 [0:bus 1:actor(每个链接一个 actor)]		[default]: ProcessingModeBus
+	PacketLimitType             *xconfigconstants.PacketLimitType `yaml:"packetLimitType"`             // 包限流-类型 [0:按自然秒计数 1:令牌桶]		[default]: PacketLimitTypeSecond
+	BusPartitionCount           *uint32                           `yaml:"busPartitionCount"`           // bus 模式-网络事件分区数量, 按链接散列, 大于1时多核并行处理 [注意:不同链接的处理函数并发执行]		[default]: 1
 }
 
 func (p *Base) ProcessingModeIsActor() bool {
 	return *p.ProcessingMode == xconfigconstants.ProcessingModeActor
 }
 
+// BusPartitionEnabled bus 模式下网络事件是否分区处理
+func (p *Base) BusPartitionEnabled() bool {
+	return !p.ProcessingModeIsActor() && 1 < *p.BusPartitionCount
+}
+
 func (p *Base) Configure() error {
 	if p.ProjectName == nil {
 		p.ProjectName = &xconfigconstants.ProjectNameDefault
@@ -73,5 +80,13 @@ func (p *Base) Configure() error {
 		defaultValue := xconfigconstants.ProcessingModeBus
 		p.ProcessingMode = &defaultValue
 	}
+	if p.PacketLimitType == nil {
+		defaultValue := xconfigconstants.PacketLimitTypeSecond
+		p.PacketLimitType = &defaultValue
+	}
+	if p.BusPartitionCount == nil || *p.BusPartitionCount == 0 {
+		defaultValue := uint32(1)
+		p.BusPartitionCount = &defaultValue
+	}
 	return nil
 }
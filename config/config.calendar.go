@@ -0,0 +1,44 @@
+package config
+
+import (
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// Calendar 游戏日历, 每日/每周/每月 重置
+type Calendar struct {
+	Timezone  *string `yaml:"timezone"`  // IANA 时区 e.g.: Asia/Shanghai, UTC [default]: Local
+	ResetHour *uint32 `yaml:"resetHour"` // 每日重置时刻(时) [0,23] [default]: 0
+	WeekStart *uint32 `yaml:"weekStart"` // 每周重置日 [0:周日 1:周一 ... 6:周六] [default]: 1
+	MonthDay  *uint32 `yaml:"monthDay"`  // 每月重置日 [1,28] [default]: 1
+}
+
+func (p *Calendar) Configure() error {
+	if p.Timezone == nil {
+		defaultValue := "Local"
+		p.Timezone = &defaultValue
+	}
+	if p.ResetHour == nil {
+		var defaultValue uint32
+		p.ResetHour = &defaultValue
+	}
+	if 23 < *p.ResetHour {
+		return errors.WithMessagef(xerror.Config, "resetHour:%v %v", *p.ResetHour, xruntime.Location())
+	}
+	if p.WeekStart == nil {
+		defaultValue := uint32(1)
+		p.WeekStart = &defaultValue
+	}
+	if 6 < *p.WeekStart {
+		return errors.WithMessagef(xerror.Config, "weekStart:%v %v", *p.WeekStart, xruntime.Location())
+	}
+	if p.MonthDay == nil {
+		defaultValue := uint32(1)
+		p.MonthDay = &defaultValue
+	}
+	if *p.MonthDay < 1 || 28 < *p.MonthDay {
+		return errors.WithMessagef(xerror.Config, "monthDay:%v %v", *p.MonthDay, xruntime.Location())
+	}
+	return nil
+}
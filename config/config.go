@@ -22,6 +22,7 @@ type Mgr struct {
 	Base           Base           `yaml:"base"`
 	Log            Log            `yaml:"log"`
 	Timer          Timer          `yaml:"timer"`
+	Calendar       Calendar       `yaml:"calendar"`
 	Net            []*Net         `yaml:"net"`
 	KCP            KCP            `yaml:"kcp"`
 	Grpc           Grpc           `yaml:"grpc"`
@@ -65,6 +66,9 @@ func (p *Mgr) Parse(executablePath string) error {
 	if err := p.Timer.Configure(); err != nil {
 		return errors.WithMessagef(err, "timer configure failed. %v", xruntime.Location())
 	}
+	if err := p.Calendar.Configure(); err != nil {
+		return errors.WithMessagef(err, "calendar configure failed. %v", xruntime.Location())
+	}
 	for _, v := range p.Net {
 		if err := v.Configure(); err != nil {
 			return errors.WithMessagef(err, "net configure failed. %v", xruntime.Location())
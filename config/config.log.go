@@ -8,17 +8,78 @@ import (
 )
 
 type Log struct {
-	Level   *uint32 `yaml:"level"`   // 日志等级
-	AbsPath *string `yaml:"absPath"` // 日志绝对路径		[default]: 当前执行的程序-绝对路径,指向启动当前进程的可执行文件-目录路径. e.g.:absPath/log
+	Level       *uint32         `yaml:"level"`       // 日志等级
+	Format      *uint32         `yaml:"format"`      // 输出格式 0:文本 1:json, 一行一个 json 对象 [default]: 0
+	AbsPath     *string         `yaml:"absPath"`     // 日志绝对路径		[default]: 当前执行的程序-绝对路径,指向启动当前进程的可执行文件-目录路径. e.g.:absPath/log
+	MaxSize     *uint32         `yaml:"maxSize"`     // 单个日志文件最大 MB, 超过时切换到新的分段. 0:不限制 [default]: 0
+	MaxBackups  *uint32         `yaml:"maxBackups"`  // 历史日志文件保留数量(normal/error 分别计数). 0:不限制 [default]: 0
+	MaxAge      *uint32         `yaml:"maxAge"`      // 历史日志文件保留天数. 0:不限制 [default]: 0
+	Compress    *bool           `yaml:"compress"`    // 是否 gzip 压缩历史日志文件 [default]: false
+	MinDiskFree *uint32         `yaml:"minDiskFree"` // 磁盘剩余空间低于该值(MB)时暂停写文件. 0:不检查 [default]: 0
+	RateLimits  []*LogRateLimit `yaml:"rateLimits"`  // 按等级限流 [default]: nil, 不限流
+}
+
+// LogRateLimit 日志限流, 按调用位置计数
+type LogRateLimit struct {
+	Level      *uint32 `yaml:"level"`      // 日志等级
+	First      *uint32 `yaml:"first"`      // 同一调用位置, 每秒前 first 条输出 [default]: 0
+	Thereafter *uint32 `yaml:"thereafter"` // 超过 first 后, 每 thereafter 条输出 1 条. 0:不输出 [default]: 0
 }
 
 func (p *Log) Configure() error {
 	if p.Level == nil {
 		return errors.WithMessagef(xerror.Config, "level is nil. %v", xruntime.Location())
 	}
+	if p.Format == nil {
+		var defaultValue uint32
+		p.Format = &defaultValue
+	}
 	if p.AbsPath == nil {
 		executablePath := filepath.Join(xruntime.ExecutablePath, "log")
 		p.AbsPath = &executablePath
 	}
+	if p.MaxSize == nil {
+		var defaultValue uint32
+		p.MaxSize = &defaultValue
+	}
+	if p.MaxBackups == nil {
+		var defaultValue uint32
+		p.MaxBackups = &defaultValue
+	}
+	if p.MaxAge == nil {
+		var defaultValue uint32
+		p.MaxAge = &defaultValue
+	}
+	if p.Compress == nil {
+		var defaultValue bool
+		p.Compress = &defaultValue
+	}
+	if p.MinDiskFree == nil {
+		var defaultValue uint32
+		p.MinDiskFree = &defaultValue
+	}
+	for _, v := range p.RateLimits {
+		if err := v.Configure(); err != nil {
+			return errors.WithMessagef(err, "rate limit configure failed. %v", xruntime.Location())
+		}
+	}
+	return nil
+}
+
+func (p *LogRateLimit) Configure() error {
+	if p.Level == nil {
+		return errors.WithMessagef(xerror.Config, "level is nil. %v", xruntime.Location())
+	}
+	if p.First == nil {
+		var defaultValue uint32
+		p.First = &defaultValue
+	}
+	if p.Thereafter == nil {
+		var defaultValue uint32
+		p.Thereafter = &defaultValue
+	}
+	if *p.First == 0 && *p.Thereafter == 0 {
+		return errors.WithMessagef(xerror.Config, "level:%v first and thereafter are 0. %v", *p.Level, xruntime.Location())
+	}
 	return nil
 }
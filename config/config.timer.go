@@ -11,7 +11,7 @@ type Timer struct {
 	ScanSecondDuration *time.Duration `yaml:"scanSecondDuration"`
 	// 毫秒级定时器扫描间隔 [default]: timer.ScanMillisecondDurationDefault
 	ScanMillisecondDuration *time.Duration `yaml:"scanMillisecondDuration"`
-	// 毫秒级定时器实现类型 [default]: timer.MillisecondTypeList
+	// 毫秒级定时器实现类型 [0:list 1:小顶堆 2:分层时间轮] [default]: timer.MillisecondTypeList
 	MillisecondType *xtimerconstants.MillisecondType `yaml:"millisecondType"`
 }
 
@@ -7,6 +7,13 @@ const (
 type ProcessingMode uint32 // 处理-模式
 
 const (
-	ProcessingModeBus   ProcessingMode = 0
-	ProcessingModeActor ProcessingMode = 1
+	ProcessingModeBus   ProcessingMode = 0 // 总线: 网络事件由 server.Actor 处理(可按链接分区)
+	ProcessingModeActor ProcessingMode = 1 // actor: 每个链接一个 server.Actor 的子 actor
+)
+
+type PacketLimitType uint32 // 包限流-类型
+
+const (
+	PacketLimitTypeSecond      PacketLimitType = 0 // 按自然秒计数
+	PacketLimitTypeTokenBucket PacketLimitType = 1 // 令牌桶
 )
@@ -1,27 +1,30 @@
 package control
 
+import "sync/atomic"
+
+// SwitchButton 开关. 并发安全: 定时器协程检查, 业务协程关闭
 type SwitchButton struct {
-	state bool // 状态 true:on false:off
+	state atomic.Bool // 状态 true:on false:off
 }
 
 func NewSwitchButton(state bool) *SwitchButton {
-	return &SwitchButton{
-		state: state,
-	}
+	p := &SwitchButton{}
+	p.state.Store(state)
+	return p
 }
 
 func (p *SwitchButton) On() {
-	p.state = true
+	p.state.Store(true)
 }
 
 func (p *SwitchButton) Off() {
-	p.state = false
+	p.state.Store(false)
 }
 
 func (p *SwitchButton) IsOn() bool {
-	return p.state
+	return p.state.Load()
 }
 
 func (p *SwitchButton) IsOff() bool {
-	return !p.state
+	return !p.state.Load()
 }
@@ -2,7 +2,11 @@ package constants
 
 const TtlSecondDefault int64 = 33 // etcd 默认 TTL 时间 秒
 
-const WatchMsgTypeServer string = "server"       // etcd watch 消息类型-服务
-const WatchMsgTypeCommand string = "command"     // etcd watch 消息类型-命令
-const WatchMsgTypeGM string = "gm"               // etcd watch 消息类型-GM
-const WatchMsgTypeServerCfg string = "serverCfg" // etcd watch 消息类型-服务配置
+const WatchMsgTypeServer string = "server"         // etcd watch 消息类型-服务
+const WatchMsgTypeCommand string = "command"       // etcd watch 消息类型-命令
+const WatchMsgTypeGM string = "gm"                 // etcd watch 消息类型-GM
+const WatchMsgTypeServerCfg string = "serverCfg"   // etcd watch 消息类型-服务配置
+const WatchMsgTypeTimeOffset string = "timeOffset" // etcd watch 消息类型-时间偏移量. 由运维设置, 同组的服务使用
+const WatchMsgTypeLogLevel string = "logLevel"     // etcd watch 消息类型-日志等级. 由运维设置, 指定的服务使用
+
+const TimeOffsetServiceName string = "all" // 时间偏移量 key 中的服务名, 表示组内所有服务
@@ -228,6 +228,43 @@ func (p *Etcd) PutWithLease(key string, value string) (*etcdclientv3.PutResponse
 	return putResponse, nil
 }
 
+// PutIfAbsentWithLease key 不存在时放入键值对 WithLease 带ttl
+//
+//	返回: ok 是否放入, current 已存在时的当前值
+func (p *Etcd) PutIfAbsentWithLease(ctx context.Context, key string, value string) (ok bool, current string, err error) {
+	txnResponse, err := p.kv.Txn(ctx).
+		If(etcdclientv3.Compare(etcdclientv3.CreateRevision(key), "=", 0)).
+		Then(etcdclientv3.OpPut(key, value, etcdclientv3.WithLease(p.leaseGrantResponse.ID))).
+		Else(etcdclientv3.OpGet(key)).
+		Commit()
+	if err != nil {
+		return false, "", errors.WithMessagef(err, "etcd put if absent err. key:%v value:%v %v", key, value, xruntime.Location())
+	}
+	if txnResponse.Succeeded {
+		return true, value, nil
+	}
+	for _, response := range txnResponse.Responses {
+		if rangeResponse := response.GetResponseRange(); rangeResponse != nil && 0 < len(rangeResponse.Kvs) {
+			current = string(rangeResponse.Kvs[0].Value)
+		}
+	}
+	return false, current, nil
+}
+
+// DelIfValue 值相等时删除键值
+//
+//	返回: 是否删除
+func (p *Etcd) DelIfValue(ctx context.Context, key string, value string) (bool, error) {
+	txnResponse, err := p.kv.Txn(ctx).
+		If(etcdclientv3.Compare(etcdclientv3.Value(key), "=", value)).
+		Then(etcdclientv3.OpDelete(key)).
+		Commit()
+	if err != nil {
+		return false, errors.WithMessagef(err, "etcd del if value err. key:%v value:%v %v", key, value, xruntime.Location())
+	}
+	return txnResponse.Succeeded, nil
+}
+
 // Put 将一个键值对放入etcd中 [不带租约ttl]
 //func (p *Etcd) Put(key string, value string) (*etcdclientv3.PutResponse, error) {
 //	putResponse, err := p.kv.Put(context.TODO(), key, value)
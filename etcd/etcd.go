@@ -12,5 +12,7 @@ type IEtcd interface {
 	Stop() error
 	GetKey() string
 	PutWithLease(key string, value string) (*etcdclientv3.PutResponse, error)
+	PutIfAbsentWithLease(ctx context.Context, key string, value string) (ok bool, current string, err error)
+	DelIfValue(ctx context.Context, key string, value string) (bool, error)
 	KeepAlive(ctx context.Context) error
 }
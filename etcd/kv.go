@@ -3,6 +3,7 @@ package etcd
 import (
 	"encoding/json"
 	"fmt"
+	xetcdconstants "github.com/75912001/xlib/etcd/constants"
 	xlog "github.com/75912001/xlib/log"
 	"path"
 	"strconv"
@@ -16,6 +17,7 @@ type ValueJson struct {
 	Version       string       `json:"version,omitempty"`       // 有:直接使用. 没有:使用 base.version 生成
 	AvailableLoad uint32       `json:"availableLoad,omitempty"` // 剩余可用负载, 可用资源数
 	SecondOffset  int64        `json:"secondOffset,omitempty"`  // 服务 时间(秒)偏移量
+	LogLevel      string       `json:"logLevel,omitempty"`      // 日志等级配置 e.g.: "net.kcp=trace,*=info". 由运维设置
 	GrpcService   *GrpcService `json:"grpcService,omitempty"`   // gRPC 服务信息
 }
 
@@ -56,6 +58,21 @@ func GenKey(projectName string, etcdWatchMsgType string, groupID uint32, service
 	)
 }
 
+// GenTimeOffsetKey 组的时间偏移量 key, 值为 ValueJson, 使用 SecondOffset. 删除 key 即偏移量为 0
+//
+//	e.g.: etcdctl put /${projectName}/timeOffset/${groupID}/all/0/ '{"secondOffset":86400}'
+func GenTimeOffsetKey(projectName string, groupID uint32) string {
+	return GenKey(projectName, xetcdconstants.WatchMsgTypeTimeOffset, groupID, xetcdconstants.TimeOffsetServiceName, 0)
+}
+
+// GenLogLevelKey 服务的日志等级 key, 值为 ValueJson, 使用 LogLevel. 删除 key 即不再使用该 key 的配置
+//
+//	serviceID 为 0 时, 表示组内该服务名的所有服务. 与服务自己的 key 合并, 服务自己的优先
+//	e.g.: etcdctl put /${projectName}/logLevel/${groupID}/${serviceName}/${serviceID}/ '{"logLevel":"net.kcp=trace"}'
+func GenLogLevelKey(projectName string, groupID uint32, serviceName string, serviceID uint32) string {
+	return GenKey(projectName, xetcdconstants.WatchMsgTypeLogLevel, groupID, serviceName, serviceID)
+}
+
 func GenPrefixKey(projectName string) string {
 	return fmt.Sprintf("/%v/", projectName)
 }
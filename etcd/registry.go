@@ -1,12 +1,15 @@
 package etcd
 
 import (
+	xetcdconstants "github.com/75912001/xlib/etcd/constants"
 	xmap "github.com/75912001/xlib/map"
 )
 
 var GRegistry = NewRegistry()
 
 // 记录-服务信息
+//
+//	记录 watch 到的所有 key(用于区分新增/更新), FindBy* 只查找服务(WatchMsgTypeServer)的 key
 type Registry struct {
 	DataMap *xmap.MapMutexMgr[string, *ValueJson]
 }
@@ -32,8 +35,9 @@ func (p *Registry) Find(key string) (*ValueJson, bool) {
 func (p *Registry) FindByGroupNameID(groupID uint32, serviceName string, serviceID uint32) []*ValueJson {
 	var results []*ValueJson
 	p.DataMap.Foreach(func(key string, value *ValueJson) bool {
-		_, _groupID, _serviceName, _serviceID := Parse(key)
-		if _groupID == groupID && _serviceName == serviceName && _serviceID == serviceID {
+		_msgType, _groupID, _serviceName, _serviceID := Parse(key)
+		if _msgType == xetcdconstants.WatchMsgTypeServer &&
+			_groupID == groupID && _serviceName == serviceName && _serviceID == serviceID {
 			results = append(results, value)
 			return false
 		}
@@ -45,8 +49,8 @@ func (p *Registry) FindByGroupNameID(groupID uint32, serviceName string, service
 func (p *Registry) FindByGroupName(groupID uint32, serviceName string) []*ServiceInfo {
 	var results []*ServiceInfo
 	p.DataMap.Foreach(func(key string, value *ValueJson) bool {
-		_, _groupID, _serviceName, _serviceID := Parse(key)
-		if _groupID == groupID && _serviceName == serviceName {
+		_msgType, _groupID, _serviceName, _serviceID := Parse(key)
+		if _msgType == xetcdconstants.WatchMsgTypeServer && _groupID == groupID && _serviceName == serviceName {
 			results = append(results,
 				&ServiceInfo{
 					ServiceID:   _serviceID,
@@ -2,6 +2,8 @@
 // 使用 channel 作为事件通道
 // 优点: 简单, 性能好
 // 缺点: 需要预估事件通道容量, 如果容量不够, 会导致事件丢失
+// 每个优先级一个事件通道, 可通过 ChanOptions 设置 PriorityFunc; SendDelayed/SendAt 发送延迟事件
+// PrioritySystem 的事件不丢失: 通道满时放入溢出队列, 工作协程取出事件后移入通道
 package event
 
 import (
@@ -13,17 +15,23 @@ import (
 	xlog "github.com/75912001/xlib/log"
 	xruntime "github.com/75912001/xlib/runtime"
 	xutil "github.com/75912001/xlib/util"
+	"github.com/pkg/errors"
 	"runtime/debug"
+	"sync"
 	"time"
 )
 
 // ChanManager 事件管理器
 type ChanManager struct {
-	eventChan   chan any            // 事件通道
-	onFunction  xcontrol.OnFunction // 事件处理器
-	workerCount uint32              // 工作协程数量
-	ctx         context.Context     // 上下文，用于控制协程生命周期
+	eventChans  [PriorityCount]chan any // 事件通道, 按优先级
+	overflowMu  sync.Mutex              // 保护 overflow
+	overflow    []any                   // PrioritySystem 通道满时溢出的事件, 按发送顺序
+	delay       *delayQueue             // 延迟事件
+	onFunction  xcontrol.OnFunction     // 事件处理器
+	workerCount uint32                  // 工作协程数量
+	ctx         context.Context         // 上下文，用于控制协程生命周期
 	cancel      context.CancelFunc
+	options     *ChanOptions
 }
 
 // NewChanManager 创建一个新的事件管理器
@@ -32,21 +40,42 @@ type ChanManager struct {
 //	handler: 事件处理函数
 //	eventChanCapacity: 事件 chan 容量
 func NewChanManager(workerCount uint32, onFunction xcontrol.OnFunction, eventChanCapacity uint32) *ChanManager {
+	return NewChanManagerWithOptions(workerCount, onFunction, eventChanCapacity)
+}
+
+// NewChanManagerWithOptions 创建一个新的事件管理器
+//
+//	eventChanCapacity: PriorityNormal 的事件 chan 容量
+//	配置错误会 panic
+func NewChanManagerWithOptions(workerCount uint32, onFunction xcontrol.OnFunction, eventChanCapacity uint32, opts ...*ChanOptions) *ChanManager {
 	if workerCount <= 0 {
 		workerCount = 1
 	}
 	if eventChanCapacity <= 0 {
 		eventChanCapacity = xeventcommon.GEventChanCapacity
 	}
+	opt := MergeChanOptions(opts...)
+	if err := configureChanOptions(opt); err != nil {
+		panic(errors.WithMessagef(err, "configureChanOptions %v", xruntime.Location()))
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &ChanManager{
-		eventChan:   make(chan any, eventChanCapacity),
+	mgr := &ChanManager{
 		workerCount: workerCount,
 		onFunction:  onFunction,
 		ctx:         ctx,
 		cancel:      cancel,
+		options:     opt,
 	}
+	mgr.eventChans[PrioritySystem] = make(chan any, *opt.priorityChanCapacity)
+	mgr.eventChans[PriorityHigh] = make(chan any, *opt.priorityChanCapacity)
+	mgr.eventChans[PriorityNormal] = make(chan any, eventChanCapacity)
+	mgr.delay = newDelayQueue(func(item *delayItem) {
+		for _, event := range item.events {
+			mgr.Send(event)
+		}
+	})
+	return mgr
 }
 
 // Start 启动事件管理器
@@ -57,6 +86,8 @@ func (p *ChanManager) Start() {
 }
 
 // Stop 停止事件管理器
+//
+//	处理完通道中的事件后结束, 丢弃未到期的延迟事件
 func (p *ChanManager) Stop() {
 	go func() { // 检查协程是否可以结束
 		idleDuration := 100 * time.Millisecond
@@ -67,6 +98,9 @@ func (p *ChanManager) Stop() {
 		for range idleDelay.C {
 			idleDelay.Reset(idleDuration)
 			if p.handleQuitCheck() {
+				if cnt := p.delay.stop(); 0 < cnt {
+					xlog.PrintfInfo("drop delayed events with length:%d", cnt)
+				}
 				p.cancel()
 				return
 			}
@@ -76,12 +110,85 @@ func (p *ChanManager) Stop() {
 
 // Send 发送事件到管理器
 func (p *ChanManager) Send(event any) {
-	err := xutil.PushEventWithTimeout(p.eventChan, event, xconfigcommon.AddEventTimeoutDurationDefault)
+	priority := PriorityNormal
+	if p.options.priorityFunc != nil {
+		if v := p.options.priorityFunc(event); v.isValid() {
+			priority = v
+		}
+	}
+	p.SendWithPriority(priority, event)
+}
+
+// SendWithPriority 按指定优先级发送事件到管理器, 忽略 PriorityFunc
+//
+//	PrioritySystem: 不阻塞, 不丢弃. 其它: 通道满时等待, 超时记录日志并丢弃
+func (p *ChanManager) SendWithPriority(priority Priority, event any) {
+	if !priority.isValid() {
+		xlog.PrintErr(errors.WithMessagef(xerror.Param, "priority:%v %v", priority, xruntime.Location()))
+		return
+	}
+	if priority == PrioritySystem {
+		p.sendSystem(event)
+		return
+	}
+	err := xutil.PushEventWithTimeout(p.eventChans[priority], event, xconfigcommon.AddEventTimeoutDurationDefault)
 	if err != nil {
 		xlog.PrintErr(err)
 	}
 }
 
+// 发送 PrioritySystem 的事件. 通道满或已有溢出的事件时, 放入溢出队列(保持顺序)
+func (p *ChanManager) sendSystem(event any) {
+	p.overflowMu.Lock()
+	defer p.overflowMu.Unlock()
+	if len(p.overflow) == 0 {
+		select {
+		case p.eventChans[PrioritySystem] <- event:
+			return
+		default:
+		}
+	}
+	p.overflow = append(p.overflow, event)
+}
+
+// 溢出的事件移入 PrioritySystem 通道, 直至通道满
+//
+//	工作协程每次从 PrioritySystem 通道取出事件后调用. 溢出时通道已满, 之后每次取出都会移入, 不会遗留
+func (p *ChanManager) moveOverflow() {
+	p.overflowMu.Lock()
+	defer p.overflowMu.Unlock()
+	for 0 < len(p.overflow) {
+		select {
+		case p.eventChans[PrioritySystem] <- p.overflow[0]:
+			p.overflow[0] = nil
+			p.overflow = p.overflow[1:]
+		default:
+			return
+		}
+	}
+	p.overflow = nil
+}
+
+// SendDelayed 延迟 delay 后发送事件到管理器
+//
+//	delay <= 0 时立即发送
+func (p *ChanManager) SendDelayed(delay time.Duration, event any) {
+	p.SendAt(time.Now().Add(delay), event)
+}
+
+// SendAt 在 at 时刻发送事件到管理器
+//
+//	at 已过时立即发送. 到期后按 Send 处理
+func (p *ChanManager) SendAt(at time.Time, event any) {
+	if !time.Now().Before(at) {
+		p.Send(event)
+		return
+	}
+	if err := p.delay.push(at, []any{event}); err != nil {
+		xlog.PrintErr(err)
+	}
+}
+
 // worker 工作协程
 func (p *ChanManager) worker() {
 	defer func() {
@@ -95,22 +202,57 @@ func (p *ChanManager) worker() {
 		xlog.PrintInfo(xerror.GoroutineDone.Error())
 	}()
 
+	guard := starvationGuard{threshold: *p.options.starvationThreshold} // 每个工作协程独立
+	ready := func(priority Priority) bool {
+		return 0 < len(p.eventChans[priority])
+	}
 	for {
-		select {
-		case <-p.ctx.Done():
+		if p.ctx.Err() != nil {
 			return
-		case event := <-p.eventChan:
-			if err := p.onFunction(event); err != nil {
-				// 处理错误，可以选择记录日志或采取其他措施
-				xlog.PrintErr(err)
+		}
+		var event any
+		var isSystem bool
+		if priority, ok := guard.pick(ready); ok { // 有事件, 按优先级(含防饥饿)取出
+			select {
+			case event = <-p.eventChans[priority]:
+				isSystem = priority == PrioritySystem
+			default: // 已被其它工作协程取走
+				continue
+			}
+		} else { // 没有事件, 等待任一通道
+			select {
+			case <-p.ctx.Done():
+				return
+			case event = <-p.eventChans[PrioritySystem]:
+				isSystem = true
+			case event = <-p.eventChans[PriorityHigh]:
+			case event = <-p.eventChans[PriorityNormal]:
 			}
 		}
+		if isSystem {
+			p.moveOverflow()
+		}
+		if err := p.onFunction(event); err != nil {
+			// 处理错误，可以选择记录日志或采取其他措施
+			xlog.PrintErr(err)
+		}
+	}
+}
+
+// Len 通道(含溢出队列)中的事件数量
+func (p *ChanManager) Len() (cnt int) {
+	for _, eventChan := range p.eventChans {
+		cnt += len(eventChan)
 	}
+	p.overflowMu.Lock()
+	cnt += len(p.overflow)
+	p.overflowMu.Unlock()
+	return cnt
 }
 
 // 处理退出检查
 func (p *ChanManager) handleQuitCheck() bool {
-	cnt := len(p.eventChan)
+	cnt := p.Len()
 	if cnt == 0 {
 		xlog.PrintInfo("consume eventChan with length 0")
 		return true
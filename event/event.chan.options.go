@@ -0,0 +1,73 @@
+package event
+
+import (
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// ChanOptions ChanManager 选项
+type ChanOptions struct {
+	priorityFunc         PriorityFunc // 获取事件的优先级 [default]: nil, 均为 PriorityNormal
+	starvationThreshold  *uint32      // 防饥饿阈值, 同 ListOptions [default]: StarvationThresholdDefault
+	priorityChanCapacity *uint32      // PrioritySystem, PriorityHigh 的事件 chan 容量 [default]: PriorityChanCapacityDefault. PrioritySystem 满时溢出, 不丢弃
+}
+
+// PriorityChanCapacityDefault 高优先级事件 chan 容量 [default]
+const PriorityChanCapacityDefault uint32 = 10000
+
+// NewChanOptions 新的ChanOptions
+func NewChanOptions() *ChanOptions {
+	return &ChanOptions{}
+}
+
+func (p *ChanOptions) WithPriorityFunc(priorityFunc PriorityFunc) *ChanOptions {
+	p.priorityFunc = priorityFunc
+	return p
+}
+
+func (p *ChanOptions) WithStarvationThreshold(starvationThreshold uint32) *ChanOptions {
+	p.starvationThreshold = &starvationThreshold
+	return p
+}
+
+func (p *ChanOptions) WithPriorityChanCapacity(priorityChanCapacity uint32) *ChanOptions {
+	p.priorityChanCapacity = &priorityChanCapacity
+	return p
+}
+
+// MergeChanOptions 合并, 后面的覆盖前面的
+func MergeChanOptions(opts ...*ChanOptions) *ChanOptions {
+	newOptions := NewChanOptions()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.priorityFunc != nil {
+			newOptions.WithPriorityFunc(opt.priorityFunc)
+		}
+		if opt.starvationThreshold != nil {
+			newOptions.WithStarvationThreshold(*opt.starvationThreshold)
+		}
+		if opt.priorityChanCapacity != nil {
+			newOptions.WithPriorityChanCapacity(*opt.priorityChanCapacity)
+		}
+	}
+	return newOptions
+}
+
+// 配置
+func configureChanOptions(opts *ChanOptions) error {
+	if opts.starvationThreshold == nil {
+		starvationThreshold := StarvationThresholdDefault
+		opts.starvationThreshold = &starvationThreshold
+	}
+	if opts.priorityChanCapacity == nil {
+		priorityChanCapacity := PriorityChanCapacityDefault
+		opts.priorityChanCapacity = &priorityChanCapacity
+	}
+	if *opts.priorityChanCapacity == 0 {
+		return errors.WithMessagef(xerror.Param, "priorityChanCapacity:0 %v", xruntime.Location())
+	}
+	return nil
+}
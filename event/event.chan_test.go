@@ -0,0 +1,49 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+// PrioritySystem 通道满时溢出, 不丢弃, 保持顺序
+func TestChanManagerSystemOverflow(t *testing.T) {
+	const cnt = 100
+	block := make(chan struct{})
+	done := make(chan struct{})
+	var got []int
+	mgr := NewChanManagerWithOptions(1, func(args ...any) error {
+		v := args[0].(int)
+		if v == 0 {
+			<-block // 阻塞工作协程, 使通道满
+		}
+		got = append(got, v)
+		if v == cnt {
+			close(done)
+		}
+		return nil
+	}, 1, NewChanOptions().WithPriorityChanCapacity(1))
+	mgr.Start()
+	defer mgr.Stop()
+
+	mgr.SendWithPriority(PrioritySystem, 0)
+	for i := 1; i <= cnt; i++ {
+		mgr.SendWithPriority(PrioritySystem, i)
+	}
+	if l := mgr.Len(); l < cnt-1 {
+		t.Fatalf("len:%v", l)
+	}
+	close(block)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+	for i, v := range got {
+		if i != v {
+			t.Fatalf("got[%v]:%v", i, v)
+		}
+	}
+	if len(got) != cnt+1 {
+		t.Fatalf("got:%v want:%v", len(got), cnt+1)
+	}
+}
@@ -0,0 +1,132 @@
+// Package event
+//
+// 延迟事件
+// 到期后再入队(优先级由 PriorityFunc 决定), 同一到期时间按发送顺序入队
+// 使用最小堆 + 单个定时器, 管理器停止时丢弃未到期的事件
+package event
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// 延迟事件
+type delayItem struct {
+	at     time.Time // 到期时间
+	seq    uint64    // 发送序号, 到期时间相同时保持顺序
+	events []any
+}
+
+// 最小堆
+type delayHeap []*delayItem
+
+func (p delayHeap) Len() int { return len(p) }
+func (p delayHeap) Less(i, j int) bool {
+	if p[i].at.Equal(p[j].at) {
+		return p[i].seq < p[j].seq
+	}
+	return p[i].at.Before(p[j].at)
+}
+func (p delayHeap) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p *delayHeap) Push(x any)   { *p = append(*p, x.(*delayItem)) }
+func (p *delayHeap) Pop() any {
+	old := *p
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*p = old[:n-1]
+	return item
+}
+
+// 延迟队列
+type delayQueue struct {
+	mu      sync.Mutex
+	items   delayHeap
+	timer   *time.Timer
+	seq     uint64
+	stopped bool
+	deliver func(item *delayItem) // 到期投递, 在定时器协程中调用
+}
+
+func newDelayQueue(deliver func(item *delayItem)) *delayQueue {
+	return &delayQueue{
+		deliver: deliver,
+	}
+}
+
+// 加入延迟事件
+func (p *delayQueue) push(at time.Time, events []any) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return errors.WithMessagef(xerror.ChannelClosed, "cnt:%v %v", len(events), xruntime.Location())
+	}
+	p.seq++
+	item := &delayItem{
+		at:     at,
+		seq:    p.seq,
+		events: events,
+	}
+	heap.Push(&p.items, item)
+	if p.items[0] == item { // 最早到期, 重置定时器
+		p.resetTimer()
+	}
+	return nil
+}
+
+// 重置定时器为最早的到期时间 [须持有 mu]
+func (p *delayQueue) resetTimer() {
+	d := time.Until(p.items[0].at)
+	if p.timer == nil {
+		p.timer = time.AfterFunc(d, p.onTimer)
+		return
+	}
+	p.timer.Reset(d)
+}
+
+// 定时器到期, 投递所有到期的事件
+func (p *delayQueue) onTimer() {
+	var dueItems []*delayItem
+	p.mu.Lock()
+	now := time.Now()
+	for 0 < len(p.items) && !now.Before(p.items[0].at) {
+		dueItems = append(dueItems, heap.Pop(&p.items).(*delayItem))
+	}
+	if 0 < len(p.items) && !p.stopped {
+		p.resetTimer()
+	}
+	p.mu.Unlock()
+	for _, item := range dueItems {
+		p.deliver(item)
+	}
+}
+
+// 未到期的事件数量
+func (p *delayQueue) Len() (cnt int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, item := range p.items {
+		cnt += len(item.events)
+	}
+	return cnt
+}
+
+// 停止, 返回丢弃的延迟事件数量
+func (p *delayQueue) stop() (cnt int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopped = true
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	for _, item := range p.items {
+		cnt += len(item.events)
+	}
+	p.items = nil
+	return cnt
+}
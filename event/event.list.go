@@ -6,10 +6,13 @@
 // ListMgr 约定: 待处理数据在链表 events 中 notifyChan 仅表示[当前有需要处理的工作],
 // 与事件条数不必一一对应. worker 每次收到通知会持锁将队列排空后再回到 select,
 // 因此 notify 在缓冲满时走 default 丢弃的是冗余唤醒, 不表示丢弃链表中的事件
+//
+// 可通过 ListOptions 设置队列容量(默认不限制), 队列满时按 FullPolicy 拒绝/阻塞/丢弃最旧
+// 可通过 ListOptions 设置 UnboundedFunc, 指定不受容量限制的事件(e.g.: 系统消息, 需要响应的消息)
+// 可通过 ListOptions 设置 PriorityFunc, 按优先级处理(防饥饿); SendDelayed/SendAt 发送延迟事件
 package event
 
 import (
-	"container/list"
 	"context"
 	"runtime/debug"
 	"sync"
@@ -19,6 +22,8 @@ import (
 	xerror "github.com/75912001/xlib/error"
 	xlog "github.com/75912001/xlib/log"
 	xruntime "github.com/75912001/xlib/runtime"
+	xstatistics "github.com/75912001/xlib/statistics"
+	"github.com/pkg/errors"
 )
 
 type Manager ListMgr
@@ -26,13 +31,25 @@ type Manager ListMgr
 // ListMgr 事件管理器
 type ListMgr struct {
 	queueMu    sync.Mutex // 保护 events
-	events     *list.List
+	events     *priorityQueue
+	delay      *delayQueue   // 延迟事件
 	notifyChan chan struct{} // [有活要做] 的唤醒信号. 容量为 workerCount, 可与多条事件合并对应
+	spaceChan  chan struct{} // [有空位] 的唤醒信号. 用于 FullPolicyBlock
 
 	onFunction  xcontrol.OnFunction // 事件处理器
 	workerCount uint32              // 工作协程数量
 	ctx         context.Context     // 上下文，用于控制协程生命周期
 	cancel      context.CancelFunc
+	options     *ListOptions
+
+	Statistics *xstatistics.Mailbox // 队列统计数据
+}
+
+// 队列元素
+type listItem struct {
+	event       any
+	enqueueTime time.Time // 入队时间
+	unbounded   bool      // 不受容量限制, 不会被拒绝/阻塞/丢弃
 }
 
 // NewListMgr 创建一个新的事件管理器
@@ -40,20 +57,38 @@ type ListMgr struct {
 //	workerCount: 工作协程数量
 //	handler: 事件处理函数
 func NewListMgr(workerCount uint32, onFunction xcontrol.OnFunction) *ListMgr {
+	return NewListMgrWithOptions(workerCount, onFunction)
+}
+
+// NewListMgrWithOptions 创建一个新的事件管理器
+//
+//	配置错误会 panic
+func NewListMgrWithOptions(workerCount uint32, onFunction xcontrol.OnFunction, opts ...*ListOptions) *ListMgr {
 	if workerCount <= 0 {
 		workerCount = 1
 	}
+	opt := MergeListOptions(opts...)
+	if err := configureListOptions(opt); err != nil {
+		panic(errors.WithMessagef(err, "configureListOptions %v", xruntime.Location()))
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &ListMgr{
-		events:      list.New(),
+	mgr := &ListMgr{
+		events:      newPriorityQueue(*opt.starvationThreshold),
 		notifyChan:  make(chan struct{}, int(workerCount)),
+		spaceChan:   make(chan struct{}, 1),
 		workerCount: workerCount,
 		onFunction:  onFunction,
 		ctx:         ctx,
 		cancel:      cancel,
+		options:     opt,
+		Statistics:  xstatistics.NewMailbox(),
 	}
+	mgr.delay = newDelayQueue(func(item *delayItem) {
+		mgr.Send(item.events...)
+	})
+	return mgr
 }
 
 // Start 启动事件管理器
@@ -64,6 +99,8 @@ func (p *ListMgr) Start() {
 }
 
 // Stop 停止事件管理器
+//
+//	处理完队列中的事件后结束, 丢弃未到期的延迟事件
 func (p *ListMgr) Stop() {
 	go func() { // 检查协程是否可以结束
 		idleDuration := 100 * time.Millisecond
@@ -74,6 +111,9 @@ func (p *ListMgr) Stop() {
 		for range idleDelay.C {
 			idleDelay.Reset(idleDuration)
 			if p.handleQuitCheck() {
+				if cnt := p.delay.stop(); 0 < cnt {
+					xlog.PrintfInfo("drop delayed events with length:%d", cnt)
+				}
 				p.cancel()
 				return
 			}
@@ -83,18 +123,227 @@ func (p *ListMgr) Stop() {
 
 // Send 将事件入队，并尽量发送一条处理通知。
 // 事件本体在 events, 通知只提示 worker 检查队列,缓冲满时省略发送(冗余通知可丢)
+//
+//	入队失败(队列满)时记录日志, 需要处理失败时使用 TrySend
 func (p *ListMgr) Send(events ...any) {
+	if err := p.TrySend(events...); err != nil {
+		xlog.PrintErr(err)
+	}
+}
+
+// SendWithPriority 按指定优先级将事件入队, 忽略 PriorityFunc
+func (p *ListMgr) SendWithPriority(priority Priority, events ...any) {
+	if err := p.TrySendWithPriority(priority, events...); err != nil {
+		xlog.PrintErr(err)
+	}
+}
+
+// SendDelayed 延迟 delay 后将事件入队
+//
+//	delay <= 0 时立即入队
+func (p *ListMgr) SendDelayed(delay time.Duration, events ...any) {
+	p.SendAt(time.Now().Add(delay), events...)
+}
+
+// SendAt 在 at 时刻将事件入队
+//
+//	at 已过时立即入队. 到期后按 Send 处理, 入队失败时记录日志
+func (p *ListMgr) SendAt(at time.Time, events ...any) {
+	if !time.Now().Before(at) {
+		p.Send(events...)
+		return
+	}
+	if err := p.delay.push(at, events); err != nil {
+		xlog.PrintErr(err)
+	}
+}
+
+// SendFront 将事件插入队首(各自优先级的队首), events 之间保持顺序
+//
+//	不受容量限制. 用于重新处理已出队的事件 e.g.: 挂起期间暂存的消息
+//	已停止时返回 xerror.ChannelClosed
+func (p *ListMgr) SendFront(events ...any) error {
+	if p.ctx.Err() != nil { // 已停止, 不会再被处理
+		return errors.WithMessagef(xerror.ChannelClosed, "cnt:%v %v", len(events), xruntime.Location())
+	}
+	now := time.Now()
 	p.queueMu.Lock()
-	for _, event := range events {
-		p.events.PushBack(event)
+	for i := len(events) - 1; 0 <= i; i-- {
+		p.events.pushFront(p.priority(nil, events[i]), &listItem{event: events[i], enqueueTime: now, unbounded: true})
 	}
+	p.Statistics.OnEnqueue(int64(p.events.Len()))
 	p.queueMu.Unlock()
+	p.notify()
+	return nil
+}
+
+// DelayLen 未到期的延迟事件数量
+func (p *ListMgr) DelayLen() int {
+	return p.delay.Len()
+}
+
+// TrySendWithPriority 按指定优先级将事件入队, 忽略 PriorityFunc
+//
+//	队列满时, 同 TrySend
+func (p *ListMgr) TrySendWithPriority(priority Priority, events ...any) error {
+	if !priority.isValid() {
+		return errors.WithMessagef(xerror.Param, "priority:%v %v", priority, xruntime.Location())
+	}
+	return p.trySend(&priority, events)
+}
+
+// TrySend 将事件入队
+//
+//	队列满时, 按 FullPolicy 处理:
+//		FullPolicyReject: 返回 xerror.ChannelFull, 本批受容量限制的事件均不入队
+//		FullPolicyBlock: 阻塞等待空位, 超时返回 xerror.Timeout, 超时前已入队的事件不回退
+//		FullPolicyDropOldest: 丢弃最旧的受容量限制的事件(先丢弃低优先级的), 返回 nil
+//	PrioritySystem 及 UnboundedFunc 判定的事件不计入容量, 总是入队
+//	已停止时返回 xerror.ChannelClosed
+//	[⚠️] FullPolicyBlock 时, 禁止在本管理器的 worker 中向自己发送, 否则阻塞至超时
+func (p *ListMgr) TrySend(events ...any) error {
+	return p.trySend(nil, events)
+}
+
+// priority: nil 时使用 PriorityFunc
+func (p *ListMgr) trySend(priority *Priority, events []any) error {
+	if p.ctx.Err() != nil { // 已停止, 不会再被处理
+		return errors.WithMessagef(xerror.ChannelClosed, "cnt:%v %v", len(events), xruntime.Location())
+	}
+	capacity := int(*p.options.capacity)
+	now := time.Now()
+	switch {
+	case capacity == 0:
+		p.queueMu.Lock()
+		p.pushBack(priority, events, now)
+		p.queueMu.Unlock()
+	case *p.options.fullPolicy == FullPolicyReject:
+		p.queueMu.Lock()
+		if cnt := p.boundedCount(priority, events); capacity < p.events.boundedLen()+cnt {
+			p.pushBackUnbounded(priority, events, now)
+			p.queueMu.Unlock()
+			p.Statistics.OnReject(uint64(cnt))
+			p.notify()
+			return errors.WithMessagef(xerror.ChannelFull, "capacity:%v cnt:%v %v", capacity, cnt, xruntime.Location())
+		}
+		p.pushBack(priority, events, now)
+		p.queueMu.Unlock()
+	case *p.options.fullPolicy == FullPolicyDropOldest:
+		p.queueMu.Lock()
+		p.pushBack(priority, events, now)
+		var dropped uint64
+		for capacity < p.events.boundedLen() && p.events.dropOldest() {
+			dropped++
+		}
+		p.queueMu.Unlock()
+		if 0 < dropped {
+			p.Statistics.OnDrop(dropped)
+		}
+	case *p.options.fullPolicy == FullPolicyBlock:
+		if err := p.pushBackBlock(priority, events, now, capacity); err != nil {
+			return err
+		}
+	}
+	p.notify()
+	return nil
+}
+
+// 唤醒 worker
+func (p *ListMgr) notify() {
 	select {
 	case p.notifyChan <- struct{}{}:
 	default: // 缓冲已满: 已有足够待消费的唤醒, 不必再发
 	}
 }
 
+// 入队 [须持有 queueMu]
+func (p *ListMgr) pushBack(priority *Priority, events []any, now time.Time) {
+	for _, event := range events {
+		p.events.pushBack(p.priority(priority, event), &listItem{event: event, enqueueTime: now, unbounded: p.isUnbounded(priority, event)})
+	}
+	p.Statistics.OnEnqueue(int64(p.events.Len()))
+}
+
+// 只将不受容量限制的事件入队 [须持有 queueMu]
+func (p *ListMgr) pushBackUnbounded(priority *Priority, events []any, now time.Time) {
+	for _, event := range events {
+		if p.isUnbounded(priority, event) {
+			p.pushBack(priority, []any{event}, now)
+		}
+	}
+}
+
+// 受容量限制的事件数量
+func (p *ListMgr) boundedCount(priority *Priority, events []any) int {
+	var cnt int
+	for _, event := range events {
+		if !p.isUnbounded(priority, event) {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// 事件是否不受容量限制. PrioritySystem 的事件不受限制
+func (p *ListMgr) isUnbounded(priority *Priority, event any) bool {
+	if p.priority(priority, event) == PrioritySystem {
+		return true
+	}
+	return p.options.unboundedFunc != nil && p.options.unboundedFunc(event)
+}
+
+// 事件的优先级. PriorityFunc 返回无效值时为 PriorityNormal
+func (p *ListMgr) priority(priority *Priority, event any) Priority {
+	if priority != nil {
+		return *priority
+	}
+	if p.options.priorityFunc == nil {
+		return PriorityNormal
+	}
+	if v := p.options.priorityFunc(event); v.isValid() {
+		return v
+	}
+	return PriorityNormal
+}
+
+// 阻塞入队, 逐条等待空位
+func (p *ListMgr) pushBackBlock(priority *Priority, events []any, now time.Time, capacity int) error {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for idx := 0; idx < len(events); {
+		p.queueMu.Lock()
+		if p.isUnbounded(priority, events[idx]) || p.events.boundedLen() < capacity {
+			p.pushBack(priority, events[idx:idx+1], now)
+			p.queueMu.Unlock()
+			idx++
+			continue
+		}
+		p.queueMu.Unlock()
+		p.notify() // 已入队的事件, 先唤醒 worker 处理
+		if timer == nil {
+			timer = time.NewTimer(*p.options.blockTimeout)
+		}
+		select {
+		case <-p.spaceChan:
+		case <-timer.C:
+			p.Statistics.OnReject(uint64(len(events) - idx))
+			return errors.WithMessagef(xerror.Timeout, "capacity:%v cnt:%v %v", capacity, len(events)-idx, xruntime.Location())
+		}
+	}
+	return nil
+}
+
+// Len 当前队列长度
+func (p *ListMgr) Len() int {
+	p.queueMu.Lock()
+	defer p.queueMu.Unlock()
+	return p.events.Len()
+}
+
 // worker 工作协程
 func (p *ListMgr) worker() {
 	defer func() {
@@ -114,16 +363,23 @@ func (p *ListMgr) worker() {
 			return
 		case <-p.notifyChan:
 			// 单次唤醒内排空链表; 期间其它 Send 入队的元素也在本轮或后续 onFunction 中处理
+			// 每次按优先级(含防饥饿)取出一个, 新入队的高优先级事件在下一次处理
 			for {
 				p.queueMu.Lock()
-				element := p.events.Front()
-				if element == nil { // 没有事件
+				item := p.events.popFront()
+				if item == nil { // 没有事件
 					p.queueMu.Unlock()
 					break
 				}
-				event := element.Value
-				p.events.Remove(element)
+				length := p.events.Len()
 				p.queueMu.Unlock()
+				p.Statistics.OnDequeue(int64(length), item.enqueueTime)
+				select {
+				case p.spaceChan <- struct{}{}:
+				default:
+				}
+
+				event := item.event
 
 				if err := p.onFunction(event); err != nil {
 					// 处理错误，可以选择记录日志或采取其他措施
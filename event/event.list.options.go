@@ -0,0 +1,126 @@
+package event
+
+import (
+	"time"
+
+	xconfigcommon "github.com/75912001/xlib/config/constants"
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// FullPolicy 队列满时的处理策略
+type FullPolicy uint32
+
+const (
+	FullPolicyReject     FullPolicy = 0 // 拒绝, 返回 xerror.ChannelFull
+	FullPolicyBlock      FullPolicy = 1 // 阻塞等待, 超时返回 xerror.Timeout
+	FullPolicyDropOldest FullPolicy = 2 // 丢弃最旧的事件
+)
+
+// ListOptions ListMgr 选项
+type ListOptions struct {
+	capacity     *uint32        // 队列容量. 0:不限制 [default]: 0
+	fullPolicy   *FullPolicy    // 队列满时的处理策略 [default]: FullPolicyReject
+	blockTimeout *time.Duration // FullPolicyBlock 时的超时时长 [default]: constants.AddEventTimeoutDurationDefault
+
+	priorityFunc        PriorityFunc // 获取事件的优先级 [default]: nil, 均为 PriorityNormal
+	starvationThreshold *uint32      // 防饥饿阈值, 低优先级事件被连续跳过该次数后优先处理一次. 0:严格按优先级 [default]: StarvationThresholdDefault
+
+	unboundedFunc UnboundedFunc // 判断事件是否不受容量限制 [default]: nil, 除 PrioritySystem 外均受容量限制
+}
+
+// UnboundedFunc 判断事件是否不受容量限制. 返回 true 的事件不计入容量, 不会被拒绝/阻塞/丢弃
+//
+//	e.g.: 停止等系统消息, 调用方等待响应的消息
+type UnboundedFunc func(event any) bool
+
+// NewListOptions 新的ListOptions
+func NewListOptions() *ListOptions {
+	return &ListOptions{}
+}
+
+func (p *ListOptions) WithCapacity(capacity uint32) *ListOptions {
+	p.capacity = &capacity
+	return p
+}
+
+func (p *ListOptions) WithFullPolicy(fullPolicy FullPolicy) *ListOptions {
+	p.fullPolicy = &fullPolicy
+	return p
+}
+
+func (p *ListOptions) WithBlockTimeout(blockTimeout time.Duration) *ListOptions {
+	p.blockTimeout = &blockTimeout
+	return p
+}
+
+func (p *ListOptions) WithPriorityFunc(priorityFunc PriorityFunc) *ListOptions {
+	p.priorityFunc = priorityFunc
+	return p
+}
+
+func (p *ListOptions) WithStarvationThreshold(starvationThreshold uint32) *ListOptions {
+	p.starvationThreshold = &starvationThreshold
+	return p
+}
+
+func (p *ListOptions) WithUnboundedFunc(unboundedFunc UnboundedFunc) *ListOptions {
+	p.unboundedFunc = unboundedFunc
+	return p
+}
+
+// MergeListOptions 合并, 后面的覆盖前面的
+func MergeListOptions(opts ...*ListOptions) *ListOptions {
+	newOptions := NewListOptions()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.capacity != nil {
+			newOptions.WithCapacity(*opt.capacity)
+		}
+		if opt.fullPolicy != nil {
+			newOptions.WithFullPolicy(*opt.fullPolicy)
+		}
+		if opt.blockTimeout != nil {
+			newOptions.WithBlockTimeout(*opt.blockTimeout)
+		}
+		if opt.priorityFunc != nil {
+			newOptions.WithPriorityFunc(opt.priorityFunc)
+		}
+		if opt.starvationThreshold != nil {
+			newOptions.WithStarvationThreshold(*opt.starvationThreshold)
+		}
+		if opt.unboundedFunc != nil {
+			newOptions.WithUnboundedFunc(opt.unboundedFunc)
+		}
+	}
+	return newOptions
+}
+
+// 配置
+func configureListOptions(opts *ListOptions) error {
+	if opts.capacity == nil {
+		var capacity uint32 = 0
+		opts.capacity = &capacity
+	}
+	if opts.fullPolicy == nil {
+		fullPolicy := FullPolicyReject
+		opts.fullPolicy = &fullPolicy
+	}
+	switch *opts.fullPolicy {
+	case FullPolicyReject, FullPolicyBlock, FullPolicyDropOldest:
+	default:
+		return errors.WithMessagef(xerror.ParamNotSupport, "fullPolicy:%v %v", *opts.fullPolicy, xruntime.Location())
+	}
+	if opts.blockTimeout == nil {
+		blockTimeout := xconfigcommon.AddEventTimeoutDurationDefault
+		opts.blockTimeout = &blockTimeout
+	}
+	if opts.starvationThreshold == nil {
+		starvationThreshold := StarvationThresholdDefault
+		opts.starvationThreshold = &starvationThreshold
+	}
+	return nil
+}
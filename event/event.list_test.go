@@ -0,0 +1,152 @@
+package event
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	xerror "github.com/75912001/xlib/error"
+	"github.com/pkg/errors"
+)
+
+// 收集处理的事件
+func newTestListMgr(opts ...*ListOptions) (*ListMgr, chan any) {
+	out := make(chan any, 1024)
+	mgr := NewListMgrWithOptions(1, func(args ...any) error {
+		out <- args[0]
+		return nil
+	}, opts...)
+	return mgr, out
+}
+
+// 等待处理 cnt 个事件
+func collect(t *testing.T, out chan any, cnt int) []any {
+	t.Helper()
+	events := make([]any, 0, cnt)
+	for len(events) < cnt {
+		select {
+		case event := <-out:
+			events = append(events, event)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout. events:%v want:%v", events, cnt)
+		}
+	}
+	return events
+}
+
+func TestListMgrFullPolicyReject(t *testing.T) {
+	mgr, out := newTestListMgr(NewListOptions().
+		WithCapacity(2).
+		WithFullPolicy(FullPolicyReject).
+		WithUnboundedFunc(func(event any) bool { return event == "unbounded" }))
+	defer mgr.Stop()
+
+	if err := mgr.TrySend(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	// 满时, 本批受容量限制的事件均不入队, 不受容量限制的事件入队
+	if err := mgr.TrySend(3, "unbounded"); !errors.Is(err, xerror.ChannelFull) {
+		t.Fatalf("err:%v", err)
+	}
+	if got := mgr.Statistics.GetRejected(); got != 1 {
+		t.Fatalf("rejected:%v want:1", got)
+	}
+	mgr.Start()
+	if got := collect(t, out, 3); !slices.Equal(got, []any{1, 2, "unbounded"}) {
+		t.Fatalf("got:%v", got)
+	}
+}
+
+func TestListMgrFullPolicyDropOldest(t *testing.T) {
+	mgr, out := newTestListMgr(NewListOptions().
+		WithCapacity(2).
+		WithFullPolicy(FullPolicyDropOldest).
+		WithPriorityFunc(func(event any) Priority {
+			if event == "system" {
+				return PrioritySystem
+			}
+			return PriorityNormal
+		}))
+	defer mgr.Stop()
+
+	// PrioritySystem 的事件不计入容量, 不被丢弃
+	for _, event := range []any{"system", 1, 2, 3, 4} {
+		if err := mgr.TrySend(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := mgr.Statistics.GetDropped(); got != 2 {
+		t.Fatalf("dropped:%v want:2", got)
+	}
+	mgr.Start()
+	if got := collect(t, out, 3); !slices.Equal(got, []any{"system", 3, 4}) {
+		t.Fatalf("got:%v", got)
+	}
+}
+
+func TestListMgrFullPolicyBlock(t *testing.T) {
+	mgr, out := newTestListMgr(NewListOptions().
+		WithCapacity(1).
+		WithFullPolicy(FullPolicyBlock).
+		WithBlockTimeout(200 * time.Millisecond))
+	defer mgr.Stop()
+
+	if err := mgr.TrySend(1); err != nil {
+		t.Fatal(err)
+	}
+	// 没有空位, 超时
+	begin := time.Now()
+	if err := mgr.TrySend(2); !errors.Is(err, xerror.Timeout) {
+		t.Fatalf("err:%v", err)
+	}
+	if elapsed := time.Since(begin); elapsed < 200*time.Millisecond {
+		t.Fatalf("elapsed:%v", elapsed)
+	}
+	// 处理后有空位, 入队
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		mgr.Start()
+	}()
+	if err := mgr.TrySend(3); err != nil {
+		t.Fatal(err)
+	}
+	if got := collect(t, out, 2); !slices.Equal(got, []any{1, 3}) {
+		t.Fatalf("got:%v", got)
+	}
+}
+
+// 持续有高优先级事件时, 低优先级事件每被跳过 threshold 次处理一次
+func TestListMgrStarvation(t *testing.T) {
+	const high, low = "high", "low"
+	priorityFunc := func(event any) Priority {
+		if event == high {
+			return PriorityHigh
+		}
+		return PriorityNormal
+	}
+	send := func(mgr *ListMgr) {
+		for range 6 {
+			mgr.Send(high)
+		}
+		for range 3 {
+			mgr.Send(low)
+		}
+	}
+
+	mgr, out := newTestListMgr(NewListOptions().WithPriorityFunc(priorityFunc).WithStarvationThreshold(2))
+	defer mgr.Stop()
+	send(mgr)
+	mgr.Start()
+	if got := collect(t, out, 9); !slices.Equal(got, []any{high, high, low, high, high, low, high, high, low}) {
+		t.Fatalf("got:%v", got)
+	}
+
+	// 0: 严格按优先级
+	strict, strictOut := newTestListMgr(NewListOptions().WithPriorityFunc(priorityFunc).WithStarvationThreshold(0))
+	defer strict.Stop()
+	send(strict)
+	strict.Start()
+	if got := collect(t, strictOut, 9); !slices.Equal(got, []any{high, high, high, high, high, high, low, low, low}) {
+		t.Fatalf("got:%v", got)
+	}
+}
@@ -0,0 +1,160 @@
+// Package event
+//
+// PartitionMgr 分区事件管理器
+// 事件按键(e.g.: 玩家ID, 链接指针)散列到 N 个分区, 每个分区一个 ListMgr, 一个工作协程
+// 相同键的事件总在同一个分区中按发送顺序处理, 不同键的事件并行处理
+package event
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unsafe"
+
+	xcontrol "github.com/75912001/xlib/control"
+	xstatistics "github.com/75912001/xlib/statistics"
+)
+
+// PartitionKeyFunc 获取事件的分区键
+type PartitionKeyFunc func(event any) uint64
+
+// IPartitionKey 事件自带分区键. 未设置 PartitionKeyFunc 时使用, 都没有时进入 0 号分区
+type IPartitionKey interface {
+	PartitionKey() uint64
+}
+
+// PartitionMgr 分区事件管理器
+type PartitionMgr struct {
+	partitions []*ListMgr
+	keyFunc    PartitionKeyFunc
+}
+
+// NewPartitionMgr 创建分区事件管理器
+//
+//	partitionCount: 分区数量(工作协程数量) [0:1]
+//	onFunction: 事件处理函数, 不同分区并发调用
+//	keyFunc: 获取分区键 [nil: 使用 IPartitionKey]
+//	opts: 每个分区的队列选项
+func NewPartitionMgr(partitionCount uint32, onFunction xcontrol.OnFunction, keyFunc PartitionKeyFunc, opts ...*ListOptions) *PartitionMgr {
+	if partitionCount == 0 {
+		partitionCount = 1
+	}
+	partitions := make([]*ListMgr, partitionCount)
+	for i := range partitions {
+		partitions[i] = NewListMgrWithOptions(1, onFunction, opts...)
+	}
+	return &PartitionMgr{
+		partitions: partitions,
+		keyFunc:    keyFunc,
+	}
+}
+
+// Start 启动
+func (p *PartitionMgr) Start() {
+	for _, partition := range p.partitions {
+		partition.Start()
+	}
+}
+
+// Stop 停止. 与 ListMgr.Stop 相同, 异步等待各分区处理完已入队的事件后结束
+func (p *PartitionMgr) Stop() {
+	for _, partition := range p.partitions {
+		partition.Stop()
+	}
+}
+
+// Send 按事件的分区键发送
+//
+//	入队失败时记录日志, 需要处理失败时使用 TrySend
+func (p *PartitionMgr) Send(events ...any) {
+	for _, event := range events {
+		p.partitions[p.Partition(p.key(event))].Send(event)
+	}
+}
+
+// TrySend 按事件的分区键发送
+//
+//	返回第一个错误, 之后的事件继续发送
+func (p *PartitionMgr) TrySend(events ...any) (err error) {
+	for _, event := range events {
+		if e := p.partitions[p.Partition(p.key(event))].TrySend(event); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// SendWithKey 指定分区键发送
+func (p *PartitionMgr) SendWithKey(key uint64, events ...any) {
+	p.partitions[p.Partition(key)].Send(events...)
+}
+
+// TrySendWithKey 指定分区键发送
+func (p *PartitionMgr) TrySendWithKey(key uint64, events ...any) error {
+	return p.partitions[p.Partition(key)].TrySend(events...)
+}
+
+// Partition 分区键 -> 分区序号
+//
+//	先打散, 避免指针(对齐), 连续ID 等分布不均
+func (p *PartitionMgr) Partition(key uint64) uint32 {
+	key ^= key >> 33
+	key *= 0xff51afd7ed558ccd
+	key ^= key >> 33
+	key *= 0xc4ceb9fe1a85ec53
+	key ^= key >> 33
+	return uint32(key % uint64(len(p.partitions)))
+}
+
+// PartitionCount 分区数量
+func (p *PartitionMgr) PartitionCount() uint32 {
+	return uint32(len(p.partitions))
+}
+
+// Len 所有分区的队列长度
+func (p *PartitionMgr) Len() int {
+	var cnt int
+	for _, partition := range p.partitions {
+		cnt += partition.Len()
+	}
+	return cnt
+}
+
+// GetStatistics 获取分区的队列统计数据 (队列长度, 最高水位, 延迟)
+func (p *PartitionMgr) GetStatistics(partition uint32) *xstatistics.Mailbox {
+	return p.partitions[partition].Statistics
+}
+
+// String 所有分区的队列统计数据
+func (p *PartitionMgr) String() string {
+	var builder strings.Builder
+	for i, partition := range p.partitions {
+		if 0 < i {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(fmt.Sprintf("partition:%v %v", i, partition.Statistics))
+	}
+	return builder.String()
+}
+
+func (p *PartitionMgr) key(event any) uint64 {
+	if p.keyFunc != nil {
+		return p.keyFunc(event)
+	}
+	if k, ok := event.(IPartitionKey); ok {
+		return k.PartitionKey()
+	}
+	return 0
+}
+
+// StringKey 字符串 -> 分区键
+func StringKey(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// PointerKey 指针 -> 分区键 [e.g.: 链接]
+func PointerKey[T any](ptr *T) uint64 {
+	return uint64(uintptr(unsafe.Pointer(ptr)))
+}
@@ -0,0 +1,140 @@
+// Package event
+//
+// 事件优先级
+// 高优先级的事件先处理, 同优先级按发送顺序处理
+// 防饥饿: 低优先级事件被连续跳过 starvationThreshold 次后, 优先处理一次
+package event
+
+import "container/list"
+
+// Priority 事件优先级, 数值越小越优先
+type Priority uint32
+
+const (
+	PrioritySystem Priority = 0 // 系统 e.g.: 停止, 生命周期
+	PriorityHigh   Priority = 1 // 高 e.g.: 定时器回调
+	PriorityNormal Priority = 2 // 普通 e.g.: 数据包 [default]
+
+	PriorityCount = 3 // 优先级数量
+)
+
+// StarvationThresholdDefault 防饥饿阈值 [default]
+const StarvationThresholdDefault uint32 = 32
+
+// PriorityFunc 获取事件的优先级
+type PriorityFunc func(event any) Priority
+
+func (p Priority) isValid() bool {
+	return p < PriorityCount
+}
+
+// 防饥饿选择器
+type starvationGuard struct {
+	threshold uint32                // 连续跳过次数阈值. 0:不防饥饿, 严格按优先级
+	waited    [PriorityCount]uint32 // 各优先级有事件时被连续跳过的次数
+}
+
+// 选择本次处理的优先级
+//
+//	ready: 该优先级是否有事件
+//	先选被跳过次数达到阈值的最低优先级, 没有则选最高优先级
+func (p *starvationGuard) pick(ready func(priority Priority) bool) (Priority, bool) {
+	selected := PriorityCount
+	if 0 < p.threshold {
+		for priority := Priority(PriorityCount - 1); selected == PriorityCount && PrioritySystem < priority; priority-- {
+			if p.threshold <= p.waited[priority] && ready(priority) {
+				selected = int(priority)
+			}
+		}
+	}
+	for priority := PrioritySystem; selected == PriorityCount && priority < PriorityCount; priority++ {
+		if ready(priority) {
+			selected = int(priority)
+		}
+	}
+	if selected == PriorityCount {
+		return 0, false
+	}
+	p.waited[selected] = 0
+	for priority := Priority(selected + 1); priority < PriorityCount; priority++ {
+		if ready(priority) {
+			p.waited[priority]++
+		}
+	}
+	return Priority(selected), true
+}
+
+// 优先级队列 [非并发安全]
+type priorityQueue struct {
+	lists   [PriorityCount]*list.List
+	length  int
+	bounded int // 受容量限制的事件数量
+	guard   starvationGuard
+}
+
+func newPriorityQueue(starvationThreshold uint32) *priorityQueue {
+	queue := &priorityQueue{
+		guard: starvationGuard{threshold: starvationThreshold},
+	}
+	for i := range queue.lists {
+		queue.lists[i] = list.New()
+	}
+	return queue
+}
+
+func (p *priorityQueue) Len() int {
+	return p.length
+}
+
+// 受容量限制的事件数量
+func (p *priorityQueue) boundedLen() int {
+	return p.bounded
+}
+
+func (p *priorityQueue) pushBack(priority Priority, item *listItem) {
+	p.lists[priority].PushBack(item)
+	p.length++
+	if !item.unbounded {
+		p.bounded++
+	}
+}
+
+func (p *priorityQueue) pushFront(priority Priority, item *listItem) {
+	p.lists[priority].PushFront(item)
+	p.length++
+	if !item.unbounded {
+		p.bounded++
+	}
+}
+
+// 按优先级(含防饥饿)取出一个事件, 没有事件时返回 nil
+func (p *priorityQueue) popFront() *listItem {
+	priority, ok := p.guard.pick(func(priority Priority) bool {
+		return 0 < p.lists[priority].Len()
+	})
+	if !ok {
+		return nil
+	}
+	p.length--
+	item := p.lists[priority].Remove(p.lists[priority].Front()).(*listItem)
+	if !item.unbounded {
+		p.bounded--
+	}
+	return item
+}
+
+// 丢弃最旧的受容量限制的事件, 先丢弃低优先级的. 不丢弃 PrioritySystem 的事件
+func (p *priorityQueue) dropOldest() bool {
+	for priority := Priority(PriorityCount - 1); PrioritySystem < priority; priority-- {
+		for element := p.lists[priority].Front(); element != nil; element = element.Next() {
+			if element.Value.(*listItem).unbounded {
+				continue
+			}
+			p.lists[priority].Remove(element)
+			p.length--
+			p.bounded--
+			return true
+		}
+	}
+	return false
+}
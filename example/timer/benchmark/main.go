@@ -0,0 +1,92 @@
+// 毫秒级定时器 基准对比: list / 小顶堆 / 分层时间轮
+//
+//	go run ./example/timer/benchmark -n 50000 -span 2000 -cancel 0.5
+//	add: 加入 n 个定时器(到期时间在 span 毫秒内随机), 直到定时器协程处理完的耗时
+//	fire-lag: 未删除的定时器中最晚的到期时刻, 到其全部到期事件收到的延迟
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	xconfig "github.com/75912001/xlib/config"
+	xcontrol "github.com/75912001/xlib/control"
+	xtimer "github.com/75912001/xlib/timer"
+	xtimerconstants "github.com/75912001/xlib/timer/constants"
+)
+
+// 到期输出, 只计数
+type counter struct {
+	fired atomic.Int64
+}
+
+func (p *counter) Send(events ...any) {
+	p.fired.Add(int64(len(events)))
+}
+
+func run(millisecondType xtimerconstants.MillisecondType, n int, span int64, cancelRatio float64) {
+	xconfig.GConfigMgr.Timer.MillisecondType = &millisecondType
+	timer := xtimer.NewTimer()
+	_ = timer.Start(context.Background())
+	defer timer.Stop()
+
+	out := &counter{}       // 未删除的定时器
+	cancelOut := &counter{} // 删除的定时器, 加入耗时过长时可能在删除前到期
+	callBack := xcontrol.NewCallBack(func(...any) error { return nil })
+	r := rand.New(rand.NewSource(1))
+	milliseconds := make([]*xtimer.Millisecond, n)
+
+	now := time.Now().UnixMilli()
+	begin := time.Now()
+	cancelCnt := int(float64(n) * cancelRatio)
+	var lastExpire int64
+	for i := range milliseconds {
+		expire := now + 100 + r.Int63n(span) // 留出加入的时间
+		if cancelCnt <= i && lastExpire < expire {
+			lastExpire = expire
+		}
+		if i < cancelCnt {
+			milliseconds[i] = timer.AddMillisecond(callBack, expire, cancelOut)
+		} else {
+			milliseconds[i] = timer.AddMillisecond(callBack, expire, out)
+		}
+	}
+	// 哨兵: 定时器协程处理完之前的加入后, 才会处理该定时器
+	sentinel := &counter{}
+	timer.AddMillisecond(callBack, 0, sentinel)
+	for sentinel.fired.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	addCost := time.Since(begin)
+
+	for _, millisecond := range milliseconds[:cancelCnt] {
+		timer.DelMillisecond(millisecond)
+	}
+	want := int64(n - cancelCnt)
+	for out.fired.Load() < want {
+		time.Sleep(time.Millisecond)
+	}
+	lag := time.Now().UnixMilli() - lastExpire
+	fmt.Printf("type:%v n:%v span:%vms cancel:%v add:%v fire-lag:%vms\n",
+		millisecondType, n, span, cancelCnt, addCost, lag)
+}
+
+func main() {
+	n := flag.Int("n", 50000, "定时器数量")
+	span := flag.Int64("span", 2000, "到期时间范围 毫秒")
+	cancelRatio := flag.Float64("cancel", 0.5, "删除比例")
+	flag.Parse()
+
+	for _, millisecondType := range []xtimerconstants.MillisecondType{
+		xtimerconstants.MillisecondTypeList,
+		xtimerconstants.MillisecondTypeMinHeap,
+		xtimerconstants.MillisecondTypeWheel,
+	} {
+		run(millisecondType, *n, *span, *cancelRatio)
+	}
+}
@@ -1,6 +1,9 @@
 package log
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 const (
 	TraceIDKey = "TID" // 日志traceId key, value 为 string
@@ -24,3 +27,25 @@ const (
 	normalLogFileBaseName = "normal.log" // 正常日志文件名
 	errorLogFileBaseName  = "error.log"  // 错误日志文件名
 )
+
+const (
+	fileSegmentFormat = "%s/%s.%d.%d.%s" // 分段文件全路径名格式 例如 ${filePath}/${prefix}.20200101.1.normal.log
+	compressSuffix    = ".gz"            // 压缩文件后缀
+	diskCheckInterval = 10 * time.Second // 检查磁盘剩余空间的间隔
+	maintainChanCap   = 1                // 维护历史日志文件 通道容量
+	netSinkBufferSize = 10000            // NetSink 缓冲的日志数量
+	rateLimitPeriod   = time.Second      // 限流周期
+)
+
+const (
+	LevelRootName    = "*"       // SetLevels 中根的名称
+	LevelInheritName = "inherit" // SetLevels 中恢复继承的等级
+)
+
+// 日志输出格式
+const (
+	FormatText uint32 = 0 // 文本 [时间][日志级别][TID:xxx][UID:xxx][堆栈信息][扩展信息] 日志消息
+	FormatJSON uint32 = 1 // json, 一行一个 json 对象
+)
+
+const logTimeFormatJSON = "2006-01-02T15:04:05.000000Z07:00" // json 日志时间格式 RFC3339 微秒
@@ -0,0 +1,31 @@
+package log
+
+import "context"
+
+// WithTraceID 设置 ctx 中的 TID, 日志中输出 e.g.: [TID:xxx]
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, TraceIDKey, traceID)
+}
+
+// WithUserID 设置 ctx 中的 UID, 日志中输出 e.g.: [UID:10001]
+func WithUserID(ctx context.Context, uid uint64) context.Context {
+	return context.WithValue(ctx, UserIDKey, uid)
+}
+
+// TraceIDFromContext ctx 中的 TID
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	traceID, ok := ctx.Value(TraceIDKey).(string)
+	return traceID, ok
+}
+
+// UserIDFromContext ctx 中的 UID
+func UserIDFromContext(ctx context.Context) (uint64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	uid, ok := ctx.Value(UserIDKey).(uint64)
+	return uid, ok
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package log
+
+import (
+	"syscall"
+)
+
+// 磁盘剩余空间(非特权用户可用)-字节
+func diskFree(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
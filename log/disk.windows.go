@@ -0,0 +1,12 @@
+//go:build windows
+
+package log
+
+import (
+	xerror "github.com/75912001/xlib/error"
+)
+
+// 磁盘剩余空间-字节, windows 不支持, 不检查
+func diskFree(path string) (uint64, error) {
+	return 0, xerror.NotSupport
+}
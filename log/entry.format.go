@@ -8,7 +8,7 @@ import (
 )
 
 // 格式化日志数据
-// 格式为  [时间][日志级别][TID:xxx][UID:xxx][堆栈信息][扩展信息,为 json 格式 {ExtendFields-key:ExtendFields:val,...}][日志消息]
+// 格式为  [时间][日志级别][名称(子日志)][TID:xxx][UID:xxx][堆栈信息][扩展信息,为 json 格式 {ExtendFields-key:ExtendFields:val,...}][日志消息]
 
 func formatLogData(p *entry) {
 	// 使用sync.Pool来复用buffer以减少内存分配
@@ -28,6 +28,12 @@ func formatLogData(p *entry) {
 	buf.WriteByte('[')
 	buf.WriteString(levelDesc[p.level])
 	buf.WriteByte(']')
+	// 名称
+	if p.name != "" {
+		buf.WriteByte('[')
+		buf.WriteString(p.name)
+		buf.WriteByte(']')
+	}
 	// TraceID
 	buf.WriteByte('[')
 	buf.WriteString(TraceIDKey)
@@ -100,6 +106,15 @@ func formatLogData(p *entry) {
 	p.outBytes = append(p.outBytes[:0], buf.Bytes()...)
 }
 
+// formatEntry 按输出格式, 格式化日志数据
+func formatEntry(p *entry, format uint32) {
+	if format == FormatJSON {
+		formatLogDataJSON(p)
+		return
+	}
+	formatLogData(p)
+}
+
 // appendLogMessage 将用户消息写入 buf(与 formatLogData 共用同一缓冲)
 func appendLogMessage(buf *bytes.Buffer, p *entry) {
 	if p.format != "" {
@@ -0,0 +1,232 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+	"unsafe"
+
+	xpool "github.com/75912001/xlib/pool"
+)
+
+// 格式化日志数据-json, 一行一个 json 对象
+// 格式为 {"time":"xxx","level":"xxx","name":"xxx","TID":"xxx","UID":xxx,"line":xxx,"file":"xxx","func":"xxx","msg":"xxx",ExtendFields-key:ExtendFields-val,...}
+//
+//	name(子日志名称)/TID 不存在时省略. UID 不存在时为 0
+//	ExtendFields 中类型为 uint64 的 UID 已作为顶层 UID 字段, 不再重复输出
+//	ExtendFields 的 key 与顶层字段同名时, 加上前缀 jsonExtendKeyPrefix e.g.: "ext.time", 避免重复的 key
+
+// json 顶层字段
+var jsonReservedKeys = map[string]struct{}{
+	"time": {}, "level": {}, "name": {}, TraceIDKey: {}, UserIDKey: {}, "line": {}, "file": {}, "func": {}, "msg": {},
+}
+
+// 与顶层字段同名的扩展字段 key 的前缀
+const jsonExtendKeyPrefix = "ext."
+
+func formatLogDataJSON(p *entry) {
+	buf := xpool.Buffer.Get()
+	// 临时缓冲, 用于需要转义的格式化内容(日志消息, 非基础类型的值)
+	tmp := xpool.Buffer.Get()
+	defer func() {
+		xpool.Buffer.Put(buf)
+		xpool.Buffer.Put(tmp)
+	}()
+	if buf.Cap() < bufferCapacity {
+		buf.Grow(bufferCapacity)
+	}
+
+	// 时间
+	buf.WriteString(`{"time":"`)
+	buf.Write(p.time.AppendFormat(buf.AvailableBuffer(), logTimeFormatJSON))
+	// 日志级别
+	buf.WriteString(`","level":"`)
+	buf.WriteString(levelDesc[p.level])
+	buf.WriteByte('"')
+	// 名称
+	if p.name != "" {
+		buf.WriteString(`,"name":`)
+		appendJSONString(buf, p.name)
+	}
+	// TraceID
+	if p.ctx != nil {
+		if traceIDVal := p.ctx.Value(TraceIDKey); traceIDVal != nil {
+			buf.WriteString(`,"` + TraceIDKey + `":`)
+			appendJSONValue(buf, tmp, traceIDVal)
+		}
+	}
+	// UID 优先从 ctx 查找,其次查找 field
+	var uid uint64
+	if p.ctx != nil {
+		if uidVal := p.ctx.Value(UserIDKey); uidVal != nil {
+			uid, _ = uidVal.(uint64)
+		}
+	}
+	if uid == 0 {
+		for idx := 0; idx+1 < len(p.extendFields); idx += 2 {
+			if key, ok := p.extendFields[idx].(string); ok && key == UserIDKey {
+				uid, _ = p.extendFields[idx+1].(uint64)
+				break
+			}
+		}
+	}
+	buf.WriteString(`,"` + UserIDKey + `":`)
+	buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uid, 10))
+	// 堆栈
+	if p.file != "" {
+		buf.WriteString(`,"line":`)
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(p.line), 10))
+		buf.WriteString(`,"file":`)
+		appendJSONString(buf, p.file)
+		buf.WriteString(`,"func":`)
+		appendJSONString(buf, p.funcName)
+	}
+	// 日志消息
+	buf.WriteString(`,"msg":`)
+	tmp.Reset()
+	appendLogMessage(tmp, p)
+	appendJSONString(buf, bytesToString(tmp.Bytes()))
+	// 扩展字段
+	for idx := 0; idx < len(p.extendFields); idx += 2 {
+		key := p.extendFields[idx]
+		var val any
+		if idx+1 < len(p.extendFields) {
+			val = p.extendFields[idx+1]
+		}
+		s, ok := key.(string)
+		if !ok {
+			tmp.Reset()
+			_, _ = fmt.Fprintf(tmp, "%v", key)
+			s = tmp.String()
+		}
+		if _, isUID := val.(uint64); isUID && s == UserIDKey {
+			continue
+		}
+		if _, reserved := jsonReservedKeys[s]; reserved {
+			s = jsonExtendKeyPrefix + s
+		}
+		buf.WriteByte(',')
+		appendJSONString(buf, s)
+		buf.WriteByte(':')
+		appendJSONValue(buf, tmp, val)
+	}
+	buf.WriteByte('}')
+
+	p.outBytes = append(p.outBytes[:0], buf.Bytes()...)
+}
+
+// appendJSONValue 按类型写入 json 值. 数值/布尔不加引号, 其余为转义后的字符串
+//
+//	tmp: 临时缓冲, 会被重置
+func appendJSONValue(buf *bytes.Buffer, tmp *bytes.Buffer, v any) {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		appendJSONString(buf, val)
+	case bool:
+		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), val))
+	case int:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
+	case int8:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
+	case int16:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
+	case int32:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
+	case int64:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), val, 10))
+	case uint:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(val), 10))
+	case uint8:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(val), 10))
+	case uint16:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(val), 10))
+	case uint32:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(val), 10))
+	case uint64:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), val, 10))
+	case float32:
+		appendJSONFloat(buf, float64(val), 32)
+	case float64:
+		appendJSONFloat(buf, val, 64)
+	case time.Time:
+		buf.WriteByte('"')
+		buf.Write(val.AppendFormat(buf.AvailableBuffer(), time.RFC3339Nano))
+		buf.WriteByte('"')
+	case error:
+		appendJSONString(buf, val.Error())
+	case fmt.Stringer:
+		appendJSONString(buf, val.String())
+	default:
+		tmp.Reset()
+		_, _ = fmt.Fprintf(tmp, "%v", val)
+		appendJSONString(buf, bytesToString(tmp.Bytes()))
+	}
+}
+
+// appendJSONFloat 写入浮点数. NaN/Inf 不是合法的 json 数值, 写为字符串
+func appendJSONFloat(buf *bytes.Buffer, f float64, bitSize int) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		buf.WriteByte('"')
+		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), f, 'g', -1, bitSize))
+		buf.WriteByte('"')
+		return
+	}
+	buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), f, 'g', -1, bitSize))
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString 写入转义后的 json 字符串(含引号). 非法的 UTF-8 替换为 \ufffd
+func appendJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
+// bytesToString 零拷贝转换, 转换后不可修改 b
+func bytesToString(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
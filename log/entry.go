@@ -14,6 +14,7 @@ type ExtendFields []any // key,val 数组
 type entry struct {
 	level uint32    // 本条目的日志级别
 	time  time.Time // 生成日志的时间
+	name  string    // 日志名称, 子日志使用 e.g.: net.kcp
 
 	format string // 日志格式
 	args   []any  // 日志参数
@@ -33,6 +34,7 @@ func newEntry() *entry {
 
 func (p *entry) reset() {
 	p.level = LevelOff
+	p.name = ""
 	p.line = 0
 	p.file = ""
 	p.funcName = ""
@@ -60,6 +62,11 @@ func (p *entry) withCallerInfo(line int, file, funcName string) *entry {
 	return p
 }
 
+func (p *entry) withName(name string) *entry {
+	p.name = name
+	return p
+}
+
 func (p *entry) withMessage(format string, args ...any) *entry {
 	p.format = format
 	p.args = args
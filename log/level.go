@@ -1,6 +1,14 @@
 package log
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
 
 // 日志等级
 const (
@@ -33,3 +41,31 @@ func GetLevelDesc(level uint32) string {
 	}
 	return levelDesc[level]
 }
+
+// 等级名称, 用于解析
+var levelName = []string{
+	LevelOff:   "off",
+	LevelFatal: "fatal",
+	LevelError: "error",
+	LevelWarn:  "warn",
+	LevelInfo:  "info",
+	LevelDebug: "debug",
+	LevelTrace: "trace",
+	LevelOn:    "on",
+}
+
+// ParseLevel 解析等级, 不区分大小写
+//
+//	e.g.: trace, TRA, 6
+func ParseLevel(s string) (uint32, error) {
+	s = strings.TrimSpace(s)
+	for level := LevelOff; level <= LevelOn; level++ {
+		if strings.EqualFold(s, levelName[level]) || strings.EqualFold(s, levelDesc[level]) {
+			return level, nil
+		}
+	}
+	if level, err := strconv.ParseUint(s, 10, 32); err == nil && level <= uint64(LevelOn) {
+		return uint32(level), nil
+	}
+	return 0, errors.WithMessagef(xerror.Level, "level:%v is invalid. %v", s, xruntime.Location())
+}
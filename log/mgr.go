@@ -1,5 +1,4 @@
 // 日志
-// 使用系统log,自带锁
 // 使用协程操作io输出日志
 // release 每小时自动创建新的日志文件
 // debug 每天自动创建新的日志文件
@@ -9,12 +8,10 @@ package log
 import (
 	"context"
 	"io"
-	"log"
-	"os"
 	"runtime"
 	"runtime/debug"
-	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	xerror "github.com/75912001/xlib/error"
@@ -39,13 +36,17 @@ func NewMgr(opts ...*options) (ILog, error) {
 
 // 日志管理器
 type mgr struct {
-	options         *options
-	loggerSlice     [LevelOn]*log.Logger // 日志实例 [note]:使用时,注意协程安全
-	logChan         chan *entry          // 日志写入通道
-	waitGroupOutPut sync.WaitGroup       // 同步锁 用于日志退出时,等待完全输出
-	logDuration     int                  // 日志分割刻度,变化时,使用新的日志文件 按天或者小时  e.g.: 20210819 或 2021081901
-	openFiles       []*os.File           // 当前打开的文件
-	timeMgr         *xtime.Mgr
+	options          *options
+	level            atomic.Uint32  // 等级, 初始为 options.level, SetLevel 修改
+	logChan          chan *entry    // 日志写入通道
+	waitGroupOutPut  sync.WaitGroup // 同步锁 用于日志退出时,等待完全输出
+	file             *rotatingFile  // 日志文件 [note]:仅在日志协程中写入
+	timeMgr          *xtime.Mgr
+	limiter          *rateLimiter            // 限流 nil:不限流
+	summaryStopChan  chan struct{}           // 停止限流汇总
+	waitGroupSummary sync.WaitGroup          // 同步锁 用于日志退出时,等待限流汇总完成
+	namedMu          sync.Mutex              // 保护 named
+	named            map[string]*namedLogger // 子日志 key:名称
 }
 
 func (p *mgr) handleOptions(opts ...*options) error {
@@ -58,16 +59,20 @@ func (p *mgr) handleOptions(opts ...*options) error {
 
 // 开始
 func (p *mgr) start() error {
-	// 初始化logger
-	for i := LevelOff; i < LevelOn; i++ {
-		p.loggerSlice[i] = log.New(os.Stdout, "", 0)
-	}
+	p.level.Store(*p.options.level)
 	p.logChan = make(chan *entry, logChannelEntryCapacity)
 	p.timeMgr = xtime.NewMgr()
-	// 初始化各级别的日志输出
-	if err := newWriters(p); err != nil {
-		return errors.WithMessagef(err, "new writers failed. %v", xruntime.Location())
+	p.timeMgr.SetClock(p.options.clock)
+	// 初始化日志文件, 标准输出,标准错误重定向
+	file, err := newRotatingFile(p.options, p.timeMgr.NowTime(), func(normal io.Writer, all io.Writer) {
+		stdOut.SetOutput(normal)
+		stdErr.SetOutput(all)
+		stdFormat.Store(*p.options.format)
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "new rotating file failed. %v", xruntime.Location())
 	}
+	p.file = file
 	p.waitGroupOutPut.Add(1)
 	go func() {
 		defer func() {
@@ -81,46 +86,71 @@ func (p *mgr) start() error {
 		}()
 		doLog(p)
 	}()
+	// 限流
+	if p.limiter = newRateLimiter(p.options.rateLimits, p.options.clock); p.limiter != nil {
+		stdLimiter.Store(p.limiter)
+		p.summaryStopChan = make(chan struct{})
+		p.waitGroupSummary.Add(1)
+		go func() {
+			defer func() {
+				if xruntime.IsRelease() {
+					if err := recover(); err != nil {
+						PrintErr(xerror.GoroutinePanic, err, string(debug.Stack()))
+					}
+				}
+				p.waitGroupSummary.Done()
+			}()
+			doSummary(p)
+		}()
+	}
 	return nil
 }
 
-// GetLevel 获取日志等级
-func (p *mgr) GetLevel() uint32 {
-	return *p.options.level
+// doSummary 定期输出限流抑制的数量, 停止时输出剩余的
+func doSummary(p *mgr) {
+	ticker := time.NewTicker(*p.options.rateLimitSummary)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.writeSummary()
+		case <-p.summaryStopChan:
+			p.writeSummary()
+			return
+		}
+	}
 }
 
-// getLogDuration 取得日志刻度
-func (p *mgr) getLogDuration(sec int64) int {
-	var logFormat string
-	if xruntime.IsRelease() {
-		logFormat = "2006010215" //年月日小时
-	} else {
-		logFormat = "20060102" //年月日
-	}
-	durationStr := time.Unix(sec, 0).Format(logFormat)
-	duration, err := strconv.Atoi(durationStr)
-	if err != nil {
-		PrintfErr("strconv.Atoi sec:%v durationStr:%v err:%v", sec, durationStr, err)
-	}
-	return duration
+// writeSummary 输出限流抑制的数量, 位于被抑制的调用位置
+func (p *mgr) writeSummary() {
+	p.limiter.summarize(func(pc uintptr, level uint32, suppressed uint64) {
+		line, file, funcName := callsiteInfo(pc)
+		p.logChan <- p.newEntry().
+			withLevel(level).
+			withTime(p.timeMgr.NowTime()).
+			withCallerInfo(line, file, funcName).
+			withMessage("suppressed %v similar messages", suppressed)
+	})
+}
+
+// GetLevel 获取日志等级
+func (p *mgr) GetLevel() uint32 {
+	return p.level.Load()
 }
 
 // doLog 处理日志
 func doLog(p *mgr) {
 	for v := range p.logChan {
-		formatLogData(v)
+		formatEntry(v, *p.options.format)
 		p.callBack(v)
-		// 检查自动切换日志
-		if p.logDuration != p.getLogDuration(v.time.Unix()) {
-			if err := newWriters(p); err != nil {
-				PrintfErr("log duration changed, init writers failed, err:%v", err)
-				p.options.entryPoolOptions.put(v)
-				continue
-			}
-		}
+		// 补上换行, 一条日志一行
+		v.outBytes = append(v.outBytes, '\n')
 		if *p.options.isWriteFile {
-			writeLogLine(p, v)
+			if err := p.file.write(v.level, v.time, v.outBytes); err != nil {
+				PrintfErr("write file failed. err:%v %v", err, xruntime.Location())
+			}
 		}
+		writeSinks(p.options.sinks, v, *p.options.format)
 		p.options.entryPoolOptions.put(v)
 	}
 	// goroutine 退出,再设置chan为nil, (如果没有退出就设置为nil, 读chan == nil  会 block)
@@ -132,71 +162,35 @@ func (p *mgr) SetLevel(level uint32) error {
 	if level < LevelOff || LevelOn < level {
 		return errors.WithMessagef(xerror.Level, "level is invalid. %v", xruntime.Location())
 	}
-	p.options.WithLevel(level)
-	return nil
-}
-
-// writeLogLine 将已格式化字节写入对应级别 Writer, 避免整行 string 与 log.Print 再分配
-func writeLogLine(p *mgr, e *entry) {
-	w := p.loggerSlice[e.level].Writer()
-	if w == nil {
-		return
-	}
-	_, _ = w.Write(e.outBytes)
-}
-
-// newWriters 初始化各级别的日志输出
-func newWriters(p *mgr) error {
-	// 检查是否要关闭文件
-	for i := range p.openFiles {
-		if err := p.openFiles[i].Close(); err != nil {
-			return errors.WithMessagef(err, "close file failed. %v", xruntime.Location())
-		}
-	}
-	second := p.timeMgr.NowTime().Unix()
-	logDuration := p.getLogDuration(second)
-	normalWriter, err := newNormalFileWriter(*p.options.absPath, *p.options.namePrefix, logDuration)
-	if err != nil {
-		return errors.WithMessagef(err, "new normal file writer failed. %v", xruntime.Location())
-	}
-	errorWriter, err := newErrorFileWriter(*p.options.absPath, *p.options.namePrefix, logDuration)
-	if err != nil {
-		return errors.WithMessagef(err, "new error file writer failed. %v", xruntime.Location())
-	}
-	p.logDuration = logDuration
-	allWriter := io.MultiWriter(normalWriter, errorWriter)
-	// 标准输出,标准错误重定向
-	stdOut.SetOutput(normalWriter)
-	stdErr.SetOutput(allWriter)
-
-	p.loggerSlice[LevelFatal].SetOutput(allWriter)
-	p.loggerSlice[LevelError].SetOutput(allWriter)
-	p.loggerSlice[LevelWarn].SetOutput(allWriter)
-	p.loggerSlice[LevelInfo].SetOutput(normalWriter)
-	p.loggerSlice[LevelDebug].SetOutput(normalWriter)
-	p.loggerSlice[LevelTrace].SetOutput(normalWriter)
-	// 记录打开的文件
-	p.openFiles = p.openFiles[0:0]
-	p.openFiles = append(p.openFiles, normalWriter)
-	p.openFiles = append(p.openFiles, errorWriter)
+	p.level.Store(level)
 	return nil
 }
 
 // Stop 停止
 func (p *mgr) Stop() error {
+	if p.summaryStopChan != nil {
+		close(p.summaryStopChan)
+		p.waitGroupSummary.Wait()
+		p.summaryStopChan = nil
+		stdLimiter.CompareAndSwap(p.limiter, nil)
+	}
 	if p.logChan != nil {
 		// close chan, for range 读完chan会退出.
 		close(p.logChan)
 		// 等待logChan 的for range 退出.
 		p.waitGroupOutPut.Wait()
 	}
-	// 检查是否要关闭文件
-	if len(p.openFiles) > 0 {
-		for i := range p.openFiles {
-			_ = p.openFiles[i].Close()
+	// 关闭文件
+	if p.file != nil {
+		_ = p.file.close()
+		p.file = nil
+	}
+	for _, sink := range p.options.sinks {
+		if err := sink.Close(); err != nil {
+			PrintfErr("sink:%T close failed. err:%v %v", sink, err, xruntime.Location())
 		}
-		p.openFiles = p.openFiles[0:0]
 	}
+	p.options.sinks = nil
 	return nil
 }
 
@@ -221,20 +215,7 @@ func (p *mgr) log(entry *entry, level uint32, v ...any) {
 	entry.withLevel(level).
 		withTime(p.timeMgr.NowTime()).
 		withMessage("", v...)
-	if *p.options.isReportCaller {
-		pc, file, line, ok := runtime.Caller(calldepth2)
-		funcName := xerror.Unknown.Name()
-		if !ok {
-			line = 0
-		} else {
-			fn := runtime.FuncForPC(pc)
-			if fn != nil {
-				funcName = fn.Name()
-			}
-		}
-		entry.withCallerInfo(line, file, funcName)
-	}
-	p.logChan <- entry
+	p.output(entry)
 }
 
 // logf 记录日志
@@ -242,8 +223,17 @@ func (p *mgr) logf(entry *entry, level uint32, format string, v ...any) {
 	entry.withLevel(level).
 		withTime(p.timeMgr.NowTime()).
 		withMessage(format, v...)
+	p.output(entry)
+}
+
+// output 补充调用信息, 限流后写入通道
+func (p *mgr) output(entry *entry) {
+	var pc uintptr
 	if *p.options.isReportCaller {
-		pc, file, line, ok := runtime.Caller(calldepth2)
+		var file string
+		var line int
+		var ok bool
+		pc, file, line, ok = runtime.Caller(calldepth3)
 		funcName := xerror.Unknown.Name()
 		if !ok {
 			line = 0
@@ -254,6 +244,21 @@ func (p *mgr) logf(entry *entry, level uint32, format string, v ...any) {
 			}
 		}
 		entry.withCallerInfo(line, file, funcName)
+	} else if p.limiter != nil {
+		var pcs [1]uintptr
+		runtime.Callers(calldepth3+1, pcs[:])
+		pc = pcs[0]
+	}
+	p.send(entry, pc)
+}
+
+// send 限流后写入通道
+//
+//	pc: 调用位置, 用于限流
+func (p *mgr) send(entry *entry, pc uintptr) {
+	if p.limiter != nil && !p.limiter.allow(pc, entry.level, entry.time) {
+		p.options.entryPoolOptions.put(entry)
+		return
 	}
 	p.logChan <- entry
 }
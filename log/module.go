@@ -10,6 +10,16 @@ type ILog interface {
 	GetLevel() uint32
 	SetLevel(level uint32) error
 	Stop() error
+	// Named 子日志, 有自己的等级, 未设置时继承上级. 名称以 . 分级 e.g.: net.kcp
+	Named(name string) ILog
+	// GetLevelByName 名称为 name 的日志生效的等级, name 为空时为根
+	GetLevelByName(name string) uint32
+	// SetLevelByName 设置名称为 name 的日志等级, name 为空时为根
+	SetLevelByName(name string, level uint32) error
+	// SetLevels 运行时按配置设置等级 e.g.: "net.kcp=trace,server=info,*=warn"
+	SetLevels(spec string) error
+	// ResetLevels 所有子日志恢复继承上级的等级
+	ResetLevels()
 	Trace(v ...any)
 	TraceExtend(ctx context.Context, extendFields ExtendFields, v ...any)
 	Tracef(format string, v ...any)
@@ -0,0 +1,435 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// 子日志, 名称以 . 分级 e.g.: net.kcp 的上级为 net
+//
+//	未设置等级时, 继承上级的等级, 直至根(日志管理器)的等级
+//	与根共用输出(文件, sink, 限流). Stop 无效
+type namedLogger struct {
+	mgr    *mgr
+	name   string
+	parent *namedLogger // nil:上级为根
+	level  atomic.Int64 // 等级, levelInherit:继承
+}
+
+const levelInherit int64 = -1 // 继承上级的等级
+
+// Named 名称为 name 的子日志, name 为空时返回根
+func (p *mgr) Named(name string) ILog {
+	if name == "" {
+		return p
+	}
+	return p.getNamed(name)
+}
+
+// 获取子日志, 不存在时创建(包括上级)
+func (p *mgr) getNamed(name string) *namedLogger {
+	p.namedMu.Lock()
+	defer p.namedMu.Unlock()
+	if p.named == nil {
+		p.named = make(map[string]*namedLogger)
+	}
+	return p.getNamedLocked(name)
+}
+
+func (p *mgr) getNamedLocked(name string) *namedLogger {
+	if v, ok := p.named[name]; ok {
+		return v
+	}
+	v := &namedLogger{
+		mgr:  p,
+		name: name,
+	}
+	v.level.Store(levelInherit)
+	if idx := strings.LastIndexByte(name, '.'); 0 < idx {
+		v.parent = p.getNamedLocked(name[:idx])
+	}
+	p.named[name] = v
+	return v
+}
+
+// GetLevelByName 名称为 name 的日志生效的等级, name 为空时为根
+func (p *mgr) GetLevelByName(name string) uint32 {
+	if name == "" {
+		return p.GetLevel()
+	}
+	return p.getNamed(name).GetLevel()
+}
+
+// SetLevelByName 设置名称为 name 的日志等级, name 为空时为根
+func (p *mgr) SetLevelByName(name string, level uint32) error {
+	if name == "" {
+		return p.SetLevel(level)
+	}
+	return p.getNamed(name).SetLevel(level)
+}
+
+// ResetLevels 所有子日志恢复继承上级的等级
+func (p *mgr) ResetLevels() {
+	p.namedMu.Lock()
+	defer p.namedMu.Unlock()
+	for _, v := range p.named {
+		v.level.Store(levelInherit)
+	}
+}
+
+// SetLevels 按配置设置等级, 全部解析成功后才设置. 用于运行时控制 e.g.: 管理接口, etcd
+//
+//	spec: 名称=等级, 以 , 分隔. 名称 * 为根. 等级 inherit 为恢复继承
+//	e.g.: "net.kcp=trace,server=info,*=warn"
+func (p *mgr) SetLevels(spec string) error {
+	nameLevels, err := parseLevels(spec)
+	if err != nil {
+		return err
+	}
+	for _, v := range nameLevels {
+		if v.name == "" {
+			_ = p.SetLevel(uint32(v.level))
+			continue
+		}
+		p.getNamed(v.name).level.Store(v.level)
+	}
+	return nil
+}
+
+// ParseLevelNames 解析 SetLevels 的配置, 返回其中的名称(根为 LevelRootName), 不设置等级
+func ParseLevelNames(spec string) ([]string, error) {
+	nameLevels, err := parseLevels(spec)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(nameLevels))
+	for _, v := range nameLevels {
+		if v.name == "" {
+			names = append(names, LevelRootName)
+			continue
+		}
+		names = append(names, v.name)
+	}
+	return names, nil
+}
+
+// 名称-等级
+type nameLevel struct {
+	name  string // 空:根
+	level int64  // levelInherit:继承
+}
+
+// 解析 SetLevels 的配置
+func parseLevels(spec string) ([]nameLevel, error) {
+	var nameLevels []nameLevel
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, levelStr, ok := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, errors.WithMessagef(xerror.Param, "item:%v is invalid. %v", item, xruntime.Location())
+		}
+		if name == LevelRootName {
+			name = ""
+		}
+		if strings.EqualFold(strings.TrimSpace(levelStr), LevelInheritName) {
+			if name == "" {
+				return nil, errors.WithMessagef(xerror.Param, "root can not inherit. %v", xruntime.Location())
+			}
+			nameLevels = append(nameLevels, nameLevel{name: name, level: levelInherit})
+			continue
+		}
+		level, err := ParseLevel(levelStr)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "item:%v %v", item, xruntime.Location())
+		}
+		nameLevels = append(nameLevels, nameLevel{name: name, level: int64(level)})
+	}
+	return nameLevels, nil
+}
+
+// GetLevel 生效的等级
+func (p *namedLogger) GetLevel() uint32 {
+	for v := p; v != nil; v = v.parent {
+		if level := v.level.Load(); level != levelInherit {
+			return uint32(level)
+		}
+	}
+	return p.mgr.GetLevel()
+}
+
+// SetLevel 设置本子日志的等级, 下级未设置等级时继承
+func (p *namedLogger) SetLevel(level uint32) error {
+	if level < LevelOff || LevelOn < level {
+		return errors.WithMessagef(xerror.Level, "level is invalid. %v", xruntime.Location())
+	}
+	p.level.Store(int64(level))
+	return nil
+}
+
+// Stop 子日志不可停止, 由根停止
+func (p *namedLogger) Stop() error {
+	return nil
+}
+
+// Named 下级子日志 e.g.: net 的 kcp 为 net.kcp
+func (p *namedLogger) Named(name string) ILog {
+	if name == "" {
+		return p
+	}
+	return p.mgr.getNamed(p.name + "." + name)
+}
+
+func (p *namedLogger) GetLevelByName(name string) uint32 {
+	return p.mgr.GetLevelByName(name)
+}
+
+func (p *namedLogger) SetLevelByName(name string, level uint32) error {
+	return p.mgr.SetLevelByName(name, level)
+}
+
+func (p *namedLogger) ResetLevels() {
+	p.mgr.ResetLevels()
+}
+
+func (p *namedLogger) SetLevels(spec string) error {
+	return p.mgr.SetLevels(spec)
+}
+
+func (p *namedLogger) newEntry() *entry {
+	return p.mgr.newEntry().withName(p.name)
+}
+
+// Trace 踪迹日志
+func (p *namedLogger) Trace(v ...any) {
+	if p.GetLevel() < LevelTrace {
+		return
+	}
+	p.mgr.log(p.newEntry(), LevelTrace, v...)
+}
+
+func (p *namedLogger) TraceExtend(ctx context.Context, extendFields ExtendFields, v ...any) {
+	if p.GetLevel() < LevelTrace {
+		return
+	}
+	element := p.newEntry()
+	element.WithContext(ctx).WithExtendFields(extendFields)
+	p.mgr.log(element, LevelTrace, v...)
+}
+
+// Tracef 踪迹日志
+func (p *namedLogger) Tracef(format string, v ...any) {
+	if p.GetLevel() < LevelTrace {
+		return
+	}
+	p.mgr.logf(p.newEntry(), LevelTrace, format, v...)
+}
+
+func (p *namedLogger) TracefExtend(ctx context.Context, extendFields ExtendFields, format string, v ...any) {
+	if p.GetLevel() < LevelTrace {
+		return
+	}
+	element := p.newEntry()
+	element.WithContext(ctx).WithExtendFields(extendFields)
+	p.mgr.logf(element, LevelTrace, format, v...)
+}
+
+// Debug 调试日志
+func (p *namedLogger) Debug(v ...any) {
+	if p.GetLevel() < LevelDebug {
+		return
+	}
+	p.mgr.log(p.newEntry(), LevelDebug, v...)
+}
+
+func (p *namedLogger) DebugExtend(ctx context.Context, extendFields ExtendFields, v ...any) {
+	if p.GetLevel() < LevelDebug {
+		return
+	}
+	element := p.newEntry()
+	element.WithContext(ctx).WithExtendFields(extendFields)
+	p.mgr.log(element, LevelDebug, v...)
+}
+
+// DebugLazy 调试日志-惰性
+//
+//	等级满足之后才会计算
+func (p *namedLogger) DebugLazy(vFunc func() []any) {
+	if p.GetLevel() < LevelDebug {
+		return
+	}
+	v := vFunc()
+	p.mgr.log(p.newEntry(), LevelDebug, v...)
+}
+
+// Debugf 调试日志
+func (p *namedLogger) Debugf(format string, v ...any) {
+	if p.GetLevel() < LevelDebug {
+		return
+	}
+	p.mgr.logf(p.newEntry(), LevelDebug, format, v...)
+}
+
+func (p *namedLogger) DebugfExtend(ctx context.Context, extendFields ExtendFields, format string, v ...any) {
+	if p.GetLevel() < LevelDebug {
+		return
+	}
+	element := p.newEntry()
+	element.WithContext(ctx).WithExtendFields(extendFields)
+	p.mgr.logf(element, LevelDebug, format, v...)
+}
+
+// DebugfLazy 调试日志-惰性
+//
+//	等级满足之后才会计算
+func (p *namedLogger) DebugfLazy(formatFunc func() (string, []any)) {
+	if p.GetLevel() < LevelDebug {
+		return
+	}
+	format, v := formatFunc()
+	p.mgr.logf(p.newEntry(), LevelDebug, format, v...)
+}
+
+// Info 信息日志
+func (p *namedLogger) Info(v ...any) {
+	if p.GetLevel() < LevelInfo {
+		return
+	}
+	p.mgr.log(p.newEntry(), LevelInfo, v...)
+}
+
+func (p *namedLogger) InfoExtend(ctx context.Context, extendFields ExtendFields, v ...any) {
+	if p.GetLevel() < LevelInfo {
+		return
+	}
+	element := p.newEntry()
+	element.WithContext(ctx).WithExtendFields(extendFields)
+	p.mgr.log(element, LevelInfo, v...)
+}
+
+// Infof 信息日志
+func (p *namedLogger) Infof(format string, v ...any) {
+	if p.GetLevel() < LevelInfo {
+		return
+	}
+	p.mgr.logf(p.newEntry(), LevelInfo, format, v...)
+}
+
+func (p *namedLogger) InfofExtend(ctx context.Context, extendFields ExtendFields, format string, v ...any) {
+	if p.GetLevel() < LevelInfo {
+		return
+	}
+	element := p.newEntry()
+	element.WithContext(ctx).WithExtendFields(extendFields)
+	p.mgr.logf(element, LevelInfo, format, v...)
+}
+
+// Warn 警告日志
+func (p *namedLogger) Warn(v ...any) {
+	if p.GetLevel() < LevelWarn {
+		return
+	}
+	p.mgr.log(p.newEntry(), LevelWarn, v...)
+}
+
+func (p *namedLogger) WarnExtend(ctx context.Context, extendFields ExtendFields, v ...any) {
+	if p.GetLevel() < LevelWarn {
+		return
+	}
+	element := p.newEntry()
+	element.WithContext(ctx).WithExtendFields(extendFields)
+	p.mgr.log(element, LevelWarn, v...)
+}
+
+// Warnf 警告日志
+func (p *namedLogger) Warnf(format string, v ...any) {
+	if p.GetLevel() < LevelWarn {
+		return
+	}
+	p.mgr.logf(p.newEntry(), LevelWarn, format, v...)
+}
+
+func (p *namedLogger) WarnfExtend(ctx context.Context, extendFields ExtendFields, format string, v ...any) {
+	if p.GetLevel() < LevelWarn {
+		return
+	}
+	element := p.newEntry()
+	element.WithContext(ctx).WithExtendFields(extendFields)
+	p.mgr.logf(element, LevelWarn, format, v...)
+}
+
+// Error 错误日志
+func (p *namedLogger) Error(v ...any) {
+	if p.GetLevel() < LevelError {
+		return
+	}
+	p.mgr.log(p.newEntry(), LevelError, v...)
+}
+
+func (p *namedLogger) ErrorExtend(ctx context.Context, extendFields ExtendFields, v ...any) {
+	if p.GetLevel() < LevelError {
+		return
+	}
+	element := p.newEntry()
+	element.WithContext(ctx).WithExtendFields(extendFields)
+	p.mgr.log(element, LevelError, v...)
+}
+
+// Errorf 错误日志
+func (p *namedLogger) Errorf(format string, v ...any) {
+	if p.GetLevel() < LevelError {
+		return
+	}
+	p.mgr.logf(p.newEntry(), LevelError, format, v...)
+}
+
+func (p *namedLogger) ErrorfExtend(ctx context.Context, extendFields ExtendFields, format string, v ...any) {
+	if p.GetLevel() < LevelError {
+		return
+	}
+	element := p.newEntry()
+	element.WithContext(ctx).WithExtendFields(extendFields)
+	p.mgr.logf(element, LevelError, format, v...)
+}
+
+// Fatal 致命日志
+func (p *namedLogger) Fatal(v ...any) {
+	if p.GetLevel() < LevelFatal {
+		return
+	}
+	p.mgr.log(p.newEntry(), LevelFatal, v...)
+}
+
+func (p *namedLogger) FatalExtend(ctx context.Context, extendFields ExtendFields, v ...any) {
+	if p.GetLevel() < LevelFatal {
+		return
+	}
+	element := p.newEntry()
+	element.WithContext(ctx).WithExtendFields(extendFields)
+	p.mgr.log(element, LevelFatal, v...)
+}
+
+// Fatalf 致命日志
+func (p *namedLogger) Fatalf(format string, v ...any) {
+	if p.GetLevel() < LevelFatal {
+		return
+	}
+	p.mgr.logf(p.newEntry(), LevelFatal, format, v...)
+}
+
+func (p *namedLogger) FatalfExtend(ctx context.Context, extendFields ExtendFields, format string, v ...any) {
+	if p.GetLevel() < LevelFatal {
+		return
+	}
+	element := p.newEntry()
+	element.WithContext(ctx).WithExtendFields(extendFields)
+	p.mgr.logf(element, LevelFatal, format, v...)
+}
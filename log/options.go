@@ -1,23 +1,36 @@
 package log
 
 import (
+	xclock "github.com/75912001/xlib/clock"
 	xcontrol "github.com/75912001/xlib/control"
+	xerror "github.com/75912001/xlib/error"
 	xruntime "github.com/75912001/xlib/runtime"
 	"github.com/pkg/errors"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // options contains options to configure a server mgrInstance. Each option can be set through setter functions. See
 // documentation for each setter function for an explanation of the option.
 type options struct {
-	level            *uint32           // 日志等级允许的最小等级 [default]: LevelOn
-	absPath          *string           // 日志绝对路径 [default]: 当前执行的程序-绝对路径,指向启动当前进程的可执行文件-目录路径. e.g.:absPath/log
-	isReportCaller   *bool             // 是否打印调用信息 [default]: true
-	namePrefix       *string           // 日志名 前缀 [default]: 当前执行的程序名称
-	isWriteFile      *bool             // 是否写文件 [default]: true
-	entryPoolOptions *entryPoolOptions // entry的内存池选项 [default]: newEntryPoolOptions()
-	levelSubscribe   *levelSubscribe   // 日志结果的回调. 该执行过程在 log 的写过程中, 位于写 log 的 goroutine 中 [default]: nil
+	level            *uint32               // 日志等级允许的最小等级 [default]: LevelOn
+	absPath          *string               // 日志绝对路径 [default]: 当前执行的程序-绝对路径,指向启动当前进程的可执行文件-目录路径. e.g.:absPath/log
+	isReportCaller   *bool                 // 是否打印调用信息 [default]: true
+	namePrefix       *string               // 日志名 前缀 [default]: 当前执行的程序名称
+	isWriteFile      *bool                 // 是否写文件 [default]: true
+	entryPoolOptions *entryPoolOptions     // entry的内存池选项 [default]: newEntryPoolOptions()
+	levelSubscribe   *levelSubscribe       // 日志结果的回调. 该执行过程在 log 的写过程中, 位于写 log 的 goroutine 中 [default]: nil
+	clock            xclock.IClock         // 时钟, 日志时间及文件分割 [default]: xclock.GClock
+	format           *uint32               // 输出格式 [default]: FormatText
+	maxSize          *int64                // 单个日志文件最大字节数, 超过时切换到新的分段. 0:不限制 [default]: 0
+	maxBackups       *uint32               // 历史日志文件保留数量(normal/error 分别计数, 不含当前文件). 0:不限制 [default]: 0
+	maxAge           *time.Duration        // 历史日志文件保留时长. 0:不限制 [default]: 0
+	compress         *bool                 // 是否 gzip 压缩历史日志文件 [default]: false
+	minDiskFree      *uint64               // 磁盘剩余空间低于该值时暂停写文件(丢弃日志)-字节. 0:不检查 [default]: 0
+	sinks            []ISink               // 额外的日志输出, 各自过滤等级/格式. Stop 时关闭 [default]: nil
+	rateLimits       map[uint32]*rateLimit // 按等级限流, 按调用位置计数. key:等级 [default]: nil, 不限流
+	rateLimitSummary *time.Duration        // 限流抑制数量的汇总间隔 [default]: 10s
 }
 
 // NewOptions 新的Options
@@ -64,6 +77,73 @@ func (p *options) WithLevelCallBack(callback xcontrol.ICallBack, subLevel ...uin
 	return p
 }
 
+func (p *options) WithClock(clock xclock.IClock) *options {
+	p.clock = clock
+	return p
+}
+
+// WithFormat 输出格式 FormatText/FormatJSON
+func (p *options) WithFormat(format uint32) *options {
+	p.format = &format
+	return p
+}
+
+// WithMaxSize 单个日志文件最大字节数
+func (p *options) WithMaxSize(maxSize int64) *options {
+	p.maxSize = &maxSize
+	return p
+}
+
+// WithMaxBackups 历史日志文件保留数量
+func (p *options) WithMaxBackups(maxBackups uint32) *options {
+	p.maxBackups = &maxBackups
+	return p
+}
+
+// WithMaxAge 历史日志文件保留时长
+func (p *options) WithMaxAge(maxAge time.Duration) *options {
+	p.maxAge = &maxAge
+	return p
+}
+
+// WithCompress 是否 gzip 压缩历史日志文件
+func (p *options) WithCompress(compress bool) *options {
+	p.compress = &compress
+	return p
+}
+
+// WithMinDiskFree 磁盘剩余空间低于该值时暂停写文件-字节. 0:不检查 e.g.: 100MB
+func (p *options) WithMinDiskFree(minDiskFree uint64) *options {
+	p.minDiskFree = &minDiskFree
+	return p
+}
+
+// WithSinks 额外的日志输出
+func (p *options) WithSinks(sinks ...ISink) *options {
+	p.sinks = sinks
+	return p
+}
+
+// WithRateLimit 等级 level 的限流
+//
+//	同一调用位置, 每秒前 first 条输出, 之后每 thereafter 条输出 1 条. thereafter 为 0 时不输出
+func (p *options) WithRateLimit(level uint32, first uint32, thereafter uint32) *options {
+	if p.rateLimits == nil {
+		p.rateLimits = make(map[uint32]*rateLimit)
+	}
+	p.rateLimits[level] = &rateLimit{
+		first:      first,
+		thereafter: thereafter,
+	}
+	return p
+}
+
+// WithRateLimitSummary 限流抑制数量的汇总间隔
+func (p *options) WithRateLimitSummary(rateLimitSummary time.Duration) *options {
+	p.rateLimitSummary = &rateLimitSummary
+	return p
+}
+
 // merge combines the given *options into a single *options in a last one wins fashion.
 //
 //	The specified options are merged with the existing options on the server, with the specified options taking
@@ -98,6 +178,39 @@ func (p *options) merge(opts ...*options) *options {
 		if opt.levelSubscribe != nil {
 			p.levelSubscribe = opt.levelSubscribe
 		}
+		if opt.clock != nil {
+			p.clock = opt.clock
+		}
+		if opt.format != nil {
+			p.format = opt.format
+		}
+		if opt.maxSize != nil {
+			p.maxSize = opt.maxSize
+		}
+		if opt.maxBackups != nil {
+			p.maxBackups = opt.maxBackups
+		}
+		if opt.maxAge != nil {
+			p.maxAge = opt.maxAge
+		}
+		if opt.compress != nil {
+			p.compress = opt.compress
+		}
+		if opt.minDiskFree != nil {
+			p.minDiskFree = opt.minDiskFree
+		}
+		if opt.sinks != nil {
+			p.sinks = opt.sinks
+		}
+		for level, limit := range opt.rateLimits {
+			if p.rateLimits == nil {
+				p.rateLimits = make(map[uint32]*rateLimit)
+			}
+			p.rateLimits[level] = limit
+		}
+		if opt.rateLimitSummary != nil {
+			p.rateLimitSummary = opt.rateLimitSummary
+		}
 	}
 	return p
 }
@@ -133,6 +246,62 @@ func (p *options) configure() error {
 	if p.entryPoolOptions == nil {
 		p.entryPoolOptions = newEntryPoolOptions()
 	}
+	if p.clock == nil {
+		p.clock = xclock.GClock
+	}
+	if p.format == nil {
+		var format = FormatText
+		p.format = &format
+	}
+	if *p.format != FormatText && *p.format != FormatJSON {
+		return errors.WithMessagef(xerror.Param, "format:%v is invalid. %v", *p.format, xruntime.Location())
+	}
+	if p.maxSize == nil {
+		var maxSize int64
+		p.maxSize = &maxSize
+	}
+	if *p.maxSize < 0 {
+		return errors.WithMessagef(xerror.Param, "maxSize:%v is invalid. %v", *p.maxSize, xruntime.Location())
+	}
+	if p.maxBackups == nil {
+		var maxBackups uint32
+		p.maxBackups = &maxBackups
+	}
+	if p.maxAge == nil {
+		var maxAge time.Duration
+		p.maxAge = &maxAge
+	}
+	if *p.maxAge < 0 {
+		return errors.WithMessagef(xerror.Param, "maxAge:%v is invalid. %v", *p.maxAge, xruntime.Location())
+	}
+	if p.compress == nil {
+		var compress bool
+		p.compress = &compress
+	}
+	if p.minDiskFree == nil {
+		var minDiskFree uint64
+		p.minDiskFree = &minDiskFree
+	}
+	for _, sink := range p.sinks {
+		if sink == nil {
+			return errors.WithMessagef(xerror.Param, "sink is nil. %v", xruntime.Location())
+		}
+	}
+	for level, limit := range p.rateLimits {
+		if level < LevelFatal || LevelTrace < level {
+			return errors.WithMessagef(xerror.Level, "rate limit level:%v is invalid. %v", level, xruntime.Location())
+		}
+		if limit.first == 0 && limit.thereafter == 0 {
+			return errors.WithMessagef(xerror.Param, "rate limit level:%v first and thereafter are 0. %v", level, xruntime.Location())
+		}
+	}
+	if p.rateLimitSummary == nil {
+		rateLimitSummary := 10 * time.Second
+		p.rateLimitSummary = &rateLimitSummary
+	}
+	if *p.rateLimitSummary <= 0 {
+		return errors.WithMessagef(xerror.Param, "rateLimitSummary:%v is invalid. %v", *p.rateLimitSummary, xruntime.Location())
+	}
 	if err := p.entryPoolOptions.configure(); err != nil {
 		return errors.WithMessagef(err, "configure entry pool options failed. %v", xruntime.Location())
 	}
@@ -0,0 +1,100 @@
+package log
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	xclock "github.com/75912001/xlib/clock"
+)
+
+// 日志限流, 按调用位置(pc)计数
+//
+//	每个周期(rateLimitPeriod)内, 前 first 条输出, 之后每 thereafter 条输出 1 条, 其余抑制
+//	抑制的数量定期汇总输出 e.g.: suppressed 100 similar messages
+type rateLimiter struct {
+	limits    [LevelOn]*rateLimit // key:等级, nil:不限流
+	callsites sync.Map            // key:pc, val:*callsite
+	clock     xclock.IClock       // 时钟, 与日志管理器一致. 用于标准输出/标准错误
+}
+
+// 等级的限流配置
+type rateLimit struct {
+	first      uint32 // 每个周期内, 前 first 条输出
+	thereafter uint32 // 超过 first 后, 每 thereafter 条输出 1 条. 0:不输出
+}
+
+// 调用位置的计数
+type callsite struct {
+	level      uint32
+	period     atomic.Int64  // 当前周期
+	count      atomic.Uint64 // 当前周期内的数量
+	suppressed atomic.Uint64 // 未汇总的抑制数量
+}
+
+// 新的限流, 没有限流配置时返回 nil
+func newRateLimiter(limits map[uint32]*rateLimit, clock xclock.IClock) *rateLimiter {
+	if len(limits) == 0 {
+		return nil
+	}
+	p := &rateLimiter{
+		clock: clock,
+	}
+	for level, limit := range limits {
+		p.limits[level] = limit
+	}
+	return p
+}
+
+// 是否允许输出
+func (p *rateLimiter) allow(pc uintptr, level uint32, nowTime time.Time) bool {
+	limit := p.limits[level]
+	if limit == nil {
+		return true
+	}
+	v, ok := p.callsites.Load(pc)
+	if !ok {
+		v, _ = p.callsites.LoadOrStore(pc, &callsite{level: level})
+	}
+	c := v.(*callsite)
+	period := nowTime.UnixNano() / int64(rateLimitPeriod)
+	if old := c.period.Load(); old != period && c.period.CompareAndSwap(old, period) {
+		c.count.Store(0)
+	}
+	n := c.count.Add(1)
+	if n <= uint64(limit.first) {
+		return true
+	}
+	if limit.thereafter != 0 && (n-uint64(limit.first))%uint64(limit.thereafter) == 0 {
+		return true
+	}
+	c.suppressed.Add(1)
+	return false
+}
+
+// 汇总抑制的数量, 并清零
+func (p *rateLimiter) summarize(fn func(pc uintptr, level uint32, suppressed uint64)) {
+	p.callsites.Range(func(key, value any) bool {
+		c := value.(*callsite)
+		if suppressed := c.suppressed.Swap(0); suppressed != 0 {
+			fn(key.(uintptr), c.level, suppressed)
+		}
+		return true
+	})
+}
+
+// 调用位置的信息
+func callsiteInfo(pc uintptr) (line int, file string, funcName string) {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return frame.Line, frame.File, frame.Function
+}
+
+// 标准输出/标准错误的限流, 使用最近启动的日志管理器的限流配置
+var stdLimiter atomic.Pointer[rateLimiter]
+
+// 标准输出/标准错误 是否允许输出
+func stdAllow(pc uintptr, level uint32) bool {
+	limiter := stdLimiter.Load()
+	return limiter == nil || limiter.allow(pc, level, limiter.clock.Now())
+}
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"os"
+	"time"
+
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// ConsoleSink 输出到控制台. LevelError 及以上输出到 os.Stderr, 其余输出到 os.Stdout
+type ConsoleSink struct {
+	options *sinkOptions
+}
+
+// NewConsoleSink 新的 ConsoleSink
+func NewConsoleSink(opts ...*sinkOptions) (*ConsoleSink, error) {
+	opt := NewSinkOptions().merge(opts...)
+	if err := opt.configure(); err != nil {
+		return nil, errors.WithMessagef(err, "configure failed. %v", xruntime.Location())
+	}
+	return &ConsoleSink{
+		options: opt,
+	}, nil
+}
+
+func (p *ConsoleSink) Level() uint32 {
+	return *p.options.level
+}
+
+func (p *ConsoleSink) Format() uint32 {
+	return *p.options.format
+}
+
+func (p *ConsoleSink) Write(level uint32, _ time.Time, line []byte) error {
+	if level <= LevelError {
+		_, err := os.Stderr.Write(line)
+		return err
+	}
+	_, err := os.Stdout.Write(line)
+	return err
+}
+
+func (p *ConsoleSink) Close() error {
+	return nil
+}
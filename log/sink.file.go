@@ -0,0 +1,48 @@
+package log
+
+import (
+	"os"
+	"time"
+
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// FileSink 输出到单个文件, 不切换/不清理
+type FileSink struct {
+	options *sinkOptions
+	file    *os.File
+}
+
+// NewFileSink 新的 FileSink, 追加写入 fileName
+func NewFileSink(fileName string, opts ...*sinkOptions) (*FileSink, error) {
+	opt := NewSinkOptions().merge(opts...)
+	if err := opt.configure(); err != nil {
+		return nil, errors.WithMessagef(err, "configure failed. %v", xruntime.Location())
+	}
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.FileMode(0644))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "open file:%v failed. %v", fileName, xruntime.Location())
+	}
+	return &FileSink{
+		options: opt,
+		file:    file,
+	}, nil
+}
+
+func (p *FileSink) Level() uint32 {
+	return *p.options.level
+}
+
+func (p *FileSink) Format() uint32 {
+	return *p.options.format
+}
+
+func (p *FileSink) Write(_ uint32, _ time.Time, line []byte) error {
+	_, err := p.file.Write(line)
+	return err
+}
+
+func (p *FileSink) Close() error {
+	return p.file.Close()
+}
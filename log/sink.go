@@ -0,0 +1,118 @@
+package log
+
+import (
+	"time"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// ISink 日志输出
+//
+//	在日志协程中调用, 不可长时间阻塞
+type ISink interface {
+	Level() uint32  // 允许输出的最小等级, 同 options.level
+	Format() uint32 // 输出格式 FormatText/FormatJSON
+	// Write 输出一行日志
+	//	line: 已按 Format 格式化, 以换行结尾. [note]: 返回后不可再引用
+	Write(level uint32, nowTime time.Time, line []byte) error
+	Close() error
+}
+
+// sink 选项
+type sinkOptions struct {
+	level  *uint32 // 允许输出的最小等级 [default]: LevelOn
+	format *uint32 // 输出格式 [default]: FormatText
+}
+
+// NewSinkOptions 新的 sinkOptions
+func NewSinkOptions() *sinkOptions {
+	return &sinkOptions{}
+}
+
+func (p *sinkOptions) WithLevel(level uint32) *sinkOptions {
+	p.level = &level
+	return p
+}
+
+func (p *sinkOptions) WithFormat(format uint32) *sinkOptions {
+	p.format = &format
+	return p
+}
+
+func (p *sinkOptions) merge(opts ...*sinkOptions) *sinkOptions {
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.level != nil {
+			p.level = opt.level
+		}
+		if opt.format != nil {
+			p.format = opt.format
+		}
+	}
+	return p
+}
+
+func (p *sinkOptions) configure() error {
+	if p.level == nil {
+		var level = LevelOn
+		p.level = &level
+	}
+	if LevelOn < *p.level {
+		return errors.WithMessagef(xerror.Level, "level:%v is invalid. %v", *p.level, xruntime.Location())
+	}
+	if p.format == nil {
+		var format = FormatText
+		p.format = &format
+	}
+	if *p.format != FormatText && *p.format != FormatJSON {
+		return errors.WithMessagef(xerror.Param, "format:%v is invalid. %v", *p.format, xruntime.Location())
+	}
+	return nil
+}
+
+// 按 sink 的格式输出日志, 每种格式只格式化一次 [日志协程]
+//
+//	e.outBytes: 已按 format 格式化, 以换行结尾
+func writeSinks(sinks []ISink, e *entry, format uint32) {
+	var pending bool // 有其他格式的 sink
+	for _, sink := range sinks {
+		if sink.Level() < e.level {
+			continue
+		}
+		if sink.Format() != format {
+			pending = true
+			continue
+		}
+		writeSink(sink, e)
+	}
+	if !pending {
+		return
+	}
+	for _, other := range [...]uint32{FormatText, FormatJSON} {
+		if other == format {
+			continue
+		}
+		formatted := false
+		for _, sink := range sinks {
+			if sink.Level() < e.level || sink.Format() != other {
+				continue
+			}
+			if !formatted {
+				formatEntry(e, other)
+				e.outBytes = append(e.outBytes, '\n')
+				formatted = true
+			}
+			writeSink(sink, e)
+		}
+	}
+}
+
+func writeSink(sink ISink, e *entry) {
+	if err := sink.Write(e.level, e.time, e.outBytes); err != nil {
+		PrintfErr("sink:%T write failed. err:%v %v", sink, err, xruntime.Location())
+	}
+}
@@ -0,0 +1,267 @@
+package log
+
+import (
+	"net"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	xerror "github.com/75912001/xlib/error"
+	xpool "github.com/75912001/xlib/pool"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// NetSink 通过 TCP/UDP 发送到本地收集器
+//
+//	Write 只放入有界缓冲, 不阻塞日志协程. 缓冲已满时丢弃, 计数
+//	发送协程中连接/重连, 发送失败的日志丢弃, 计数
+//	TCP: 按行连续发送. UDP: 一行一个数据报
+type NetSink struct {
+	options   *netSinkOptions
+	network   string
+	address   string
+	lineChan  chan []byte // 待发送的日志, 数据来自 xpool.GetBytes
+	closeChan chan struct{}
+	closeOnce sync.Once
+	waitGroup sync.WaitGroup
+	dropped   atomic.Uint64
+	conn      net.Conn // 发送协程中使用
+}
+
+// NewNetSink 新的 NetSink, 并开始发送协程
+//
+//	network: tcp, tcp4, tcp6, udp, udp4, udp6
+//	address: e.g.: 127.0.0.1:5170
+func NewNetSink(network string, address string, opts ...*netSinkOptions) (*NetSink, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+	default:
+		return nil, errors.WithMessagef(xerror.Param, "network:%v is invalid. %v", network, xruntime.Location())
+	}
+	opt := NewNetSinkOptions().merge(opts...)
+	if err := opt.configure(); err != nil {
+		return nil, errors.WithMessagef(err, "configure failed. %v", xruntime.Location())
+	}
+	p := &NetSink{
+		options:   opt,
+		network:   network,
+		address:   address,
+		lineChan:  make(chan []byte, *opt.bufferSize),
+		closeChan: make(chan struct{}),
+	}
+	p.waitGroup.Add(1)
+	go func() {
+		defer func() {
+			if xruntime.IsRelease() {
+				if err := recover(); err != nil {
+					PrintErr(xerror.GoroutinePanic, err, string(debug.Stack()))
+				}
+			}
+			p.waitGroup.Done()
+		}()
+		p.doSend()
+	}()
+	return p, nil
+}
+
+func (p *NetSink) Level() uint32 {
+	return *p.options.level
+}
+
+func (p *NetSink) Format() uint32 {
+	return *p.options.format
+}
+
+// Write 放入缓冲, 缓冲已满时丢弃
+func (p *NetSink) Write(_ uint32, _ time.Time, line []byte) error {
+	buf := xpool.GetBytes(uint32(len(line)))
+	copy(buf, line)
+	select {
+	case p.lineChan <- buf:
+	default:
+		xpool.PutBytes(buf)
+		p.dropped.Add(1)
+	}
+	return nil
+}
+
+// Dropped 丢弃的日志数量
+func (p *NetSink) Dropped() uint64 {
+	return p.dropped.Load()
+}
+
+// Close 停止发送协程. 缓冲中剩余的日志在 closeTimeout 内尝试发送, 之后丢弃
+func (p *NetSink) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.closeChan)
+		p.waitGroup.Wait()
+	})
+	return nil
+}
+
+func (p *NetSink) doSend() {
+	defer func() {
+		if p.conn != nil {
+			_ = p.conn.Close()
+			p.conn = nil
+		}
+	}()
+	for {
+		select {
+		case line := <-p.lineChan:
+			p.send(line)
+		case <-p.closeChan:
+			deadline := time.Now().Add(*p.options.closeTimeout)
+			for {
+				select {
+				case line := <-p.lineChan:
+					if time.Now().After(deadline) {
+						xpool.PutBytes(line)
+						p.dropped.Add(1)
+						continue
+					}
+					p.send(line)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// 发送一行, 未连接时连接, 连接失败时等待 reconnectInterval 后重试. 关闭时不再重试
+func (p *NetSink) send(line []byte) {
+	defer xpool.PutBytes(line)
+	for p.conn == nil {
+		conn, err := net.DialTimeout(p.network, p.address, *p.options.dialTimeout)
+		if err == nil {
+			p.conn = conn
+			break
+		}
+		select {
+		case <-time.After(*p.options.reconnectInterval):
+		case <-p.closeChan:
+			p.dropped.Add(1)
+			return
+		}
+	}
+	_ = p.conn.SetWriteDeadline(time.Now().Add(*p.options.writeTimeout))
+	if _, err := p.conn.Write(line); err != nil {
+		_ = p.conn.Close()
+		p.conn = nil
+		p.dropped.Add(1)
+	}
+}
+
+// NetSink 选项
+type netSinkOptions struct {
+	level             *uint32        // 允许输出的最小等级 [default]: LevelOn
+	format            *uint32        // 输出格式 [default]: FormatText
+	bufferSize        *uint32        // 缓冲的日志数量 [default]: netSinkBufferSize
+	dialTimeout       *time.Duration // 连接超时 [default]: 3s
+	writeTimeout      *time.Duration // 发送超时 [default]: 3s
+	reconnectInterval *time.Duration // 重连间隔 [default]: 1s
+	closeTimeout      *time.Duration // 关闭时, 发送剩余日志的最长时间 [default]: 3s
+}
+
+// NewNetSinkOptions 新的 netSinkOptions
+func NewNetSinkOptions() *netSinkOptions {
+	return &netSinkOptions{}
+}
+
+func (p *netSinkOptions) WithLevel(level uint32) *netSinkOptions {
+	p.level = &level
+	return p
+}
+
+func (p *netSinkOptions) WithFormat(format uint32) *netSinkOptions {
+	p.format = &format
+	return p
+}
+
+func (p *netSinkOptions) WithBufferSize(bufferSize uint32) *netSinkOptions {
+	p.bufferSize = &bufferSize
+	return p
+}
+
+func (p *netSinkOptions) WithDialTimeout(dialTimeout time.Duration) *netSinkOptions {
+	p.dialTimeout = &dialTimeout
+	return p
+}
+
+func (p *netSinkOptions) WithWriteTimeout(writeTimeout time.Duration) *netSinkOptions {
+	p.writeTimeout = &writeTimeout
+	return p
+}
+
+func (p *netSinkOptions) WithReconnectInterval(reconnectInterval time.Duration) *netSinkOptions {
+	p.reconnectInterval = &reconnectInterval
+	return p
+}
+
+func (p *netSinkOptions) WithCloseTimeout(closeTimeout time.Duration) *netSinkOptions {
+	p.closeTimeout = &closeTimeout
+	return p
+}
+
+func (p *netSinkOptions) merge(opts ...*netSinkOptions) *netSinkOptions {
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.level != nil {
+			p.level = opt.level
+		}
+		if opt.format != nil {
+			p.format = opt.format
+		}
+		if opt.bufferSize != nil {
+			p.bufferSize = opt.bufferSize
+		}
+		if opt.dialTimeout != nil {
+			p.dialTimeout = opt.dialTimeout
+		}
+		if opt.writeTimeout != nil {
+			p.writeTimeout = opt.writeTimeout
+		}
+		if opt.reconnectInterval != nil {
+			p.reconnectInterval = opt.reconnectInterval
+		}
+		if opt.closeTimeout != nil {
+			p.closeTimeout = opt.closeTimeout
+		}
+	}
+	return p
+}
+
+func (p *netSinkOptions) configure() error {
+	sinkOpt := NewSinkOptions()
+	sinkOpt.level, sinkOpt.format = p.level, p.format
+	if err := sinkOpt.configure(); err != nil {
+		return errors.WithMessagef(err, "configure failed. %v", xruntime.Location())
+	}
+	p.level, p.format = sinkOpt.level, sinkOpt.format
+	if p.bufferSize == nil {
+		var bufferSize uint32 = netSinkBufferSize
+		p.bufferSize = &bufferSize
+	}
+	if p.dialTimeout == nil {
+		dialTimeout := 3 * time.Second
+		p.dialTimeout = &dialTimeout
+	}
+	if p.writeTimeout == nil {
+		writeTimeout := 3 * time.Second
+		p.writeTimeout = &writeTimeout
+	}
+	if p.reconnectInterval == nil {
+		reconnectInterval := time.Second
+		p.reconnectInterval = &reconnectInterval
+	}
+	if p.closeTimeout == nil {
+		closeTimeout := 3 * time.Second
+		p.closeTimeout = &closeTimeout
+	}
+	return nil
+}
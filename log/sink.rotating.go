@@ -0,0 +1,49 @@
+package log
+
+import (
+	"time"
+
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// RotatingFileSink 输出到按刻度/大小切换的日志文件, 与默认的日志文件相同
+//
+//	使用 options 中的 level, format, absPath, namePrefix, maxSize, maxBackups, maxAge, compress, minDiskFree
+//	[note]: namePrefix 不可与其他日志相同
+type RotatingFileSink struct {
+	options *options
+	file    *rotatingFile
+}
+
+// NewRotatingFileSink 新的 RotatingFileSink
+func NewRotatingFileSink(opts ...*options) (*RotatingFileSink, error) {
+	opt := NewOptions().merge(opts...)
+	if err := opt.configure(); err != nil {
+		return nil, errors.WithMessagef(err, "configure failed. %v", xruntime.Location())
+	}
+	file, err := newRotatingFile(opt, opt.clock.Now(), nil)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "new rotating file failed. %v", xruntime.Location())
+	}
+	return &RotatingFileSink{
+		options: opt,
+		file:    file,
+	}, nil
+}
+
+func (p *RotatingFileSink) Level() uint32 {
+	return *p.options.level
+}
+
+func (p *RotatingFileSink) Format() uint32 {
+	return *p.options.format
+}
+
+func (p *RotatingFileSink) Write(level uint32, nowTime time.Time, line []byte) error {
+	return p.file.write(level, nowTime, line)
+}
+
+func (p *RotatingFileSink) Close() error {
+	return p.file.close()
+}
@@ -0,0 +1,220 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// SlogHandler slog.Handler, 输出到日志管理器(或子日志)
+//
+//	与日志管理器共用输出(文件, sink, 限流), 等级使用日志管理器(或子日志)的等级
+//	TID/UID 从 ctx 中获取. 属性转换为扩展字段, 分组以 . 连接 e.g.: req.id
+//	时间使用 record.Time, 零值时为当前时间
+//	e.g.: slog.SetDefault(slog.New(xlog.NewSlogHandler(xlog.GLog)))
+type SlogHandler struct {
+	logger ILog
+	group  string       // 分组前缀 e.g.: req.
+	fields ExtendFields // WithAttrs 的属性, 已加上分组前缀
+}
+
+// NewSlogHandler 新的 SlogHandler
+//
+//	logger: 日志管理器或子日志. 其他 ILog 的实现, 通过 *Extend 方法输出, 调用信息为 SlogHandler
+func NewSlogHandler(logger ILog) *SlogHandler {
+	return &SlogHandler{
+		logger: logger,
+	}
+}
+
+func (p *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return SlogLevel2Level(level) <= p.logger.GetLevel()
+}
+
+func (p *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var fields ExtendFields
+	if cnt := len(p.fields) + record.NumAttrs()*2; cnt != 0 {
+		fields = make(ExtendFields, len(p.fields), cnt)
+		copy(fields, p.fields)
+		record.Attrs(func(attr slog.Attr) bool {
+			fields = appendAttr(fields, p.group, attr)
+			return true
+		})
+	}
+	logAttrs(ctx, p.logger, SlogLevel2Level(record.Level), record.Time, record.PC, record.Message, fields)
+	return nil
+}
+
+func (p *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return p
+	}
+	h := *p
+	h.fields = make(ExtendFields, len(p.fields), len(p.fields)+len(attrs)*2)
+	copy(h.fields, p.fields)
+	for _, attr := range attrs {
+		h.fields = appendAttr(h.fields, p.group, attr)
+	}
+	return &h
+}
+
+func (p *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return p
+	}
+	h := *p
+	h.group = p.group + name + "."
+	return &h
+}
+
+// SlogLevel2Level slog 等级转换为日志等级
+//
+//	低于 slog.LevelDebug 为 LevelTrace, 高于 slog.LevelError 为 LevelFatal
+func SlogLevel2Level(level slog.Level) uint32 {
+	switch {
+	case slog.LevelError+4 <= level:
+		return LevelFatal
+	case slog.LevelError <= level:
+		return LevelError
+	case slog.LevelWarn <= level:
+		return LevelWarn
+	case slog.LevelInfo <= level:
+		return LevelInfo
+	case slog.LevelDebug <= level:
+		return LevelDebug
+	default:
+		return LevelTrace
+	}
+}
+
+// Level2SlogLevel 日志等级转换为 slog 等级
+func Level2SlogLevel(level uint32) slog.Level {
+	switch level {
+	case LevelFatal:
+		return slog.LevelError + 4
+	case LevelError:
+		return slog.LevelError
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelDebug:
+		return slog.LevelDebug
+	default:
+		return slog.LevelDebug - 4
+	}
+}
+
+// ExtendFieldsFromAttrs slog 属性转换为扩展字段, 分组以 . 连接 e.g.: req.id
+func ExtendFieldsFromAttrs(attrs ...slog.Attr) ExtendFields {
+	fields := make(ExtendFields, 0, len(attrs)*2)
+	for _, attr := range attrs {
+		fields = appendAttr(fields, "", attr)
+	}
+	return fields
+}
+
+// LogAttrs 以 slog 属性记录日志
+//
+//	e.g.: xlog.LogAttrs(ctx, xlog.GLog, xlog.LevelInfo, "login", slog.Uint64(xlog.UserIDKey, uid), slog.String("ip", ip))
+func LogAttrs(ctx context.Context, logger ILog, level uint32, msg string, attrs ...slog.Attr) {
+	if logger.GetLevel() < level {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(calldepth2, pcs[:]) // 跳过 runtime.Callers, LogAttrs
+	logAttrs(ctx, logger, level, time.Time{}, pcs[0], msg, ExtendFieldsFromAttrs(attrs...))
+}
+
+// 记录日志
+//
+//	t: 日志时间. 零值:当前时间. 其他 ILog 的实现, 忽略
+//	pc: 调用位置. 0:未知
+func logAttrs(ctx context.Context, logger ILog, level uint32, t time.Time, pc uintptr, msg string, fields ExtendFields) {
+	var m *mgr
+	var element *entry
+	switch v := logger.(type) {
+	case *mgr:
+		m, element = v, v.newEntry()
+	case *namedLogger:
+		m, element = v.mgr, v.newEntry()
+	default:
+		logExtend(ctx, logger, level, msg, fields)
+		return
+	}
+	if t.IsZero() {
+		t = m.timeMgr.NowTime()
+	}
+	element.withLevel(level).
+		withTime(t).
+		withMessage("", msg).
+		WithContext(ctx)
+	element.extendFields = fields
+	if *m.options.isReportCaller && pc != 0 {
+		element.withCallerInfo(callsiteInfo(pc))
+	}
+	m.send(element, pc)
+}
+
+// 通过 *Extend 方法记录日志
+func logExtend(ctx context.Context, logger ILog, level uint32, msg string, fields ExtendFields) {
+	switch level {
+	case LevelFatal:
+		logger.FatalExtend(ctx, fields, msg)
+	case LevelError:
+		logger.ErrorExtend(ctx, fields, msg)
+	case LevelWarn:
+		logger.WarnExtend(ctx, fields, msg)
+	case LevelInfo:
+		logger.InfoExtend(ctx, fields, msg)
+	case LevelDebug:
+		logger.DebugExtend(ctx, fields, msg)
+	default:
+		logger.TraceExtend(ctx, fields, msg)
+	}
+}
+
+// 添加属性到扩展字段, 分组展开
+func appendAttr(fields ExtendFields, prefix string, attr slog.Attr) ExtendFields {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) { // 忽略空属性
+		return fields
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, v := range attr.Value.Group() {
+			fields = appendAttr(fields, prefix, v)
+		}
+		return fields
+	}
+	key := prefix + attr.Key
+	return append(fields, key, attrValue(key, attr.Value))
+}
+
+// 属性值转换为对应类型的值
+func attrValue(key string, value slog.Value) any {
+	switch value.Kind() {
+	case slog.KindString:
+		return value.String()
+	case slog.KindInt64:
+		if key == UserIDKey && 0 <= value.Int64() { // UID 为 uint64
+			return uint64(value.Int64())
+		}
+		return value.Int64()
+	case slog.KindUint64:
+		return value.Uint64()
+	case slog.KindFloat64:
+		return value.Float64()
+	case slog.KindBool:
+		return value.Bool()
+	case slog.KindDuration:
+		return value.Duration()
+	case slog.KindTime:
+		return value.Time()
+	default:
+		return value.Any()
+	}
+}
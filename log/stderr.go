@@ -19,12 +19,15 @@ func PrintErr(v ...any) {
 			funcName = fn.Name()
 		}
 	}
+	if !stdAllow(pc, LevelError) {
+		return
+	}
 	element := newEntry().
 		withLevel(LevelError).
 		withTime(time.Now()).
 		withCallerInfo(line, file, funcName).
 		withMessage("", v...)
-	formatLogData(element)
+	formatEntry(element, stdFormat.Load())
 	_ = stdErr.Output(calldepth2, string(element.outBytes))
 }
 
@@ -37,11 +40,14 @@ func PrintfErr(format string, v ...any) {
 			funcName = fn.Name()
 		}
 	}
+	if !stdAllow(pc, LevelError) {
+		return
+	}
 	element := newEntry().
 		withLevel(LevelError).
 		withTime(time.Now()).
 		withCallerInfo(line, file, funcName).
 		withMessage(format, v...)
-	formatLogData(element)
+	formatEntry(element, stdFormat.Load())
 	_ = stdErr.Output(calldepth2, string(element.outBytes))
 }
@@ -5,11 +5,15 @@ import (
 	"log"
 	"os"
 	"runtime"
+	"sync/atomic"
 	"time"
 )
 
 var stdOut = log.New(os.Stdout, "", 0)
 
+// 标准输出/标准错误的输出格式, 重定向到日志文件后与日志一致
+var stdFormat atomic.Uint32
+
 // PrintInfo 输出到os.Stdout
 func PrintInfo(v ...any) {
 	funcName := xerror.Unknown.Name()
@@ -19,12 +23,15 @@ func PrintInfo(v ...any) {
 			funcName = fn.Name()
 		}
 	}
+	if !stdAllow(pc, LevelInfo) {
+		return
+	}
 	element := newEntry().
 		withLevel(LevelInfo).
 		withTime(time.Now()).
 		withCallerInfo(line, file, funcName).
 		withMessage("", v...)
-	formatLogData(element)
+	formatEntry(element, stdFormat.Load())
 	_ = stdOut.Output(calldepth2, string(element.outBytes))
 }
 
@@ -37,11 +44,14 @@ func PrintfInfo(format string, v ...any) {
 			funcName = fn.Name()
 		}
 	}
+	if !stdAllow(pc, LevelInfo) {
+		return
+	}
 	element := newEntry().
 		withLevel(LevelInfo).
 		withTime(time.Now()).
 		withCallerInfo(line, file, funcName).
 		withMessage(format, v...)
-	formatLogData(element)
+	formatEntry(element, stdFormat.Load())
 	_ = stdOut.Output(calldepth2, string(element.outBytes))
 }
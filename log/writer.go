@@ -2,27 +2,171 @@ package log
 
 import (
 	"fmt"
+	"os"
+	"sync"
+	"time"
+
 	xruntime "github.com/75912001/xlib/runtime"
 	"github.com/pkg/errors"
-	"os"
 )
 
-// 生成 normal log Writer
-func newNormalFileWriter(filePath string, namePrefix string, logDuration int) (*os.File, error) {
-	return newFileWriter(filePath, namePrefix, logDuration, normalLogFileBaseName)
+// 日志文件 Writer
+//
+//	文件超过 maxSize 时, 切换到新的分段 e.g.: ${prefix}.20200101.normal.log -> ${prefix}.20200101.1.normal.log
+//	磁盘剩余空间低于 minDiskFree 时, 暂停写入, 丢弃日志
+type fileWriter struct {
+	mu          sync.Mutex
+	filePath    string
+	namePrefix  string
+	baseName    string // normal.log / error.log
+	logDuration int
+	segment     int // 分段序号, 0:无序号
+	file        *os.File
+	closed      bool
+	size        int64
+	maxSize     int64
+	minDiskFree uint64
+	diskCheck   time.Time // 上次检查磁盘的时间
+	diskLow     bool      // 磁盘剩余空间不足
+	dropped     uint64    // 磁盘剩余空间不足时丢弃的日志数量
+	onRotate    func()    // 切换分段/磁盘剩余空间不足 时的回调, 用于触发历史文件维护
+}
+
+// 生成 log Writer, 续写该刻度下最新的分段
+func newFileWriter(opt *options, logDuration int, fileBaseName string, onRotate func()) (*fileWriter, error) {
+	w := &fileWriter{
+		filePath:    *opt.absPath,
+		namePrefix:  *opt.namePrefix,
+		baseName:    fileBaseName,
+		logDuration: logDuration,
+		maxSize:     *opt.maxSize,
+		minDiskFree: *opt.minDiskFree,
+		onRotate:    onRotate,
+	}
+	files, err := listLogFiles(w.filePath, w.namePrefix, w.baseName)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "list log files failed. %v", xruntime.Location())
+	}
+	for _, f := range files {
+		if f.logDuration != logDuration || f.segment < w.segment {
+			continue
+		}
+		w.segment = f.segment
+		if f.compressed || (0 < w.maxSize && w.maxSize <= f.size) { // 已压缩/已写满
+			w.segment = f.segment + 1
+		}
+	}
+	if err = w.open(); err != nil {
+		return nil, errors.WithMessagef(err, "open failed. %v", xruntime.Location())
+	}
+	return w, nil
 }
 
-// 生成 error log Writer
-func newErrorFileWriter(filePath string, namePrefix string, logDuration int) (*os.File, error) {
-	return newFileWriter(filePath, namePrefix, logDuration, errorLogFileBaseName)
+// 当前分段的文件名
+func (p *fileWriter) fileName() string {
+	if p.segment == 0 {
+		return fmt.Sprintf(fileFormat, p.filePath, p.namePrefix, p.logDuration, p.baseName)
+	}
+	return fmt.Sprintf(fileSegmentFormat, p.filePath, p.namePrefix, p.logDuration, p.segment, p.baseName)
 }
 
-// 生成 log Writer
-func newFileWriter(filePath string, namePrefix string, logDuration int, fileBaseName string) (*os.File, error) {
-	fileName := fmt.Sprintf(fileFormat, filePath, namePrefix, logDuration, fileBaseName)
-	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_APPEND|os.O_RDWR, os.FileMode(0644))
+func (p *fileWriter) open() error {
+	file, err := os.OpenFile(p.fileName(), os.O_CREATE|os.O_APPEND|os.O_RDWR, os.FileMode(0644))
 	if err != nil {
-		return nil, errors.WithMessage(err, xruntime.Location())
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	p.file = file
+	p.size = stat.Size()
+	return nil
+}
+
+// 切换到下一个分段
+func (p *fileWriter) rotate() error {
+	if err := p.file.Close(); err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	p.file = nil
+	p.segment++
+	if err := p.open(); err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	p.onRotate()
+	return nil
+}
+
+func (p *fileWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return 0, os.ErrClosed
+	}
+	if p.isDiskLow() {
+		p.dropped++
+		return len(b), nil
+	}
+	if p.file == nil { // 切换分段时打开失败, 重试
+		if err := p.open(); err != nil {
+			return 0, err
+		}
+	}
+	if 0 < p.maxSize && 0 < p.size && p.maxSize < p.size+int64(len(b)) {
+		if err := p.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := p.file.Write(b)
+	p.size += int64(n)
+	return n, err
+}
+
+// 检查磁盘剩余空间, 间隔 diskCheckInterval [持有锁]
+//
+//	[note]: 文件已重定向到日志, 提示信息直接写到 os.Stderr
+func (p *fileWriter) isDiskLow() bool {
+	if p.minDiskFree == 0 {
+		return false
+	}
+	now := time.Now()
+	if now.Sub(p.diskCheck) < diskCheckInterval {
+		return p.diskLow
+	}
+	p.diskCheck = now
+	free, err := diskFree(p.filePath)
+	if err != nil {
+		return p.diskLow
+	}
+	low := free < p.minDiskFree
+	if low && !p.diskLow {
+		_, _ = fmt.Fprintf(os.Stderr, "log disk free:%v less than:%v, pause writing %v\n", free, p.minDiskFree, p.fileName())
+		p.onRotate()
+	} else if !low && p.diskLow {
+		_, _ = fmt.Fprintf(os.Stderr, "log disk free:%v, resume writing %v, dropped:%v\n", free, p.fileName(), p.dropped)
+		p.dropped = 0
+	}
+	p.diskLow = low
+	return low
+}
+
+// 当前分段
+func (p *fileWriter) current() logFileKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return logFileKey{logDuration: p.logDuration, segment: p.segment}
+}
+
+func (p *fileWriter) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	if p.file == nil {
+		return nil
 	}
-	return file, nil
+	err := p.file.Close()
+	p.file = nil
+	return err
 }
@@ -0,0 +1,171 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// 历史日志文件维护: 按数量/时长清理, gzip 压缩
+
+// 日志文件 刻度+分段
+type logFileKey struct {
+	logDuration int
+	segment     int
+}
+
+func (p logFileKey) less(other logFileKey) bool {
+	if p.logDuration != other.logDuration {
+		return p.logDuration < other.logDuration
+	}
+	return p.segment < other.segment
+}
+
+// 日志文件
+type logFile struct {
+	logFileKey
+	name       string // 全路径
+	compressed bool
+	size       int64
+	modTime    time.Time
+}
+
+// 列出日志文件, 从新到旧
+//
+//	${prefix}.${duration}.${baseName}[.gz] 或 ${prefix}.${duration}.${segment}.${baseName}[.gz]
+func listLogFiles(filePath string, namePrefix string, baseName string) ([]*logFile, error) {
+	dirEntries, err := os.ReadDir(filePath)
+	if err != nil {
+		return nil, errors.WithMessage(err, xruntime.Location())
+	}
+	var files []*logFile
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() {
+			continue
+		}
+		name := dirEntry.Name()
+		if !strings.HasPrefix(name, namePrefix+".") {
+			continue
+		}
+		rest := name[len(namePrefix)+1:]
+		compressed := strings.HasSuffix(rest, compressSuffix)
+		rest = strings.TrimSuffix(rest, compressSuffix)
+		if !strings.HasSuffix(rest, "."+baseName) {
+			continue
+		}
+		fields := strings.Split(strings.TrimSuffix(rest, "."+baseName), ".")
+		if 2 < len(fields) {
+			continue
+		}
+		var key logFileKey
+		if key.logDuration, err = strconv.Atoi(fields[0]); err != nil {
+			continue
+		}
+		if len(fields) == 2 {
+			if key.segment, err = strconv.Atoi(fields[1]); err != nil || key.segment <= 0 {
+				continue
+			}
+		}
+		info, err := dirEntry.Info()
+		if err != nil { // 已被删除
+			continue
+		}
+		files = append(files, &logFile{
+			logFileKey: key,
+			name:       filepath.Join(filePath, name),
+			compressed: compressed,
+			size:       info.Size(),
+			modTime:    info.ModTime(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].logFileKey != files[j].logFileKey {
+			return files[j].logFileKey.less(files[i].logFileKey)
+		}
+		return files[i].compressed // 未压缩完的文件, 与压缩文件为同一个
+	})
+	return files, nil
+}
+
+// 维护历史日志文件
+//
+//	active: 当前写入的分段, 该分段及更新的文件不处理
+func maintainLogFiles(opt *options, baseName string, active logFileKey) error {
+	files, err := listLogFiles(*opt.absPath, *opt.namePrefix, baseName)
+	if err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	var count uint32
+	var last *logFile
+	for _, f := range files {
+		if !f.less(active) {
+			continue
+		}
+		if last == nil || last.logFileKey != f.logFileKey {
+			count++
+		}
+		last = f
+		if (0 < *opt.maxBackups && *opt.maxBackups < count) ||
+			(0 < *opt.maxAge && *opt.maxAge < time.Since(f.modTime)) {
+			if err = os.Remove(f.name); err != nil && !os.IsNotExist(err) {
+				return errors.WithMessage(err, xruntime.Location())
+			}
+			continue
+		}
+		if *opt.compress && !f.compressed {
+			if err = gzipFile(f.name, f.modTime); err != nil {
+				return errors.WithMessagef(err, "gzip %v failed. %v", f.name, xruntime.Location())
+			}
+		}
+	}
+	return nil
+}
+
+// 压缩文件为 name.gz, 保留修改时间, 成功后删除原文件
+func gzipFile(name string, modTime time.Time) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	tmpName := name + compressSuffix + ".tmp"
+	dst, err := os.OpenFile(tmpName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(0644))
+	if err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if errClose := dst.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Chtimes(tmpName, modTime, modTime)
+	}
+	if err == nil {
+		err = os.Rename(tmpName, name+compressSuffix)
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	if err = os.Remove(name); err != nil {
+		return errors.WithMessage(err, xruntime.Location())
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package log
+
+import (
+	"io"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// 按刻度切换的日志文件, 分为 normal/error 两个文件
+//
+//	release 每小时创建新的日志文件, debug 每天创建新的日志文件
+//	normal: 所有等级. error: LevelWarn 及以上
+//	后台协程维护历史日志文件: 按数量/时长清理, gzip 压缩
+type rotatingFile struct {
+	options           *options
+	logDuration       int // 日志分割刻度,变化时,使用新的日志文件 按天或者小时  e.g.: 20210819 或 2021081901
+	normalWriter      *fileWriter
+	errorWriter       *fileWriter
+	allWriter         io.Writer                             // normal+error
+	onSwitch          func(normal io.Writer, all io.Writer) // 切换文件后的回调 e.g.: 标准输出重定向 [default]: nil
+	mu                sync.Mutex                            // 保护 normalWriter/errorWriter, 维护协程中读取
+	maintainChan      chan struct{}                         // 维护历史日志文件 通道
+	waitGroupMaintain sync.WaitGroup                        // 同步锁 用于关闭时,等待维护完成
+}
+
+// 新的日志文件, 并开始维护协程
+func newRotatingFile(opt *options, nowTime time.Time, onSwitch func(normal io.Writer, all io.Writer)) (*rotatingFile, error) {
+	p := &rotatingFile{
+		options:      opt,
+		onSwitch:     onSwitch,
+		maintainChan: make(chan struct{}, maintainChanCap),
+	}
+	if err := p.switchFiles(getLogDuration(nowTime.Unix())); err != nil {
+		return nil, errors.WithMessagef(err, "switch files failed. %v", xruntime.Location())
+	}
+	p.waitGroupMaintain.Add(1)
+	go func() {
+		defer func() {
+			if xruntime.IsRelease() {
+				if err := recover(); err != nil {
+					PrintErr(xerror.GoroutinePanic, err, string(debug.Stack()))
+				}
+			}
+			p.waitGroupMaintain.Done()
+		}()
+		doMaintain(p)
+	}()
+	return p, nil
+}
+
+// getLogDuration 取得日志刻度
+func getLogDuration(sec int64) int {
+	var logFormat string
+	if xruntime.IsRelease() {
+		logFormat = "2006010215" //年月日小时
+	} else {
+		logFormat = "20060102" //年月日
+	}
+	durationStr := time.Unix(sec, 0).Format(logFormat)
+	duration, err := strconv.Atoi(durationStr)
+	if err != nil {
+		PrintfErr("strconv.Atoi sec:%v durationStr:%v err:%v", sec, durationStr, err)
+	}
+	return duration
+}
+
+// 写入一行, 刻度变化时切换文件
+func (p *rotatingFile) write(level uint32, nowTime time.Time, line []byte) error {
+	if logDuration := getLogDuration(nowTime.Unix()); p.logDuration != logDuration {
+		if err := p.switchFiles(logDuration); err != nil {
+			return errors.WithMessagef(err, "log duration changed, switch files failed. %v", xruntime.Location())
+		}
+	}
+	var err error
+	if level <= LevelWarn {
+		_, err = p.allWriter.Write(line)
+	} else {
+		_, err = p.normalWriter.Write(line)
+	}
+	return err
+}
+
+// 切换到新刻度的文件, 关闭之前的文件
+func (p *rotatingFile) switchFiles(logDuration int) error {
+	normalWriter, err := newFileWriter(p.options, logDuration, normalLogFileBaseName, p.triggerMaintain)
+	if err != nil {
+		return errors.WithMessagef(err, "new normal file writer failed. %v", xruntime.Location())
+	}
+	errorWriter, err := newFileWriter(p.options, logDuration, errorLogFileBaseName, p.triggerMaintain)
+	if err != nil {
+		_ = normalWriter.Close()
+		return errors.WithMessagef(err, "new error file writer failed. %v", xruntime.Location())
+	}
+	p.logDuration = logDuration
+	p.allWriter = io.MultiWriter(normalWriter, errorWriter)
+	if p.onSwitch != nil {
+		p.onSwitch(normalWriter, p.allWriter)
+	}
+	p.mu.Lock()
+	oldWriters := []*fileWriter{p.normalWriter, p.errorWriter}
+	p.normalWriter, p.errorWriter = normalWriter, errorWriter
+	p.mu.Unlock()
+	for _, w := range oldWriters {
+		if w == nil {
+			continue
+		}
+		if err = w.Close(); err != nil {
+			PrintfErr("close file failed. err:%v %v", err, xruntime.Location())
+		}
+	}
+	// 维护历史日志文件(包括之前的文件)
+	p.triggerMaintain()
+	return nil
+}
+
+// triggerMaintain 触发维护历史日志文件, 不阻塞
+func (p *rotatingFile) triggerMaintain() {
+	select {
+	case p.maintainChan <- struct{}{}:
+	default: // 已有待处理的维护
+	}
+}
+
+// doMaintain 维护历史日志文件
+func doMaintain(p *rotatingFile) {
+	for range p.maintainChan {
+		p.mu.Lock()
+		fileWriters := []*fileWriter{p.normalWriter, p.errorWriter}
+		p.mu.Unlock()
+		for _, w := range fileWriters {
+			if err := maintainLogFiles(p.options, w.baseName, w.current()); err != nil {
+				PrintfErr("maintain log files failed. err:%v %v", err, xruntime.Location())
+			}
+		}
+	}
+}
+
+// 关闭文件, 等待维护完成
+func (p *rotatingFile) close() error {
+	p.mu.Lock()
+	fileWriters := []*fileWriter{p.normalWriter, p.errorWriter}
+	p.mu.Unlock()
+	var err error
+	for _, w := range fileWriters {
+		if errClose := w.Close(); errClose != nil && err == nil {
+			err = errClose
+		}
+	}
+	if p.maintainChan != nil {
+		close(p.maintainChan)
+		p.waitGroupMaintain.Wait()
+		p.maintainChan = nil
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package message
+
+import (
+	"math"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// LimitPolicy 消息限流-超载时的处理策略
+type LimitPolicy uint32
+
+const (
+	LimitPolicyDrop       LimitPolicy = 0 // 丢弃该消息
+	LimitPolicyReply      LimitPolicy = 1 // 丢弃该消息, 并回复 ResultID
+	LimitPolicyDisconnect LimitPolicy = 2 // 断开连接
+)
+
+// Limit 消息限流配置 (令牌桶)
+//
+//	每个连接, 每个 messageID 独立计数. e.g.: 聊天 1/s, 移动 30/s
+type Limit struct {
+	RatePerSec float64     // 令牌生成速率-每秒
+	Burst      uint32      // 桶容量-允许的最大突发数量 [default]: ceil(RatePerSec)
+	Policy     LimitPolicy // 超载时的处理策略 [default]: LimitPolicyDrop
+	ResultID   uint32      // Policy 为 LimitPolicyReply 时, 回复的结果id
+}
+
+// NewLimit 创建消息限流配置
+func NewLimit(ratePerSec float64, policy LimitPolicy) *Limit {
+	return &Limit{
+		RatePerSec: ratePerSec,
+		Policy:     policy,
+	}
+}
+
+func (p *Limit) WithBurst(burst uint32) *Limit {
+	p.Burst = burst
+	return p
+}
+
+func (p *Limit) WithResultID(resultID uint32) *Limit {
+	p.ResultID = resultID
+	return p
+}
+
+// 配置
+func (p *Limit) configure() error {
+	if p.RatePerSec <= 0 {
+		return errors.WithMessagef(xerror.Param, "RatePerSec:%v %v", p.RatePerSec, xruntime.Location())
+	}
+	switch p.Policy {
+	case LimitPolicyDrop, LimitPolicyReply, LimitPolicyDisconnect:
+	default:
+		return errors.WithMessagef(xerror.ParamNotSupport, "Policy:%v %v", p.Policy, xruntime.Location())
+	}
+	if p.Burst == 0 {
+		p.Burst = uint32(math.Ceil(p.RatePerSec))
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ type Message struct {
 	newProtoMessage   func() proto.Message   // 创建新的 proto.Message
 	stateSwitch       xcontrol.ISwitchButton // 状态开关-该消息是否启用
 	passThroughSwitch xcontrol.ISwitchButton // 透传开关-该消息是否透传
+	limit             *Limit                 // 限流配置
 }
 
 func newDefaultMessage(opts *options) *Message {
@@ -22,6 +23,7 @@ func newDefaultMessage(opts *options) *Message {
 		newProtoMessage:   opts.newProtoMessage,
 		stateSwitch:       opts.stateSwitch,
 		passThroughSwitch: opts.passThroughSwitch,
+		limit:             opts.limit,
 	}
 }
 
@@ -29,6 +31,10 @@ func (p *Message) IsPassThrough() bool {
 	return p.passThroughSwitch.IsOn()
 }
 
+func (p *Message) GetLimit() *Limit {
+	return p.limit
+}
+
 func (p *Message) Execute() error {
 	if p.stateSwitch.IsOff() { // 消息是否禁用
 		return xerror.Disable
@@ -11,4 +11,5 @@ type IMessage interface {
 	Unmarshal(data []byte) (message proto.Message, err error)
 	JsonUnmarshal(data []byte) (message proto.Message, err error)
 	IsPassThrough() bool // 是否透传
+	GetLimit() *Limit    // 限流配置, nil:不限流
 }
@@ -13,6 +13,7 @@ type options struct {
 	newProtoMessage   func() proto.Message   // [required] 创建新的 proto.Message
 	stateSwitch       xcontrol.ISwitchButton // [optional] 状态开关-该消息是否启用 [default]:true
 	passThroughSwitch xcontrol.ISwitchButton // [optional] 透传开关-该消息是否透传 [default]:false
+	limit             *Limit                 // [optional] 限流配置-每个连接 [default]:nil 不限流
 }
 
 // NewOptions 创建 options
@@ -40,6 +41,11 @@ func (p *options) WithPassThroughSwitch(passThroughSwitch xcontrol.ISwitchButton
 	return p
 }
 
+func (p *options) WithLimit(limit *Limit) *options {
+	p.limit = limit
+	return p
+}
+
 func merge(opts ...*options) *options {
 	so := NewOptions()
 	for _, opt := range opts {
@@ -58,6 +64,9 @@ func merge(opts ...*options) *options {
 		if opt.passThroughSwitch != nil {
 			so.passThroughSwitch = opt.passThroughSwitch
 		}
+		if opt.limit != nil {
+			so.limit = opt.limit
+		}
 	}
 	return so
 }
@@ -78,5 +87,10 @@ func configure(opts *options) error {
 			return errors.WithMessage(xerror.Param, xruntime.Location())
 		}
 	}
+	if opts.limit != nil {
+		if err := opts.limit.configure(); err != nil {
+			return errors.WithMessage(err, xruntime.Location())
+		}
+	}
 	return nil
 }
@@ -22,6 +22,7 @@ const (
 	DisconnectReasonServerShutdown DisconnectReason = 3 // 服务端关闭
 	DisconnectReasonShutdown       DisconnectReason = 4 // 关闭-主动关闭
 	DisconnectReasonPeerShutdown   DisconnectReason = 5 // 对端关闭
+	DisconnectReasonOverload       DisconnectReason = 6 // 过载-限流
 	// [10000,20000] 留给业务使用
 	// ...
 )
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"time"
+
+	xerror "github.com/75912001/xlib/error"
+	xmessage "github.com/75912001/xlib/message"
+	xpacket "github.com/75912001/xlib/packet"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// IMessageLimit 消息限流-按 messageID
+type IMessageLimit interface {
+	IsMessageOverload(messageID uint32, limit *xmessage.Limit, nowTime time.Time) bool // 是否超载
+}
+
+// MessageLimit 消息限流-每个连接一份, 每个 messageID 一个令牌桶
+//
+//	[⚠️] 非并发安全, 须在处理该连接数据包的协程中调用
+type MessageLimit struct {
+	bucketMap map[uint32]*PacketLimitTokenBucket // key: messageID
+}
+
+func NewMessageLimit() *MessageLimit {
+	return &MessageLimit{
+		bucketMap: make(map[uint32]*PacketLimitTokenBucket),
+	}
+}
+
+func (p *MessageLimit) IsMessageOverload(messageID uint32, limit *xmessage.Limit, nowTime time.Time) bool {
+	if limit == nil {
+		return false
+	}
+	bucket, ok := p.bucketMap[messageID]
+	if !ok {
+		bucket = NewPacketLimitTokenBucketWithBurst(limit.RatePerSec, limit.Burst)
+		p.bucketMap[messageID] = bucket
+	}
+	return bucket.IsOverload(1, nowTime)
+}
+
+// CheckMessageLimit 检查数据包是否超过该消息的限流配置, 超载时按 Limit.Policy 处理
+//
+//	只处理 *xpacket.Packet 且 IMessage 配置了限流的数据包
+//	返回:
+//		nil: 未超载, 可以继续处理
+//		xerror.Overload: 超载, 该数据包已按策略处理, 不应继续处理
+func CheckMessageLimit(remote IRemote, packet xpacket.IPacket) error {
+	pkt, ok := packet.(*xpacket.Packet)
+	if !ok || pkt.IMessage == nil || pkt.Header == nil {
+		return nil
+	}
+	limit := pkt.IMessage.GetLimit()
+	if limit == nil {
+		return nil
+	}
+	if !remote.IsMessageOverload(pkt.Header.MessageID, limit, time.Now()) {
+		return nil
+	}
+	switch limit.Policy {
+	case xmessage.LimitPolicyReply:
+		reply := xpacket.NewPacket().WithHeader(
+			&xpacket.Header{
+				MessageID: pkt.Header.MessageID,
+				SessionID: pkt.Header.SessionID,
+				ResultID:  limit.ResultID,
+				Key:       pkt.Header.Key,
+			},
+		)
+		if err := remote.Send(reply); err != nil {
+			return errors.WithMessagef(err, "messageID:%#x reply err. %v", pkt.Header.MessageID, xruntime.Location())
+		}
+	case xmessage.LimitPolicyDisconnect:
+		remote.SetDisconnectReason(DisconnectReasonOverload)
+		remote.Stop()
+	}
+	return errors.WithMessagef(xerror.Overload, "messageID:%#x policy:%v %v", pkt.Header.MessageID, limit.Policy, xruntime.Location())
+}
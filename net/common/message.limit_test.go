@@ -0,0 +1,142 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	xclock "github.com/75912001/xlib/clock"
+	xerror "github.com/75912001/xlib/error"
+	xmessage "github.com/75912001/xlib/message"
+	xpacket "github.com/75912001/xlib/packet"
+	"github.com/pkg/errors"
+)
+
+// 限流配置的消息
+type testLimitMessage struct {
+	xmessage.IMessage
+	limit *xmessage.Limit
+}
+
+func (p *testLimitMessage) GetLimit() *xmessage.Limit {
+	return p.limit
+}
+
+// 链接, 使用注入的时钟限流, 记录发送的数据包及断开
+type testLimitRemote struct {
+	IRemote
+	clock        xclock.IClock
+	messageLimit *MessageLimit
+	sent         []xpacket.IPacket
+	reason       DisconnectReason
+	stopped      bool
+}
+
+func newTestLimitRemote(clock xclock.IClock) *testLimitRemote {
+	return &testLimitRemote{
+		clock:        clock,
+		messageLimit: NewMessageLimit(),
+	}
+}
+
+func (p *testLimitRemote) IsMessageOverload(messageID uint32, limit *xmessage.Limit, _ time.Time) bool {
+	return p.messageLimit.IsMessageOverload(messageID, limit, p.clock.Now())
+}
+
+func (p *testLimitRemote) Send(packet xpacket.IPacket) error {
+	p.sent = append(p.sent, packet)
+	return nil
+}
+
+func (p *testLimitRemote) SetDisconnectReason(reason DisconnectReason) {
+	p.reason = reason
+}
+
+func (p *testLimitRemote) Stop() {
+	p.stopped = true
+}
+
+func newTestLimitPacket(messageID uint32, limit *xmessage.Limit) *xpacket.Packet {
+	return xpacket.NewPacket().
+		WithHeader(&xpacket.Header{MessageID: messageID, SessionID: 7, Key: 9}).
+		WithIMessage(&testLimitMessage{limit: limit})
+}
+
+func TestCheckMessageLimitPolicy(t *testing.T) {
+	const messageID = 0x10001
+	tests := []struct {
+		name   string
+		policy xmessage.LimitPolicy
+	}{
+		{"drop", xmessage.LimitPolicyDrop},
+		{"reply", xmessage.LimitPolicyReply},
+		{"disconnect", xmessage.LimitPolicyDisconnect},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := xclock.NewVirtual(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+			remote := newTestLimitRemote(clock)
+			limit := xmessage.NewLimit(1, test.policy).WithBurst(2)
+			limit.ResultID = 42
+			for i := 0; i < 2; i++ {
+				if err := CheckMessageLimit(remote, newTestLimitPacket(messageID, limit)); err != nil {
+					t.Fatalf("burst %v err:%v", i, err)
+				}
+			}
+			if err := CheckMessageLimit(remote, newTestLimitPacket(messageID, limit)); !errors.Is(err, xerror.Overload) {
+				t.Fatalf("err:%v", err)
+			}
+			switch test.policy {
+			case xmessage.LimitPolicyDrop:
+				if len(remote.sent) != 0 || remote.stopped {
+					t.Fatalf("sent:%v stopped:%v", len(remote.sent), remote.stopped)
+				}
+			case xmessage.LimitPolicyReply:
+				if len(remote.sent) != 1 || remote.stopped {
+					t.Fatalf("sent:%v stopped:%v", len(remote.sent), remote.stopped)
+				}
+				reply := remote.sent[0].(*xpacket.Packet)
+				if want := (xpacket.Header{MessageID: messageID, SessionID: 7, ResultID: 42, Key: 9}); *reply.Header != want || reply.PBMessage != nil {
+					t.Fatalf("reply header:%+v pb:%v", *reply.Header, reply.PBMessage)
+				}
+			case xmessage.LimitPolicyDisconnect:
+				if len(remote.sent) != 0 || !remote.stopped || remote.reason != DisconnectReasonOverload {
+					t.Fatalf("sent:%v stopped:%v reason:%v", len(remote.sent), remote.stopped, remote.reason)
+				}
+			}
+		})
+	}
+}
+
+// 每个 messageID 一个令牌桶, 令牌按注入的时钟补充
+func TestCheckMessageLimitRefill(t *testing.T) {
+	clock := xclock.NewVirtual(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	remote := newTestLimitRemote(clock)
+	limit := xmessage.NewLimit(2, xmessage.LimitPolicyDrop).WithBurst(1)
+	check := func(messageID uint32) error {
+		return CheckMessageLimit(remote, newTestLimitPacket(messageID, limit))
+	}
+	if err := check(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := check(1); !errors.Is(err, xerror.Overload) {
+		t.Fatalf("err:%v", err)
+	}
+	if err := check(2); err != nil { // 其它 messageID 不受影响
+		t.Fatal(err)
+	}
+	clock.Advance(250 * time.Millisecond) // 补充 0.5 个
+	if err := check(1); !errors.Is(err, xerror.Overload) {
+		t.Fatalf("err:%v before refill", err)
+	}
+	clock.Advance(250 * time.Millisecond)
+	if err := check(1); err != nil {
+		t.Fatalf("err:%v after refill", err)
+	}
+	if err := check(1); !errors.Is(err, xerror.Overload) {
+		t.Fatalf("err:%v", err)
+	}
+	// 未配置限流
+	if err := CheckMessageLimit(remote, newTestLimitPacket(3, nil)); err != nil {
+		t.Fatal(err)
+	}
+}
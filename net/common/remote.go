@@ -3,6 +3,7 @@ package common
 import (
 	"context"
 	xcontrol "github.com/75912001/xlib/control"
+	xmessage "github.com/75912001/xlib/message"
 	xpacket "github.com/75912001/xlib/packet"
 	"time"
 )
@@ -10,6 +11,7 @@ import (
 type IRemote interface {
 	ISend
 	IPacketLimit
+	IMessageLimit
 	IsConnect() bool
 	Start(connOptions *ConnOptions, iout xcontrol.IOut, handler IHandler)
 	Stop()
@@ -25,6 +27,7 @@ type DefaultRemote struct {
 	DisconnectReason DisconnectReason // 断开原因
 	HeaderStrategy   xpacket.IHeaderStrategy
 	PacketLimit      IPacketLimit
+	MessageLimit     IMessageLimit // 消息限流-按 messageID, nil 时使用 NewMessageLimit 创建
 }
 
 func (p *DefaultRemote) GetDisconnectReason() DisconnectReason {
@@ -41,3 +44,13 @@ func (p *DefaultRemote) IsOverload(cnt uint32, nowTime time.Time) bool {
 	}
 	return p.PacketLimit.IsOverload(cnt, nowTime)
 }
+
+func (p *DefaultRemote) IsMessageOverload(messageID uint32, limit *xmessage.Limit, nowTime time.Time) bool {
+	if limit == nil {
+		return false
+	}
+	if p.MessageLimit == nil {
+		p.MessageLimit = NewMessageLimit()
+	}
+	return p.MessageLimit.IsMessageOverload(messageID, limit, nowTime)
+}
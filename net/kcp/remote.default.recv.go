@@ -1,7 +1,6 @@
 package kcp
 
 import (
-	xconfig "github.com/75912001/xlib/config"
 	xcontrol "github.com/75912001/xlib/control"
 	xerror "github.com/75912001/xlib/error"
 	xlog "github.com/75912001/xlib/log"
@@ -19,16 +18,12 @@ func (p *Remote) onRecvLengthFirst(iOut xcontrol.IOut, handler xnetcommon.IHandl
 		if r := recover(); r != nil {
 			xlog.PrintErr(xerror.GoroutinePanic, p, r, debug.Stack())
 		}
-		if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-			_ = handler.OnDisconnect(p)
-		} else {
-			iOut.Send(
-				&xnetcommon.Disconnect{
-					IHandler: handler,
-					IRemote:  p,
-				},
-			)
-		}
+		iOut.Send(
+			&xnetcommon.Disconnect{
+				IHandler: handler,
+				IRemote:  p,
+			},
+		)
 
 		xlog.PrintInfo(xerror.GoroutineDone, p)
 	}()
@@ -76,17 +71,13 @@ func (p *Remote) onRecvLengthFirst(iOut xcontrol.IOut, handler xnetcommon.IHandl
 				if err != nil {
 					xlog.PrintfErr("remote:%p buf:%v err:%v", p, buf[:packetAllLength], err)
 				} else {
-					if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-						_ = handler.OnPacket(p, packet)
-					} else {
-						iOut.Send(
-							&xnetcommon.Packet{
-								IHandler: handler,
-								IRemote:  p,
-								IPacket:  packet,
-							},
-						)
-					}
+					iOut.Send(
+						&xnetcommon.Packet{
+							IHandler: handler,
+							IRemote:  p,
+							IPacket:  packet,
+						},
+					)
 
 				}
 			}
@@ -107,16 +98,12 @@ func (p *Remote) onRecvMessageIDFirst(iOut xcontrol.IOut, handler xnetcommon.IHa
 		if r := recover(); r != nil {
 			xlog.PrintErr(xerror.GoroutinePanic, p, r, debug.Stack())
 		}
-		if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-			_ = handler.OnDisconnect(p)
-		} else {
-			iOut.Send(
-				&xnetcommon.Disconnect{
-					IHandler: handler,
-					IRemote:  p,
-				},
-			)
-		}
+		iOut.Send(
+			&xnetcommon.Disconnect{
+				IHandler: handler,
+				IRemote:  p,
+			},
+		)
 
 		xlog.PrintInfo(xerror.GoroutineDone, p)
 	}()
@@ -189,17 +176,13 @@ func (p *Remote) onRecvMessageIDFirst(iOut xcontrol.IOut, handler xnetcommon.IHa
 				if packet, err := handler.OnUnmarshalPacket(p, buf[:packetAllLength]); err != nil {
 					xlog.PrintfErr("remote:%p buf:%v err:%v", p, buf[:packetAllLength], err)
 				} else {
-					if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-						_ = handler.OnPacket(p, packet)
-					} else {
-						iOut.Send(
-							&xnetcommon.Packet{
-								IHandler: handler,
-								IRemote:  p,
-								IPacket:  packet,
-							},
-						)
-					}
+					iOut.Send(
+						&xnetcommon.Packet{
+							IHandler: handler,
+							IRemote:  p,
+							IPacket:  packet,
+						},
+					)
 				}
 			}
 			// 移动剩余数据到缓冲区开始
@@ -2,7 +2,6 @@ package kcp
 
 import (
 	"context"
-	xconfig "github.com/75912001/xlib/config"
 	xcontrol "github.com/75912001/xlib/control"
 	xerror "github.com/75912001/xlib/error"
 	xlog "github.com/75912001/xlib/log"
@@ -107,15 +106,11 @@ func (p *Server) handleConn(udpSession *kcp.UDPSession, iOut xcontrol.IOut) {
 	}
 	xlog.PrintfInfo("accept from UDPSession:%p, conv:%v, RemoteAddr.Network:%v, RemoteAddr.String:%v, remote:%p",
 		udpSession, udpSession.GetConv(), udpSession.RemoteAddr().Network(), udpSession.RemoteAddr().String(), remote)
-	if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-		_ = p.IHandler.OnConnect(remote)
-	} else {
-		iOut.Send(
-			&xnetcommon.Connect{
-				IHandler: p.IHandler,
-				IRemote:  remote,
-			},
-		)
-	}
+	iOut.Send(
+		&xnetcommon.Connect{
+			IHandler: p.IHandler,
+			IRemote:  remote,
+		},
+	)
 	remote.Start(&p.options.ConnOptions, iOut, p.IHandler)
 }
@@ -2,7 +2,6 @@ package tcp
 
 import (
 	"context"
-	xconfig "github.com/75912001/xlib/config"
 	xcontrol "github.com/75912001/xlib/control"
 	xerror "github.com/75912001/xlib/error"
 	xlog "github.com/75912001/xlib/log"
@@ -20,16 +19,12 @@ func (p *Remote) onRecvLengthFirst(ctx context.Context, iOut xcontrol.IOut, hand
 		if r := recover(); r != nil {
 			xlog.PrintErr(xerror.GoroutinePanic, p, r, debug.Stack())
 		}
-		if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-			_ = handler.OnDisconnect(p)
-		} else {
-			iOut.Send(
-				&xnetcommon.Disconnect{
-					IHandler: handler,
-					IRemote:  p,
-				},
-			)
-		}
+		iOut.Send(
+			&xnetcommon.Disconnect{
+				IHandler: handler,
+				IRemote:  p,
+			},
+		)
 
 		xlog.PrintInfo(xerror.GoroutineDone, p)
 	}()
@@ -71,17 +66,13 @@ func (p *Remote) onRecvLengthFirst(ctx context.Context, iOut xcontrol.IOut, hand
 			xlog.PrintfErr("remote:%p err:%v", p, err)
 			continue
 		}
-		if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-			_ = handler.OnPacket(p, packet)
-		} else {
-			iOut.Send(
-				&xnetcommon.Packet{
-					IHandler: handler,
-					IRemote:  p,
-					IPacket:  packet,
-				},
-			)
-		}
+		iOut.Send(
+			&xnetcommon.Packet{
+				IHandler: handler,
+				IRemote:  p,
+				IPacket:  packet,
+			},
+		)
 	}
 }
 
@@ -92,16 +83,12 @@ func (p *Remote) onRecvMessageIDFirst(ctx context.Context, iOut xcontrol.IOut, h
 		if r := recover(); r != nil {
 			xlog.PrintErr(xerror.GoroutinePanic, p, r, debug.Stack())
 		}
-		if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-			_ = handler.OnDisconnect(p)
-		} else {
-			iOut.Send(
-				&xnetcommon.Disconnect{
-					IHandler: handler,
-					IRemote:  p,
-				},
-			)
-		}
+		iOut.Send(
+			&xnetcommon.Disconnect{
+				IHandler: handler,
+				IRemote:  p,
+			},
+		)
 
 		xlog.PrintInfo(xerror.GoroutineDone, p)
 	}()
@@ -177,17 +164,13 @@ func (p *Remote) onRecvMessageIDFirst(ctx context.Context, iOut xcontrol.IOut, h
 			xlog.PrintfErr("remote:%p err:%v", p, err)
 			continue
 		}
-		if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-			_ = handler.OnPacket(p, packet)
-		} else {
-			iOut.Send(
-				&xnetcommon.Packet{
-					IHandler: handler,
-					IRemote:  p,
-					IPacket:  packet,
-				},
-			)
-		}
+		iOut.Send(
+			&xnetcommon.Packet{
+				IHandler: handler,
+				IRemote:  p,
+				IPacket:  packet,
+			},
+		)
 
 	}
 }
@@ -199,16 +182,12 @@ func (p *Remote) onRecvLengthFirst_WithoutLength(ctx context.Context, iOut xcont
 		if r := recover(); r != nil {
 			xlog.PrintErr(xerror.GoroutinePanic, p, r, debug.Stack())
 		}
-		if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-			_ = handler.OnDisconnect(p)
-		} else {
-			iOut.Send(
-				&xnetcommon.Disconnect{
-					IHandler: handler,
-					IRemote:  p,
-				},
-			)
-		}
+		iOut.Send(
+			&xnetcommon.Disconnect{
+				IHandler: handler,
+				IRemote:  p,
+			},
+		)
 
 		xlog.PrintInfo(xerror.GoroutineDone, p)
 	}()
@@ -250,16 +229,12 @@ func (p *Remote) onRecvLengthFirst_WithoutLength(ctx context.Context, iOut xcont
 			xlog.PrintfErr("remote:%p err:%v", p, err)
 			continue
 		}
-		if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-			_ = handler.OnPacket(p, packet)
-		} else {
-			iOut.Send(
-				&xnetcommon.Packet{
-					IHandler: handler,
-					IRemote:  p,
-					IPacket:  packet,
-				},
-			)
-		}
+		iOut.Send(
+			&xnetcommon.Packet{
+				IHandler: handler,
+				IRemote:  p,
+				IPacket:  packet,
+			},
+		)
 	}
 }
@@ -2,7 +2,6 @@ package tcp
 
 import (
 	"context"
-	xconfig "github.com/75912001/xlib/config"
 	xcontrol "github.com/75912001/xlib/control"
 	xerror "github.com/75912001/xlib/error"
 	xlog "github.com/75912001/xlib/log"
@@ -104,15 +103,11 @@ func (p *Server) handleConn(conn *net.TCPConn, iOut xcontrol.IOut) {
 	if p.options.NewPacketLimitFunc != nil {
 		remote.PacketLimit = p.options.NewPacketLimitFunc(p.options.MaxCntPerSec)
 	}
-	if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-		_ = p.IHandler.OnConnect(remote)
-	} else {
-		iOut.Send(
-			&xnetcommon.Connect{
-				IHandler: p.IHandler,
-				IRemote:  remote,
-			},
-		)
-	}
+	iOut.Send(
+		&xnetcommon.Connect{
+			IHandler: p.IHandler,
+			IRemote:  remote,
+		},
+	)
 	remote.Start(&p.options.ConnOptions, iOut, p.IHandler)
 }
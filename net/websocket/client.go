@@ -2,7 +2,6 @@ package websocket
 
 import (
 	"context"
-	xconfig "github.com/75912001/xlib/config"
 	xerror "github.com/75912001/xlib/error"
 	xlog "github.com/75912001/xlib/log"
 	xnetcommon "github.com/75912001/xlib/net/common"
@@ -63,16 +62,12 @@ func (p *Client) Connect(ctx context.Context, opts ...*ConnectOptions) error {
 					xlog.PrintErr(xerror.GoroutinePanic, p, r, debug.Stack())
 				}
 			}
-			if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-				_ = p.IHandler.OnDisconnect(remote)
-			} else {
-				opt.iOut.Send(
-					&xnetcommon.Disconnect{
-						IHandler: p.IHandler,
-						IRemote:  remote,
-					},
-				)
-			}
+			opt.iOut.Send(
+				&xnetcommon.Disconnect{
+					IHandler: p.IHandler,
+					IRemote:  remote,
+				},
+			)
 			_ = conn.Close()
 		}()
 
@@ -106,17 +101,13 @@ func (p *Client) Connect(ctx context.Context, opts ...*ConnectOptions) error {
 				xlog.PrintfErr("remote:%p buf:%v err:%v", p, buf, err)
 				continue
 			}
-			if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-				_ = p.IHandler.OnPacket(remote, packet)
-			} else {
-				opt.iOut.Send(
-					&xnetcommon.Packet{
-						IHandler: p.IHandler,
-						IRemote:  remote,
-						IPacket:  packet,
-					},
-				)
-			}
+			opt.iOut.Send(
+				&xnetcommon.Packet{
+					IHandler: p.IHandler,
+					IRemote:  remote,
+					IPacket:  packet,
+				},
+			)
 		}
 	}()
 	return nil
@@ -3,7 +3,6 @@ package websocket
 import (
 	"context"
 	"errors"
-	xconfig "github.com/75912001/xlib/config"
 	xcontrol "github.com/75912001/xlib/control"
 	xerror "github.com/75912001/xlib/error"
 	xlog "github.com/75912001/xlib/log"
@@ -115,16 +114,12 @@ func (p *Server) handleWebSocket(w http.ResponseWriter, req *http.Request) {
 				xlog.PrintErr(xerror.GoroutinePanic, p, r, debug.Stack())
 			}
 		}
-		if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-			_ = p.IHandler.OnDisconnect(remote)
-		} else {
-			p.options.iOut.Send(
-				&xnetcommon.Disconnect{
-					IHandler: p.IHandler,
-					IRemote:  remote,
-				},
-			)
-		}
+		p.options.iOut.Send(
+			&xnetcommon.Disconnect{
+				IHandler: p.IHandler,
+				IRemote:  remote,
+			},
+		)
 		_ = conn.Close()
 	}()
 
@@ -158,17 +153,13 @@ func (p *Server) handleWebSocket(w http.ResponseWriter, req *http.Request) {
 			xlog.PrintfErr("remote:%p buf:%v err:%v", p, buf, err)
 			continue
 		}
-		if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-			_ = p.IHandler.OnPacket(remote, packet)
-		} else {
-			p.options.iOut.Send(
-				&xnetcommon.Packet{
-					IHandler: p.IHandler,
-					IRemote:  remote,
-					IPacket:  packet,
-				},
-			)
-		}
+		p.options.iOut.Send(
+			&xnetcommon.Packet{
+				IHandler: p.IHandler,
+				IRemote:  remote,
+				IPacket:  packet,
+			},
+		)
 	}
 }
 
@@ -177,16 +168,12 @@ func (p *Server) handleConn(conn *websocket.Conn, iOut xcontrol.IOut) *Remote {
 	if p.options.NewPacketLimitFunc != nil {
 		remote.PacketLimit = p.options.NewPacketLimitFunc(p.options.MaxCntPerSec)
 	}
-	if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
-		_ = p.IHandler.OnConnect(remote)
-	} else {
-		iOut.Send(
-			&xnetcommon.Connect{
-				IHandler: p.IHandler,
-				IRemote:  remote,
-			},
-		)
-	}
+	iOut.Send(
+		&xnetcommon.Connect{
+			IHandler: p.IHandler,
+			IRemote:  remote,
+		},
+	)
 	remote.Start(&p.options.ConnOptions, iOut, p.IHandler)
 	return remote
 }
@@ -1,6 +1,9 @@
 package server
 
-import xactor "github.com/75912001/xlib/actor"
+import (
+	xactor "github.com/75912001/xlib/actor"
+	xevent "github.com/75912001/xlib/event"
+)
 
 type Actor struct {
 	*xactor.Actor[uint64] // actor, 该 id 为 server id
@@ -9,8 +12,21 @@ type Actor struct {
 // NewActor creates a new Actor with the given id and behavior.
 //
 //	id: server id.
+//	在 actor 系统中的路径为 /server
+//	邮箱按 eventPriority 处理, 数据包洪峰时定时器回调仍能及时处理
 func NewActor(id uint64, behavior xactor.Behavior) *Actor {
 	return &Actor{
-		Actor: xactor.NewActor(id, nil, behavior),
+		Actor: xactor.NewActorWithOptions(id, nil,
+			xactor.NewOptions().
+				WithFactory(xactor.BehaviorFactory(behavior)).
+				WithName("server").
+				WithMailbox(newMailboxOptions()),
+		),
 	}
 }
+
+// 邮箱选项
+func newMailboxOptions() *xevent.ListOptions {
+	return xevent.NewListOptions().
+		WithPriorityFunc(eventPriority)
+}
@@ -4,8 +4,12 @@ import (
 	xactor "github.com/75912001/xlib/actor"
 	xcontrol "github.com/75912001/xlib/control"
 	xerror "github.com/75912001/xlib/error"
+	xevent "github.com/75912001/xlib/event"
 	xlog "github.com/75912001/xlib/log"
 	xnetcommon "github.com/75912001/xlib/net/common"
+	xnetkcp "github.com/75912001/xlib/net/kcp"
+	xnettcp "github.com/75912001/xlib/net/tcp"
+	xnetwebsocket "github.com/75912001/xlib/net/websocket"
 	xruntime "github.com/75912001/xlib/runtime"
 	"github.com/pkg/errors"
 	"time"
@@ -36,6 +40,58 @@ func (p *Server) behavior(events ...any) (be xactor.Behavior, response any, err
 	return p.behavior, response, nil
 }
 
+// eventPriority 事件优先级
+//
+//	定时器回调优先于网络事件
+//	链接, 断开链接 与数据包同优先级, 保证同一链接的事件有序
+func eventPriority(value any) xevent.Priority {
+	switch value.(type) {
+	case *xcontrol.Event:
+		return xevent.PriorityHigh
+	default:
+		return xevent.PriorityNormal
+	}
+}
+
+// onBusPartition 分区处理网络事件, 不同分区并发调用
+func (p *Server) onBusPartition(events ...any) error {
+	event := events[0]
+	result := p.processEvent(event)
+
+	p.monitorPerformance(event, result.dt)
+	if result.err != nil {
+		return errors.WithMessagef(result.err, "processEvent error, with event type:%T", event)
+	}
+	return nil
+}
+
+// busPartitionKey 网络事件按链接分区, 同一链接的事件有序
+//
+//	链接为 nil 或其它类型时为 0
+func busPartitionKey(value any) uint64 {
+	var remote xnetcommon.IRemote
+	switch event := value.(type) {
+	case *xnetcommon.Connect:
+		remote = event.IRemote
+	case *xnetcommon.Packet:
+		remote = event.IRemote
+	case *xnetcommon.Disconnect:
+		remote = event.IRemote
+	default:
+		return 0
+	}
+	switch v := remote.(type) {
+	case *xnettcp.Remote:
+		return xevent.PointerKey(v)
+	case *xnetkcp.Remote:
+		return xevent.PointerKey(v)
+	case *xnetwebsocket.Remote:
+		return xevent.PointerKey(v)
+	default:
+		return 0
+	}
+}
+
 // processEvent 处理事件
 func (p *Server) processEvent(value any) eventResult {
 	var err error
@@ -45,7 +101,9 @@ func (p *Server) processEvent(value any) eventResult {
 		err = event.IHandler.OnConnect(event.IRemote)
 	case *xnetcommon.Packet:
 		if event.IRemote.IsConnect() {
-			err = event.IHandler.OnPacket(event.IRemote, event.IPacket)
+			if err = xnetcommon.CheckMessageLimit(event.IRemote, event.IPacket); err == nil { // 消息限流
+				err = event.IHandler.OnPacket(event.IRemote, event.IPacket)
+			}
 		}
 	case *xnetcommon.Disconnect:
 		err = event.IHandler.OnDisconnect(event.IRemote)
@@ -1,28 +1,27 @@
 package server
 
 import (
+	"fmt"
+	"strings"
+
+	xconfig "github.com/75912001/xlib/config"
 	xcontrol "github.com/75912001/xlib/control"
 	xetcd "github.com/75912001/xlib/etcd"
+	xetcdconstants "github.com/75912001/xlib/etcd/constants"
 	xlog "github.com/75912001/xlib/log"
-	xtimer "github.com/75912001/xlib/timer"
-	"time"
+	xtime "github.com/75912001/xlib/time"
 )
 
 const ReportIntervalSecondDefault int64 = 30 // etcd-上报时间间隔 秒
 
-// etcdReportFunction etcd-上报
+// etcdReportFunction etcd-上报 [周期定时器]
+//
+//	关闭中时不再上报. 周期定时器在设置关闭中后删除
 func etcdReportFunction(args ...any) error {
 	defaultServer := args[0].(*Server)
-	defer func() {
-		if IsServerStopping() {
-			return
-		}
-		xtimer.GTimer.AddSecond(
-			xcontrol.NewCallBack(etcdReportFunction, defaultServer),
-			time.Now().Unix()+ReportIntervalSecondDefault,
-			defaultServer.GetActor(),
-		)
-	}()
+	if IsServerStopping() {
+		return nil
+	}
 	key := xetcd.GEtcd.GetKey()
 	value := defaultServer.genEtcdValue()
 	if _, err := xetcd.GEtcd.PutWithLease(key, value); err != nil {
@@ -30,3 +29,108 @@ func etcdReportFunction(args ...any) error {
 	}
 	return nil
 }
+
+// etcd 回调: 先处理时间偏移量, 日志等级, 再执行业务回调 [actor 协程]
+//
+//	args: [0]:key [1]:*xetcd.ValueJson(删除时没有)
+func (p *Server) etcdCallback(callback xcontrol.ICallBack) xcontrol.ICallBack {
+	return xcontrol.NewCallBack(func(args ...any) error {
+		key := args[0].(string)
+		var valueJson *xetcd.ValueJson
+		if 1 < len(args) {
+			valueJson = args[1].(*xetcd.ValueJson)
+		}
+		p.onEtcdTimeOffset(key, valueJson)
+		p.onEtcdLogLevel(key, valueJson)
+		if callback == nil {
+			return nil
+		}
+		return callback.Clone(args...).Execute()
+	})
+}
+
+// 时间偏移量
+//
+//	组的时间偏移量 key(xetcd.GenTimeOffsetKey) 变化时, 设置 xtime.GTimeMgr(xtimer 共用), 并立即上报
+//	同组其它服务上报的偏移量与本服务不一致时, 记录日志. 偏移量变化后, 各服务上报前会短暂不一致
+func (p *Server) onEtcdTimeOffset(key string, valueJson *xetcd.ValueJson) {
+	msgType, groupID, _, _ := xetcd.Parse(key)
+	if groupID != *xconfig.GConfigMgr.Base.GroupID {
+		return
+	}
+	switch msgType {
+	case xetcdconstants.WatchMsgTypeTimeOffset:
+		var offset int64
+		if valueJson != nil {
+			offset = valueJson.SecondOffset
+		}
+		p.setTimeOffset(offset)
+	case xetcdconstants.WatchMsgTypeServer:
+		if valueJson == nil || key == xetcd.GEtcd.GetKey() {
+			return
+		}
+		if offset := xtime.GTimeMgr.GetTimestampOffset(); valueJson.SecondOffset != offset {
+			xlog.GLog.Warnf("time offset mismatch. key:%v secondOffset:%v local:%v", key, valueJson.SecondOffset, offset)
+		}
+	}
+}
+
+// 设置时间偏移量 [actor 协程]
+func (p *Server) setTimeOffset(offset int64) {
+	old := xtime.GTimeMgr.GetTimestampOffset()
+	if old == offset {
+		return
+	}
+	xtime.GTimeMgr.SetTimestampOffset(offset)
+	xlog.GLog.Warnf("time offset changed. old:%v new:%v", old, offset)
+	_ = etcdReportFunction(p)
+}
+
+// 日志等级
+//
+//	本服务的日志等级 key(xetcd.GenLogLevelKey) 变化时, 按 LogLevel 设置. 配置无效时记录日志, 不做修改
+//	组内该服务名的 key(serviceID 0) 与本服务的 key 合并, 本服务的优先. 删除 key 即不再使用该 key 的配置
+//	之前由 etcd 设置, 合并后不再设置的名称恢复继承, 根恢复配置的等级. 其它名称(e.g.: SetLevelByName 设置的)不变
+func (p *Server) onEtcdLogLevel(key string, valueJson *xetcd.ValueJson) {
+	msgType, groupID, serviceName, serviceID := xetcd.Parse(key)
+	if msgType != xetcdconstants.WatchMsgTypeLogLevel ||
+		groupID != *xconfig.GConfigMgr.Base.GroupID ||
+		serviceName != *xconfig.GConfigMgr.Base.Name ||
+		(serviceID != 0 && serviceID != *xconfig.GConfigMgr.Base.ServerID) {
+		return
+	}
+	var spec string
+	if valueJson != nil {
+		spec = valueJson.LogLevel
+	}
+	if _, err := xlog.ParseLevelNames(spec); err != nil {
+		xlog.GLog.Errorf("log level key:%v spec:%v err:%v", key, spec, err)
+		return
+	}
+	oldNames, _ := xlog.ParseLevelNames(p.logLevelSpec())
+	idx := 0
+	if serviceID != 0 {
+		idx = 1
+	}
+	p.logLevelSpecs[idx] = spec
+	// 先恢复之前设置的名称, 再按合并后的配置设置. 一次 SetLevels, 全部解析成功后才设置
+	items := make([]string, 0, len(oldNames)+1)
+	for _, name := range oldNames {
+		if name == xlog.LevelRootName {
+			items = append(items, fmt.Sprintf("%v=%v", xlog.LevelRootName, *xconfig.GConfigMgr.Log.Level))
+			continue
+		}
+		items = append(items, name+"="+xlog.LevelInheritName)
+	}
+	items = append(items, p.logLevelSpec())
+	if err := xlog.GLog.SetLevels(strings.Join(items, ",")); err != nil {
+		xlog.GLog.Errorf("log level key:%v spec:%v err:%v", key, spec, err)
+		return
+	}
+	xlog.GLog.Warnf("log level changed. key:%v spec:%v merged:%v", key, spec, p.logLevelSpec())
+}
+
+// 合并 etcd 的日志等级配置, 本服务的在后(优先)
+func (p *Server) logLevelSpec() string {
+	return strings.Trim(p.logLevelSpecs[0]+","+p.logLevelSpecs[1], ",")
+}
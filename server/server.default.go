@@ -5,11 +5,14 @@ import (
 	"crypto/sha1"
 	"fmt"
 	xactor "github.com/75912001/xlib/actor"
+	xactorremote "github.com/75912001/xlib/actor/remote"
 	xconfig "github.com/75912001/xlib/config"
+	xconfigconstants "github.com/75912001/xlib/config/constants"
 	xcontrol "github.com/75912001/xlib/control"
 	xerror "github.com/75912001/xlib/error"
 	xetcd "github.com/75912001/xlib/etcd"
 	xetcdconstants "github.com/75912001/xlib/etcd/constants"
+	xevent "github.com/75912001/xlib/event"
 	xgrpcprotoregistry "github.com/75912001/xlib/grpc/proto/registry"
 	xgrpcselector "github.com/75912001/xlib/grpc/selector"
 	xgrpc "github.com/75912001/xlib/grpc/server"
@@ -22,7 +25,9 @@ import (
 	xruntime "github.com/75912001/xlib/runtime"
 	xruntimeconstants "github.com/75912001/xlib/runtime/constants"
 	xserverresources "github.com/75912001/xlib/server/resources"
+	xtime "github.com/75912001/xlib/time"
 	xtimer "github.com/75912001/xlib/timer"
+	xtimerdurable "github.com/75912001/xlib/timer/durable"
 	"github.com/pkg/errors"
 	"github.com/xdg-go/pbkdf2"
 	"github.com/xtaci/kcp-go/v5"
@@ -35,7 +40,12 @@ import (
 )
 
 type Server struct {
-	actor *Actor
+	actor        *Actor
+	busPartition *xevent.PartitionMgr // bus 模式-网络事件分区, nil:不分区
+	sessionMgr   *sessionMgr          // actor 模式-链接 actor 管理器, nil:非 actor 模式
+
+	etcdReportSchedule *xtimer.Schedule // etcd-定时上报, nil:未启用
+	logLevelSpecs      [2]string        // etcd-日志等级配置 [0]:组内该服务名的所有服务(serviceID 0) [1]:本服务 [actor 协程]
 
 	QuitChan chan struct{} // 退出信号, 用于关闭服务
 	quitOnce sync.Once     // 确保只关闭一次
@@ -83,6 +93,20 @@ func (p *Server) GetActor() *Actor {
 	return p.actor
 }
 
+// netIOut 网络事件的输出
+//
+//	actor 模式时, 每个链接一个子 actor 处理
+//	bus 模式且分区数量大于1时, 按链接分区并行处理, 否则由 actor 顺序处理
+func (p *Server) netIOut() xcontrol.IOut {
+	if p.sessionMgr != nil {
+		return p.sessionMgr
+	}
+	if p.busPartition != nil {
+		return p.busPartition
+	}
+	return p.GetActor()
+}
+
 func (p *Server) PreStart(ctx context.Context, opts ...*Options) error {
 	p.Options = mergeOptions(opts...)
 	if err := configure(p.Options); err != nil {
@@ -106,9 +130,19 @@ func (p *Server) PreStart(ctx context.Context, opts ...*Options) error {
 
 	var err error
 	// 日志
-	xlog.GLog, err = xlog.NewMgr(xlog.NewOptions().
+	logOptions := xlog.NewOptions()
+	for _, v := range xconfig.GConfigMgr.Log.RateLimits {
+		logOptions.WithRateLimit(*v.Level, *v.First, *v.Thereafter)
+	}
+	xlog.GLog, err = xlog.NewMgr(logOptions.
 		WithLevel(*xconfig.GConfigMgr.Log.Level).
+		WithFormat(*xconfig.GConfigMgr.Log.Format).
 		WithAbsPath(*xconfig.GConfigMgr.Log.AbsPath).
+		WithMaxSize(int64(*xconfig.GConfigMgr.Log.MaxSize)*1024*1024).
+		WithMaxBackups(*xconfig.GConfigMgr.Log.MaxBackups).
+		WithMaxAge(time.Duration(*xconfig.GConfigMgr.Log.MaxAge)*24*time.Hour).
+		WithCompress(*xconfig.GConfigMgr.Log.Compress).
+		WithMinDiskFree(uint64(*xconfig.GConfigMgr.Log.MinDiskFree)*1024*1024).
 		WithNamePrefix(fmt.Sprintf("%v.%v.%v", *xconfig.GConfigMgr.Base.GroupID, *xconfig.GConfigMgr.Base.Name, *xconfig.GConfigMgr.Base.ServerID)).
 		WithLevelCallBack(p.Options.LogCallback, xlog.LevelFatal, xlog.LevelError, xlog.LevelWarn, xlog.LevelInfo, xlog.LevelDebug, xlog.LevelTrace),
 	)
@@ -123,9 +157,32 @@ func (p *Server) PreStart(ctx context.Context, opts ...*Options) error {
 	// grpc 服务
 	if xconfig.GConfigMgr.Grpc.IsEnabled() {
 		p.GRPCServer = xgrpc.NewServer()
+		// 远程 actor, 经由 grpc 服务的内部流投递
+		xactorremote.GRemote = xactorremote.NewRemote(
+			xactorremote.NewNode(*xconfig.GConfigMgr.Base.GroupID, *xconfig.GConfigMgr.Base.Name, *xconfig.GConfigMgr.Base.ServerID),
+			xactor.GSystem,
+		)
+		xactorremote.GRemote.Register(p.GRPCServer.GrpcServer)
 	}
 
 	p.actor.Start()
+	if xconfig.GConfigMgr.Base.ProcessingModeIsActor() {
+		p.sessionMgr = newSessionMgr(p.actor, p.behavior)
+	} else if xconfig.GConfigMgr.Base.BusPartitionEnabled() {
+		p.busPartition = xevent.NewPartitionMgr(*xconfig.GConfigMgr.Base.BusPartitionCount, p.onBusPartition, busPartitionKey, newMailboxOptions())
+		p.busPartition.Start()
+	}
+
+	// 游戏日历
+	xtime.GCalendar, err = xtime.NewCalendar(xtime.NewCalendarOptions().
+		WithTimezone(*xconfig.GConfigMgr.Calendar.Timezone).
+		WithResetHour(*xconfig.GConfigMgr.Calendar.ResetHour).
+		WithWeekStart(time.Weekday(*xconfig.GConfigMgr.Calendar.WeekStart)).
+		WithMonthDay(*xconfig.GConfigMgr.Calendar.MonthDay),
+	)
+	if err != nil {
+		return errors.WithMessagef(err, "calendar new err. %v", xruntime.Location())
+	}
 
 	// 全局定时器
 	{
@@ -135,6 +192,14 @@ func (p *Server) PreStart(ctx context.Context, opts ...*Options) error {
 			return errors.Errorf("timer Start err:%v %v", err, xruntime.Location())
 		}
 	}
+	// 持久化定时任务, 加载存储中的任务
+	if p.Options.Scheduler != nil {
+		err = p.Options.Scheduler.Start(xtimerdurable.NewOptions().WithTimer(xtimer.GTimer).WithIOut(p.GetActor()))
+		if err != nil {
+			return errors.WithMessagef(err, "scheduler start err. %v", xruntime.Location())
+		}
+		xtimerdurable.GScheduler = p.Options.Scheduler
+	}
 
 	// 是否开启http采集分析
 	if xconfig.GConfigMgr.Base.PprofHttpPort != nil {
@@ -161,10 +226,10 @@ func (p *Server) Start(ctx context.Context) (err error) {
 			p.TCPServer = xnettcp.NewServer(p.Options.TCPHandler)
 			serverOptions := xnettcp.NewServerOptions().
 				WithListenAddress(*element.ListenAddr).
-				WithIOut(p.GetActor()).
+				WithIOut(p.netIOut()).
 				WithSendChanCapacity(*xconfig.GConfigMgr.Base.SendChannelCapacity).
 				WithHeaderStrategy(p.Options.HeaderStrategy)
-			serverOptions.WithNewPacketLimitFunc(xnetcommon.NewPackLimitDefault).
+			serverOptions.WithNewPacketLimitFunc(newPacketLimitFunc()).
 				WithMaxCntPerSec(*xconfig.GConfigMgr.Base.PacketLimitRecvCntPreSecond)
 			if err = p.TCPServer.Start(ctx, serverOptions); err != nil {
 				return errors.WithMessagef(err, "tcp server start err. %v", xruntime.Location())
@@ -179,10 +244,10 @@ func (p *Server) Start(ctx context.Context) (err error) {
 			}
 			kcpOpts := xnetkcp.NewOptions()
 			kcpOpts.WithListenAddress(*element.ListenAddr).
-				WithIOut(p.GetActor()).
+				WithIOut(p.netIOut()).
 				WithSendChanCapacity(*xconfig.GConfigMgr.Base.SendChannelCapacity).
 				WithHeaderStrategy(p.Options.HeaderStrategy).
-				WithNewPacketLimitFunc(xnetcommon.NewPackLimitDefault).
+				WithNewPacketLimitFunc(newPacketLimitFunc()).
 				WithMaxCntPerSec(*xconfig.GConfigMgr.Base.PacketLimitRecvCntPreSecond)
 			kcpOpts.WithBlockCrypt(blockCrypt).
 				WithFEC(true)
@@ -194,9 +259,9 @@ func (p *Server) Start(ctx context.Context) (err error) {
 			serverOptions := xnetwebsocket.NewServerOptions().
 				WithPattern(*element.Pattern).
 				WithListenAddress(*element.ListenAddr).
-				WithIOut(p.GetActor()).
+				WithIOut(p.netIOut()).
 				WithSendChanCapacity(*xconfig.GConfigMgr.Base.SendChannelCapacity)
-			serverOptions.WithNewPacketLimitFunc(xnetcommon.NewPackLimitDefault).
+			serverOptions.WithNewPacketLimitFunc(newPacketLimitFunc()).
 				WithMaxCntPerSec(*xconfig.GConfigMgr.Base.PacketLimitRecvCntPreSecond)
 			if err = p.WebSocket.Start(ctx, serverOptions); err != nil {
 				return errors.WithMessagef(err, "websocket server start err. %v", xruntime.Location())
@@ -206,7 +271,7 @@ func (p *Server) Start(ctx context.Context) (err error) {
 		}
 	}
 
-	stateTimerPrint(xtimer.GTimer, xlog.GLog, p.GetActor())
+	stateTimerPrint(xtimer.GTimer, xlog.GLog, p.GetActor(), p.busPartition)
 	////////////////////////////////////////////////////////////
 	// etcd
 	etcdKey := xetcd.GenKey(*xconfig.GConfigMgr.Base.ProjectName,
@@ -215,6 +280,9 @@ func (p *Server) Start(ctx context.Context) (err error) {
 	value := p.genEtcdValue()
 
 	opt := xetcd.MergeOptions(p.Options.Etcd)
+	opt.WithAddCallback(p.etcdCallback(opt.AddCallback)).
+		WithUpdateCallback(p.etcdCallback(opt.UpdateCallback)).
+		WithDelCallback(p.etcdCallback(opt.DelCallback))
 	defaultEtcd := xetcd.NewEtcd(
 		xetcd.NewOptions().
 			WithEndpoints(xconfig.GConfigMgr.Etcd.Endpoints).
@@ -234,20 +302,30 @@ func (p *Server) Start(ctx context.Context) (err error) {
 		return errors.WithMessagef(err, "etcd keepAlive err. %v", xruntime.Location())
 	}
 	// etcd-定时上报
-	xtimer.GTimer.AddSecond(xcontrol.NewCallBack(etcdReportFunction, p),
-		time.Now().Unix()+ReportIntervalSecondDefault,
+	if p.etcdReportSchedule, err = xtimer.GTimer.AddRepeatSecond(xcontrol.NewCallBack(etcdReportFunction, p),
+		ReportIntervalSecondDefault,
 		p.GetActor(),
-	)
+	); err != nil {
+		return errors.WithMessagef(err, "etcd report timer err. %v", xruntime.Location())
+	}
 	////////////////////////////////////////////////////////////
 	runtime.GC()
 	return nil
 }
 
+// 根据配置选择包限流
+func newPacketLimitFunc() func(maxCntPerSec uint32) xnetcommon.IPacketLimit {
+	if *xconfig.GConfigMgr.Base.PacketLimitType == xconfigconstants.PacketLimitTypeTokenBucket {
+		return xnetcommon.NewPacketLimitTokenBucket
+	}
+	return xnetcommon.NewPackLimitDefault
+}
+
 func (p *Server) genEtcdValue() string {
 	valueJson := &xetcd.ValueJson{
 		Version:       *xconfig.GConfigMgr.Base.Version,
 		AvailableLoad: xserverresources.GResources.GetAvailableLoad(),
-		SecondOffset:  0,
+		SecondOffset:  xtime.GTimeMgr.GetTimestampOffset(),
 	}
 	for _, v := range xconfig.GConfigMgr.Net {
 		valueJson.ServerNet = append(valueJson.ServerNet,
@@ -285,10 +363,16 @@ func (p *Server) PostStart() error {
 	}
 	// 设置为关闭中
 	SetServerStopping()
+	if p.etcdReportSchedule != nil { // 停止 etcd-定时上报
+		xtimer.GTimer.DelSchedule(p.etcdReportSchedule)
+	}
 
 	if _, err := p.actor.SendMsgSync(xactor.NewMsg(context.Background(), xactor.SystemReservedCommand_Stop)); err != nil {
 		xlog.GLog.Warnf("actor stop err:%v ", err)
 	}
+	if p.busPartition != nil {
+		p.busPartition.Stop()
+	}
 
 	err := p.Derived.Stop()
 	if err != nil {
@@ -318,6 +402,9 @@ func (p *Server) Stop() (err error) {
 			errs = append(errs, errors.WithMessagef(errEtcd, "etcd stop err. %v", xruntime.Location()))
 		}
 	}
+	if xactorremote.GRemote != nil { // 先关闭内部流, 否则 grpc GracefulStop 会等待
+		xactorremote.GRemote.Stop()
+	}
 	if p.GRPCServer != nil {
 		if errGrpc := p.GRPCServer.Stop(); errGrpc != nil {
 			errs = append(errs, errors.WithMessagef(errGrpc, "grpc server stop err. %v", xruntime.Location()))
@@ -332,6 +419,11 @@ func (p *Server) Stop() (err error) {
 	if p.WebSocket != nil {
 		p.WebSocket.Stop()
 	}
+	if xtimerdurable.GScheduler != nil {
+		if errScheduler := xtimerdurable.GScheduler.Stop(); errScheduler != nil {
+			errs = append(errs, errors.WithMessagef(errScheduler, "scheduler stop err. %v", xruntime.Location()))
+		}
+	}
 	if xtimer.GTimer != nil {
 		xtimer.GTimer.Stop()
 	}
@@ -7,6 +7,7 @@ import (
 	xnetcommon "github.com/75912001/xlib/net/common"
 	xpacket "github.com/75912001/xlib/packet"
 	xruntime "github.com/75912001/xlib/runtime"
+	xtimerdurable "github.com/75912001/xlib/timer/durable"
 	"github.com/pkg/errors"
 )
 
@@ -17,6 +18,7 @@ type Options struct {
 	LogCallback      xcontrol.ICallBack
 	HeaderStrategy   xpacket.IHeaderStrategy
 	Etcd             *xetcd.Options
+	Scheduler        *xtimerdurable.Scheduler // 持久化定时任务, nil:不使用. PreStart 中加载任务, 到期回调在服务 actor 中执行
 }
 
 // NewServerOptions 新的ServerOptions
@@ -57,6 +59,11 @@ func (p *Options) WithEtcd(etcd *xetcd.Options) *Options {
 	return p
 }
 
+func (p *Options) WithScheduler(scheduler *xtimerdurable.Scheduler) *Options {
+	p.Scheduler = scheduler
+	return p
+}
+
 func mergeOptions(opts ...*Options) *Options {
 	newOptions := NewServerOptions()
 	for _, opt := range opts {
@@ -81,6 +88,9 @@ func mergeOptions(opts ...*Options) *Options {
 		if opt.Etcd != nil {
 			newOptions.WithEtcd(opt.Etcd)
 		}
+		if opt.Scheduler != nil {
+			newOptions.WithScheduler(opt.Scheduler)
+		}
 	}
 	return newOptions
 }
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	xactor "github.com/75912001/xlib/actor"
+	xerror "github.com/75912001/xlib/error"
+	xlog "github.com/75912001/xlib/log"
+	xnetcommon "github.com/75912001/xlib/net/common"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// SessionKeyBase 链接 actor 的 key 起始值, 避免与业务创建的 server 子 actor 冲突
+const SessionKeyBase uint64 = 1 << 63
+
+// sessionMgr 链接 actor 管理器 [actor 模式]
+//
+//	每个链接一个 server.Actor 的子 actor, 路径为 /server/session.${序号}
+//	同一链接的 Connect, Packet, Disconnect 按顺序投递到该子 actor, 不同链接并行处理
+//	其它事件投递到 server.Actor
+//	子 actor 由 server.Actor 异步创建, 创建完成前的事件暂存, 不阻塞网络协程
+type sessionMgr struct {
+	actor    *Actor
+	behavior xactor.Behavior
+	seq      atomic.Uint64
+	sessions sync.Map // key: xnetcommon.IRemote, value: *session
+}
+
+// 一个链接
+type session struct {
+	mu           sync.Mutex
+	actor        *xactor.Actor[uint64] // nil: 创建中
+	buffer       []any                 // 创建中, 暂存的事件
+	disconnected bool                  // 已收到 Disconnect
+}
+
+func newSessionMgr(actor *Actor, behavior xactor.Behavior) *sessionMgr {
+	return &sessionMgr{
+		actor:    actor,
+		behavior: behavior,
+	}
+}
+
+// Send 实现 xcontrol.IOut
+func (p *sessionMgr) Send(events ...any) {
+	for _, value := range events {
+		switch event := value.(type) {
+		case *xnetcommon.Connect:
+			s := &session{buffer: []any{event}}
+			p.sessions.Store(event.IRemote, s)
+			p.spawn(event.IRemote, s)
+		case *xnetcommon.Packet:
+			if value, ok := p.sessions.Load(event.IRemote); ok {
+				p.deliver(value.(*session), event, false)
+			}
+		case *xnetcommon.Disconnect:
+			value, ok := p.sessions.LoadAndDelete(event.IRemote)
+			if !ok {
+				continue
+			}
+			p.deliver(value.(*session), event, true)
+		default:
+			p.actor.Send(value)
+		}
+	}
+}
+
+// Len 链接 actor 数量
+func (p *sessionMgr) Len() (cnt int) {
+	p.sessions.Range(func(_, _ any) bool {
+		cnt++
+		return true
+	})
+	return cnt
+}
+
+// 投递到链接 actor. 创建中时暂存
+//
+//	disconnect: 是否为 Disconnect. 投递后停止链接 actor
+func (p *sessionMgr) deliver(s *session, event any, disconnect bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disconnected = s.disconnected || disconnect
+	if s.actor == nil {
+		s.buffer = append(s.buffer, event)
+		return
+	}
+	s.actor.Send(event)
+	if s.disconnected {
+		p.removeSession(s.actor)
+	}
+}
+
+// 停止链接 actor. 子 actor 处理完 Disconnect 后停止
+func (p *sessionMgr) removeSession(actor *xactor.Actor[uint64]) {
+	p.actor.SendMsg(xactor.NewMsg(context.Background(), xactor.SystemReservedCommand_RemoveChild, actor.GetKey()))
+}
+
+// 创建链接 actor. 在 server.Actor 中创建, 完成后投递暂存的事件
+func (p *sessionMgr) spawn(remote xnetcommon.IRemote, s *session) {
+	seq := p.seq.Add(1)
+	msg := xactor.NewMsg(context.Background(), xactor.SystemReservedCommand_Spawn,
+		SessionKeyBase|seq,
+		p.behavior,
+		xactor.NewOptions().
+			WithName(fmt.Sprintf("session.%v", seq)),
+	)
+	msg.WithReplyFunc(func(resp any, err error) {
+		actor, ok := resp.(*xactor.Actor[uint64])
+		if err == nil && !ok {
+			err = errors.WithMessagef(xerror.Mismatch, "resp:%T %v", resp, xruntime.Location())
+		}
+		if err != nil {
+			xlog.PrintfErr("session spawn remote:%p seq:%v err:%v", remote, seq, err)
+			p.sessions.CompareAndDelete(remote, s)
+			remote.Stop()
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.actor = actor
+		actor.Send(s.buffer...)
+		s.buffer = nil
+		if s.disconnected {
+			p.removeSession(actor)
+		}
+	})
+	p.actor.SendMsg(msg)
+}
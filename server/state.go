@@ -3,6 +3,7 @@ package server
 import (
 	xconfig "github.com/75912001/xlib/config"
 	xcontrol "github.com/75912001/xlib/control"
+	xevent "github.com/75912001/xlib/event"
 	xlog "github.com/75912001/xlib/log"
 	xserverconstants "github.com/75912001/xlib/server/constants"
 	xserverresources "github.com/75912001/xlib/server/resources"
@@ -10,23 +11,34 @@ import (
 	"runtime"
 	"runtime/debug"
 	"sync/atomic"
-	"time"
 )
 
-func stateTimerPrint(timer xtimer.ITimer, l xlog.ILog, out xcontrol.IOut) {
-	defaultCallBack := xcontrol.NewCallBack(timeOut, timer, l, out)
-	_ = timer.AddSecond(defaultCallBack, time.Now().Unix()+xserverconstants.ServerInfoTimeOutSec, out)
+func stateTimerPrint(timer xtimer.ITimer, l xlog.ILog, out xcontrol.IOut, busPartition *xevent.PartitionMgr) {
+	defaultCallBack := xcontrol.NewCallBack(timeOut, l, out, busPartition)
+	if _, err := timer.AddRepeatSecond(defaultCallBack, xserverconstants.ServerInfoTimeOutSec, out); err != nil {
+		l.Errorf("state timer add err:%v", err)
+	}
 }
 
 // 服务信息 打印
 func timeOut(args ...any) error {
 	s := debug.GCStats{}
 	debug.ReadGCStats(&s)
-	l := args[1].(xlog.ILog)
+	l := args[0].(xlog.ILog)
 	l.Infof("goroutineCnt:%v, numGC:%d, lastGC:%v, GCPauseTotal:%v availableLoad/AvailableLoad:%v/%v",
 		runtime.NumGoroutine(), s.NumGC, s.LastGC, s.PauseTotal, xserverresources.GResources.GetAvailableLoad(),
 		*xconfig.GConfigMgr.Base.AvailableLoad)
-	stateTimerPrint(args[0].(xtimer.ITimer), l, args[2].(xcontrol.IOut))
+	if actor, ok := args[1].(*Actor); ok { // 邮箱统计
+		l.Infof("actor mailbox %v", actor.GetMailboxStatistics())
+		actor.GetMailboxStatistics().ResetHighWater()
+	}
+	busPartition := args[2].(*xevent.PartitionMgr)
+	if busPartition != nil { // 分区统计
+		for i := uint32(0); i < busPartition.PartitionCount(); i++ {
+			l.Infof("bus partition:%v %v", i, busPartition.GetStatistics(i))
+			busPartition.GetStatistics(i).ResetHighWater()
+		}
+	}
 	return nil
 }
 
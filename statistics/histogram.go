@@ -0,0 +1,100 @@
+package statistics
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// 直方图-桶数量. 第 i 个桶的上限为 2^i 微秒, 最后一个桶(第 31 个)无上限, 即超过 2^30 微秒(约 17.9 分钟)的时长
+const histogramBucketCount = 32
+
+// Histogram 时长直方图 (指数分桶, 微秒)
+//
+//	并发安全, 无锁. 百分位数取所在桶的上限, 误差在 2 倍以内
+type Histogram struct {
+	buckets [histogramBucketCount]atomic.Uint64
+	count   atomic.Uint64
+	max     atomic.Int64 // 最大值-纳秒
+}
+
+func NewHistogram() *Histogram {
+	return &Histogram{}
+}
+
+// Record 记录一个时长
+func (p *Histogram) Record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	us := uint64(d / time.Microsecond)
+	idx := 0
+	if us > 1 {
+		idx = bits.Len64(us - 1) // 向上取到 2 的幂
+	}
+	if histogramBucketCount <= idx {
+		idx = histogramBucketCount - 1
+	}
+	p.buckets[idx].Add(1)
+	p.count.Add(1)
+	for {
+		old := p.max.Load()
+		if int64(d) <= old || p.max.CompareAndSwap(old, int64(d)) {
+			break
+		}
+	}
+}
+
+// Count 记录总数
+func (p *Histogram) Count() uint64 {
+	return p.count.Load()
+}
+
+// Max 最大值
+func (p *Histogram) Max() time.Duration {
+	return time.Duration(p.max.Load())
+}
+
+// Percentile 百分位数
+//
+//	q: (0,1] e.g.: 0.5, 0.99
+func (p *Histogram) Percentile(q float64) time.Duration {
+	total := p.count.Load()
+	if total == 0 {
+		return 0
+	}
+	if q <= 0 {
+		q = 0
+	}
+	if 1 < q {
+		q = 1
+	}
+	target := uint64(q * float64(total))
+	if target == 0 {
+		target = 1
+	}
+	var cumulative uint64
+	for idx := range p.buckets {
+		cumulative += p.buckets[idx].Load()
+		if target <= cumulative {
+			if idx == histogramBucketCount-1 {
+				return p.Max()
+			}
+			upper := time.Duration(uint64(1)<<uint(idx)) * time.Microsecond
+			if maxValue := p.Max(); maxValue < upper {
+				return maxValue
+			}
+			return upper
+		}
+	}
+	return p.Max()
+}
+
+// Reset 重置
+func (p *Histogram) Reset() {
+	for idx := range p.buckets {
+		p.buckets[idx].Store(0)
+	}
+	p.count.Store(0)
+	p.max.Store(0)
+}
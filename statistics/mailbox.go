@@ -0,0 +1,81 @@
+package statistics
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Mailbox 邮箱(事件队列)统计数据
+//
+//	并发安全
+type Mailbox struct {
+	length    atomic.Int64  // 当前队列长度
+	highWater atomic.Int64  // 队列长度-最高水位
+	dropped   atomic.Uint64 // 丢弃数量 (丢弃最旧)
+	rejected  atomic.Uint64 // 拒绝数量 (满/超时)
+	Latency   *Histogram    // 入队到开始处理的时长
+}
+
+func NewMailbox() *Mailbox {
+	return &Mailbox{
+		Latency: NewHistogram(),
+	}
+}
+
+// OnEnqueue 入队后调用
+//
+//	length: 入队后的队列长度
+func (p *Mailbox) OnEnqueue(length int64) {
+	p.length.Store(length)
+	for {
+		old := p.highWater.Load()
+		if length <= old || p.highWater.CompareAndSwap(old, length) {
+			break
+		}
+	}
+}
+
+// OnDequeue 出队后调用
+//
+//	length: 出队后的队列长度
+//	enqueueTime: 入队时间
+func (p *Mailbox) OnDequeue(length int64, enqueueTime time.Time) {
+	p.length.Store(length)
+	p.Latency.Record(time.Since(enqueueTime))
+}
+
+func (p *Mailbox) OnDrop(cnt uint64) {
+	p.dropped.Add(cnt)
+}
+
+func (p *Mailbox) OnReject(cnt uint64) {
+	p.rejected.Add(cnt)
+}
+
+func (p *Mailbox) GetLength() int64 {
+	return p.length.Load()
+}
+
+func (p *Mailbox) GetHighWater() int64 {
+	return p.highWater.Load()
+}
+
+func (p *Mailbox) GetDropped() uint64 {
+	return p.dropped.Load()
+}
+
+func (p *Mailbox) GetRejected() uint64 {
+	return p.rejected.Load()
+}
+
+// ResetHighWater 重置最高水位为当前长度, 用于按周期观察
+func (p *Mailbox) ResetHighWater() {
+	p.highWater.Store(p.length.Load())
+}
+
+func (p *Mailbox) String() string {
+	return fmt.Sprintf("length:%v highWater:%v dropped:%v rejected:%v latency[p50:%v p90:%v p99:%v max:%v]",
+		p.GetLength(), p.GetHighWater(), p.GetDropped(), p.GetRejected(),
+		p.Latency.Percentile(0.5), p.Latency.Percentile(0.9), p.Latency.Percentile(0.99), p.Latency.Max())
+}
@@ -0,0 +1,168 @@
+package time
+
+import (
+	"time"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// GCalendar 游戏日历 [default]: 本地时区, 0 点重置, 周一, 每月 1 日
+var GCalendar = mustCalendar(NewCalendar())
+
+// ResetType 重置周期
+type ResetType uint8
+
+const (
+	ResetDaily   ResetType = 0 // 每日
+	ResetWeekly  ResetType = 1 // 每周
+	ResetMonthly ResetType = 2 // 每月
+)
+
+// Calendar 游戏日历
+//
+//	按配置的时区和重置时刻计算 每日/每周/每月 的重置时刻
+//	时间戳均为叠加偏移量的时间戳(秒), 当前时间为 timeMgr 的当前时间叠加其偏移量
+//	游戏日: 从当天重置时刻到次日重置时刻 e.g.: 05:00 重置时, 01-02 03:00 属于 01-01
+//	夏令时: 重置时刻按当地时间计算, 该日不存在该时刻(e.g.: 跳过的 02:00)时取 time.Date 规范化的时刻
+type Calendar struct {
+	location  *time.Location
+	resetHour int
+	weekStart time.Weekday
+	monthDay  int
+	timeMgr   *Mgr
+}
+
+// NewCalendar 新的日历
+func NewCalendar(opts ...*CalendarOptions) (*Calendar, error) {
+	options := NewCalendarOptions().merge(opts...)
+	if err := options.configure(); err != nil {
+		return nil, errors.WithMessagef(err, "configure failed. %v", xruntime.Location())
+	}
+	location, err := time.LoadLocation(*options.timezone)
+	if err != nil {
+		return nil, errors.WithMessagef(xerror.Param, "timezone:%v err:%v %v", *options.timezone, err, xruntime.Location())
+	}
+	return &Calendar{
+		location:  location,
+		resetHour: int(*options.resetHour),
+		weekStart: *options.weekStart,
+		monthDay:  int(*options.monthDay),
+		timeMgr:   options.timeMgr,
+	}, nil
+}
+
+// 创建失败时 panic. 用于默认选项(不会失败)的初始化
+func mustCalendar(calendar *Calendar, err error) *Calendar {
+	if err != nil {
+		panic(err)
+	}
+	return calendar
+}
+
+// GetLocation 时区
+func (p *Calendar) GetLocation() *time.Location {
+	return p.location
+}
+
+// Now 当前时间戳(叠加偏移量)
+func (p *Calendar) Now() int64 {
+	return p.timeMgr.NowTime().Unix() + p.timeMgr.GetTimestampOffset()
+}
+
+// LastReset 当前周期的开始(最近一次重置)时刻
+func (p *Calendar) LastReset(resetType ResetType) int64 {
+	return p.LastResetAt(resetType, p.Now())
+}
+
+// NextReset 下一次重置时刻
+func (p *Calendar) NextReset(resetType ResetType) int64 {
+	return p.NextResetAt(resetType, p.Now())
+}
+
+// LastResetAt timestamp 所在周期的开始时刻, 不晚于 timestamp
+func (p *Calendar) LastResetAt(resetType ResetType, timestamp int64) int64 {
+	year, month, day := p.lastResetDate(resetType, timestamp)
+	return p.resetTime(year, month, day).Unix()
+}
+
+// NextResetAt timestamp 之后的第一次重置时刻, 晚于 timestamp
+func (p *Calendar) NextResetAt(resetType ResetType, timestamp int64) int64 {
+	year, month, day := p.lastResetDate(resetType, timestamp)
+	switch resetType {
+	case ResetWeekly:
+		day += 7
+	case ResetMonthly:
+		month++
+	default:
+		day++
+	}
+	return p.resetTime(year, month, day).Unix()
+}
+
+// HasReset (from, to] 之间是否发生过重置 e.g.: 登录时判断离线期间是否需要重置
+func (p *Calendar) HasReset(resetType ResetType, from int64, to int64) bool {
+	return from < to && from < p.LastResetAt(resetType, to)
+}
+
+// ResetCount (from, to] 之间发生的重置次数 e.g.: 离线期间按天累计的奖励
+func (p *Calendar) ResetCount(resetType ResetType, from int64, to int64) int {
+	if to <= from {
+		return 0
+	}
+	fromYear, fromMonth, fromDay := p.lastResetDate(resetType, from)
+	toYear, toMonth, toDay := p.lastResetDate(resetType, to)
+	switch resetType {
+	case ResetMonthly:
+		return (toYear-fromYear)*12 + int(toMonth-fromMonth)
+	case ResetWeekly:
+		return daysBetween(fromYear, fromMonth, fromDay, toYear, toMonth, toDay) / 7
+	default:
+		return daysBetween(fromYear, fromMonth, fromDay, toYear, toMonth, toDay)
+	}
+}
+
+// GameDate timestamp 所在的游戏日
+func (p *Calendar) GameDate(timestamp int64) (year int, month time.Month, day int) {
+	return p.lastResetDate(ResetDaily, timestamp)
+}
+
+// GameYMD timestamp 所在的游戏日 e.g.: 20210819
+func (p *Calendar) GameYMD(timestamp int64) int {
+	year, month, day := p.GameDate(timestamp)
+	return year*10000 + int(month)*100 + day
+}
+
+// 重置时刻: 当地日期 + 重置时
+func (p *Calendar) resetTime(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, p.resetHour, 0, 0, 0, p.location)
+}
+
+// timestamp 所在周期开始的当地日期
+func (p *Calendar) lastResetDate(resetType ResetType, timestamp int64) (year int, month time.Month, day int) {
+	t := time.Unix(timestamp, 0).In(p.location)
+	year, month, day = t.Date()
+	if timestamp < p.resetTime(year, month, day).Unix() { // 未到当天重置时刻, 属于前一个游戏日
+		year, month, day = time.Date(year, month, day-1, 0, 0, 0, 0, time.UTC).Date()
+	}
+	switch resetType {
+	case ResetWeekly:
+		weekday := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday()
+		diff := (int(weekday) - int(p.weekStart) + 7) % 7
+		year, month, day = time.Date(year, month, day-diff, 0, 0, 0, 0, time.UTC).Date()
+	case ResetMonthly:
+		if day < p.monthDay {
+			month--
+		}
+		year, month, day = time.Date(year, month, p.monthDay, 0, 0, 0, 0, time.UTC).Date()
+	}
+	return year, month, day
+}
+
+// 两个日期相差的天数
+func daysBetween(fromYear int, fromMonth time.Month, fromDay int, toYear int, toMonth time.Month, toDay int) int {
+	from := time.Date(fromYear, fromMonth, fromDay, 0, 0, 0, 0, time.UTC)
+	to := time.Date(toYear, toMonth, toDay, 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
@@ -0,0 +1,105 @@
+package time
+
+import (
+	"time"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// CalendarOptions 日历选项
+type CalendarOptions struct {
+	timezone  *string       // IANA 时区 e.g.: "Asia/Shanghai", "UTC", "Local" [default]: "Local"
+	resetHour *uint32       // 重置时刻(时) [0,23] [default]: 0
+	weekStart *time.Weekday // 每周重置日 [default]: time.Monday
+	monthDay  *uint32       // 每月重置日 [1,28] [default]: 1
+	timeMgr   *Mgr          // 时间管理器, 当前时间及偏移量 [default]: GTimeMgr
+}
+
+// NewCalendarOptions 新的CalendarOptions
+func NewCalendarOptions() *CalendarOptions {
+	return &CalendarOptions{}
+}
+
+func (p *CalendarOptions) WithTimezone(timezone string) *CalendarOptions {
+	p.timezone = &timezone
+	return p
+}
+
+func (p *CalendarOptions) WithResetHour(resetHour uint32) *CalendarOptions {
+	p.resetHour = &resetHour
+	return p
+}
+
+func (p *CalendarOptions) WithWeekStart(weekStart time.Weekday) *CalendarOptions {
+	p.weekStart = &weekStart
+	return p
+}
+
+func (p *CalendarOptions) WithMonthDay(monthDay uint32) *CalendarOptions {
+	p.monthDay = &monthDay
+	return p
+}
+
+func (p *CalendarOptions) WithTimeMgr(timeMgr *Mgr) *CalendarOptions {
+	p.timeMgr = timeMgr
+	return p
+}
+
+func (p *CalendarOptions) merge(opts ...*CalendarOptions) *CalendarOptions {
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.timezone != nil {
+			p.timezone = opt.timezone
+		}
+		if opt.resetHour != nil {
+			p.resetHour = opt.resetHour
+		}
+		if opt.weekStart != nil {
+			p.weekStart = opt.weekStart
+		}
+		if opt.monthDay != nil {
+			p.monthDay = opt.monthDay
+		}
+		if opt.timeMgr != nil {
+			p.timeMgr = opt.timeMgr
+		}
+	}
+	return p
+}
+
+// 配置
+func (p *CalendarOptions) configure() error {
+	if p.timezone == nil {
+		timezone := "Local"
+		p.timezone = &timezone
+	}
+	if p.resetHour == nil {
+		var resetHour uint32
+		p.resetHour = &resetHour
+	}
+	if 23 < *p.resetHour {
+		return errors.WithMessagef(xerror.Param, "resetHour:%v %v", *p.resetHour, xruntime.Location())
+	}
+	if p.weekStart == nil {
+		weekStart := time.Monday
+		p.weekStart = &weekStart
+	}
+	if *p.weekStart < time.Sunday || time.Saturday < *p.weekStart {
+		return errors.WithMessagef(xerror.Param, "weekStart:%v %v", *p.weekStart, xruntime.Location())
+	}
+	if p.monthDay == nil {
+		monthDay := uint32(1)
+		p.monthDay = &monthDay
+	}
+	if *p.monthDay < 1 || 28 < *p.monthDay {
+		return errors.WithMessagef(xerror.Param, "monthDay:%v %v", *p.monthDay, xruntime.Location())
+	}
+	if p.timeMgr == nil {
+		p.timeMgr = GTimeMgr
+	}
+	return nil
+}
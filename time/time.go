@@ -4,7 +4,9 @@
 package time
 
 import (
+	xclock "github.com/75912001/xlib/clock"
 	xcontrol "github.com/75912001/xlib/control"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,22 +17,29 @@ type Mgr struct {
 	timestampSecond       int64                  // 上一次调用Update更新的时间戳-秒
 	timestampMillisecond  int64                  // 上一次调用Update更新的时间戳-毫秒
 	time                  time.Time              // 上一次调用Update更新的时间
-	timestampSecondOffset int64                  // 时间戳偏移量-秒
+	timestampSecondOffset atomic.Int64           // 时间戳偏移量-秒
 	UTCSwitch             xcontrol.ISwitchButton // UTC 时间开关
+	clock                 xclock.IClock          // 时钟 [default]: xclock.GClock
 }
 
 func NewMgr() *Mgr {
 	return &Mgr{
 		UTCSwitch: xcontrol.NewSwitchButton(false),
+		clock:     xclock.GClock,
 	}
 }
 
+// SetClock 设置时钟 e.g.: 测试时使用虚拟时钟
+func (p *Mgr) SetClock(clock xclock.IClock) {
+	p.clock = clock
+}
+
 // NowTime 获取当前时间
 func (p *Mgr) NowTime() time.Time {
 	if p.UTCSwitch.IsOn() {
-		return time.Now().UTC()
+		return p.clock.Now().UTC()
 	}
-	return time.Now()
+	return p.clock.Now()
 }
 
 // Update 更新时间管理器中的,当前时间
@@ -42,17 +51,17 @@ func (p *Mgr) Update() {
 
 // ShadowTimestamp 叠加偏移量的时间戳-秒
 func (p *Mgr) ShadowTimestamp() int64 {
-	return p.timestampSecond + p.timestampSecondOffset
+	return p.timestampSecond + p.timestampSecondOffset.Load()
 }
 
 // SetTimestampOffset 设置 时间戳偏移量-秒
 func (p *Mgr) SetTimestampOffset(offset int64) {
-	p.timestampSecondOffset = offset
+	p.timestampSecondOffset.Store(offset)
 }
 
 // GetTimestampOffset 获取 时间戳偏移量-秒
 func (p *Mgr) GetTimestampOffset() int64 {
-	return p.timestampSecondOffset
+	return p.timestampSecondOffset.Load()
 }
 
 // GetMillisecond 获取-毫秒时间戳
@@ -12,4 +12,5 @@ type MillisecondType uint32 // 毫秒级定时器使用类型
 const (
 	MillisecondTypeList    MillisecondType = 0 // 毫秒级定时器 使用 list.List 实现. 尾部插入-O(1) 插入排序-O(n) 头部移除-O(1)
 	MillisecondTypeMinHeap MillisecondType = 1 // 毫秒级定时器 使用小顶堆实现. Push-O(log n), Pop-O(log n)
+	MillisecondTypeWheel   MillisecondType = 2 // 毫秒级定时器 使用分层时间轮实现. 加入-O(1) 删除-O(1)(标记) 到期-O(1)/刻度
 )
@@ -0,0 +1,169 @@
+package timer
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// Cron cron 表达式
+//
+//	5 个字段: 分 时 日 月 周, e.g.: "0 5 * * *" 每天 05:00
+//	支持: * , - / 及 @yearly @monthly @weekly @daily @hourly
+//	周: [0,7], 0 和 7 都是周日
+//	日 和 周 都不以 * 开头时, 满足其一即可; 否则需都满足 (与 crontab 一致) e.g.: "0 0 */2 * 1" 为单数日且周一
+type Cron struct {
+	spec    string
+	minute  uint64 // 位图 [0,59]
+	hour    uint64 // 位图 [0,23]
+	dom     uint64 // 位图 [1,31]
+	month   uint64 // 位图 [1,12]
+	dow     uint64 // 位图 [0,6]
+	domStar bool   // 日 以 * 开头 e.g.: * */2
+	dowStar bool   // 周 以 * 开头
+}
+
+// cron 描述符
+var cronDescriptors = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// 时间范围 [min,max]
+type cronBounds struct {
+	min, max uint64
+}
+
+var (
+	cronMinute = cronBounds{0, 59}
+	cronHour   = cronBounds{0, 23}
+	cronDom    = cronBounds{1, 31}
+	cronMonth  = cronBounds{1, 12}
+	cronDow    = cronBounds{0, 7}
+)
+
+// ParseCron 解析 cron 表达式
+func ParseCron(spec string) (*Cron, error) {
+	expr := strings.TrimSpace(spec)
+	if descriptor, ok := cronDescriptors[expr]; ok {
+		expr = descriptor
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.WithMessagef(xerror.Param, "cron spec:%v fields:%v %v", spec, len(fields), xruntime.Location())
+	}
+	cron := &Cron{
+		spec:    spec,
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	for i, pair := range []struct {
+		bits   *uint64
+		bounds cronBounds
+	}{
+		{&cron.minute, cronMinute},
+		{&cron.hour, cronHour},
+		{&cron.dom, cronDom},
+		{&cron.month, cronMonth},
+		{&cron.dow, cronDow},
+	} {
+		if *pair.bits, err = parseCronField(fields[i], pair.bounds); err != nil {
+			return nil, errors.WithMessagef(err, "cron spec:%v field:%v %v", spec, fields[i], xruntime.Location())
+		}
+	}
+	if cron.dow&(1<<7) != 0 { // 7 => 0 周日
+		cron.dow = cron.dow&^(1<<7) | 1
+	}
+	return cron, nil
+}
+
+// 解析字段. e.g.: * */5 1,2,3 1-5 1-10/2 3/15
+func parseCronField(field string, bounds cronBounds) (bits uint64, err error) {
+	for _, item := range strings.Split(field, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(item, "/")
+		low, high := bounds.min, bounds.max
+		step := uint64(1)
+		if hasStep {
+			if step, err = strconv.ParseUint(stepExpr, 10, 64); err != nil || step == 0 {
+				return 0, errors.WithMessagef(xerror.Param, "step:%v %v", stepExpr, xruntime.Location())
+			}
+		}
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			lowExpr, highExpr, _ := strings.Cut(rangeExpr, "-")
+			if low, err = strconv.ParseUint(lowExpr, 10, 64); err != nil {
+				return 0, errors.WithMessagef(xerror.Param, "range:%v %v", rangeExpr, xruntime.Location())
+			}
+			if high, err = strconv.ParseUint(highExpr, 10, 64); err != nil {
+				return 0, errors.WithMessagef(xerror.Param, "range:%v %v", rangeExpr, xruntime.Location())
+			}
+		default:
+			if low, err = strconv.ParseUint(rangeExpr, 10, 64); err != nil {
+				return 0, errors.WithMessagef(xerror.Param, "value:%v %v", rangeExpr, xruntime.Location())
+			}
+			if !hasStep { // 单个值; 有步长时 e.g.: 3/15 => 3-max/15
+				high = low
+			}
+		}
+		if low < bounds.min || bounds.max < high || high < low {
+			return 0, errors.WithMessagef(xerror.OutOfRange, "item:%v bounds:[%v,%v] %v", item, bounds.min, bounds.max, xruntime.Location())
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// String cron 表达式
+func (p *Cron) String() string {
+	return p.spec
+}
+
+// Next 晚于 t 的下一个触发时刻(按 t 的时区)
+//
+//	5 年内没有触发时刻时(e.g.: "0 0 30 2 *"), 返回零值
+func (p *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if p.month&(1<<uint(t.Month())) == 0 { // 下个月 1 日 00:00
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !p.matchDay(t) { // 下一日 00:00
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if p.hour&(1<<uint(t.Hour())) == 0 { // 下一时 00 分
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if p.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// 日 是否匹配
+func (p *Cron) matchDay(t time.Time) bool {
+	domMatch := p.dom&(1<<uint(t.Day())) != 0
+	dowMatch := p.dow&(1<<uint(t.Weekday())) != 0
+	if p.domStar || p.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
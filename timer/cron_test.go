@@ -0,0 +1,53 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+// 日 与 周 的组合. 2026-01-01 为周四
+func TestCronMatchDay(t *testing.T) {
+	tests := []struct {
+		spec string
+		day  int
+		want bool
+	}{
+		{"0 0 * * *", 2, true},
+		{"0 0 13 * *", 13, true},
+		{"0 0 13 * *", 14, false},
+		{"0 0 * * 1", 12, true},
+		{"0 0 * * 1", 13, false},
+		{"0 0 13 * 5", 2, true},      // 周五
+		{"0 0 13 * 5", 13, true},     // 13 日
+		{"0 0 13 * 5", 14, false},    // 都不满足
+		{"0 0 1,15 * 1", 15, true},   // 15 日
+		{"0 0 1,15 * 1", 12, true},   // 周一
+		{"0 0 1,15 * 1", 13, false},  // 都不满足
+		{"0 0 */2 * 1", 5, true},     // 日 以 * 开头, 需都满足: 单数日, 周一
+		{"0 0 */2 * 1", 3, false},    // 单数日
+		{"0 0 */2 * 1", 12, false},   // 周一
+		{"0 0 1-7 * */3", 3, true},   // 周 以 * 开头, 需都满足: 周六
+		{"0 0 1-7 * */3", 2, false},  // 周五
+		{"0 0 1-7 * */3", 10, false}, // 周六
+	}
+	for _, test := range tests {
+		cron, err := ParseCron(test.spec)
+		if err != nil {
+			t.Fatalf("spec:%v err:%v", test.spec, err)
+		}
+		if got := cron.matchDay(time.Date(2026, 1, test.day, 0, 0, 0, 0, time.UTC)); got != test.want {
+			t.Errorf("spec:%v day:%v got:%v want:%v", test.spec, test.day, got, test.want)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	cron, err := ParseCron("0 0 */2 * 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := cron.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Fatalf("next:%v want:%v", next, want)
+	}
+}
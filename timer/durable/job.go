@@ -0,0 +1,158 @@
+// Package durable 持久化的定时任务
+//
+//	任务定义保存在 IStore 中, 重启后由 Scheduler.Start 重新加载, 错过的触发按任务的 MisfirePolicy 补发
+//	到期时按任务类型找到注册的 JobFunc, 在 out 的处理协程中执行
+//	时间使用叠加偏移量的时间戳(秒), 与 xtimer 的秒级定时器一致. cron 按日历(xtime.GCalendar)的时区计算, 与 xtimer.AddCron 一致
+package durable
+
+import (
+	"time"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	xtime "github.com/75912001/xlib/time"
+	xtimer "github.com/75912001/xlib/timer"
+	"github.com/pkg/errors"
+)
+
+// MisfirePolicy 错过触发(e.g.: 停服期间到期)的处理策略
+type MisfirePolicy uint8
+
+const (
+	MisfireFireOnce MisfirePolicy = 0 // 补发一次(最近一次错过的触发时刻) [default]
+	MisfireFireAll  MisfirePolicy = 1 // 逐次补发, 最多 MisfireFireAllMax 次
+	MisfireSkip     MisfirePolicy = 2 // 不补发. 一次性任务直接删除
+)
+
+// MisfireFireAllMax MisfireFireAll 最多补发的次数, 超出的不补发
+const MisfireFireAllMax = 1024
+
+// JobFunc 任务回调, 在 out 的处理协程中执行
+//
+//	fireTime: 本次触发的时刻(秒), 补发时为错过的触发时刻
+type JobFunc func(job *Job, fireTime int64) error
+
+// Job 任务定义
+type Job struct {
+	ID       string        `json:"id"`                 // 唯一标识 e.g.: "auction.expire.1001"
+	Type     string        `json:"type"`               // 任务类型, 对应 Scheduler.Register 注册的回调
+	Expire   int64         `json:"expire"`             // 下次到期时间戳(秒, 叠加偏移量)
+	Interval int64         `json:"interval,omitempty"` // 重复间隔(秒), 0:不按间隔重复
+	Cron     string        `json:"cron,omitempty"`     // cron 表达式, 见 xtimer.Cron. 与 Interval 互斥
+	Misfire  MisfirePolicy `json:"misfire,omitempty"`  // 错过触发的处理策略
+	Data     []byte        `json:"data,omitempty"`     // 业务数据
+}
+
+// IsRepeat 是否重复任务
+func (p *Job) IsRepeat() bool {
+	return p.Interval > 0 || p.Cron != ""
+}
+
+// 检查, 补全首次到期时间
+func (p *Job) check(now int64) error {
+	if p.ID == "" {
+		return errors.WithMessagef(xerror.Param, "job id is empty. %v", xruntime.Location())
+	}
+	if p.Interval < 0 {
+		return errors.WithMessagef(xerror.Param, "job:%v interval:%v %v", p.ID, p.Interval, xruntime.Location())
+	}
+	if p.Interval > 0 && p.Cron != "" {
+		return errors.WithMessagef(xerror.Param, "job:%v interval and cron are both set. %v", p.ID, xruntime.Location())
+	}
+	if MisfireSkip < p.Misfire {
+		return errors.WithMessagef(xerror.Param, "job:%v misfire:%v %v", p.ID, p.Misfire, xruntime.Location())
+	}
+	if p.Expire != 0 {
+		return nil
+	}
+	switch {
+	case p.Interval > 0:
+		p.Expire = now + p.Interval
+	case p.Cron != "":
+		next, err := p.next(now)
+		if err != nil {
+			return errors.WithMessagef(err, "job:%v %v", p.ID, xruntime.Location())
+		}
+		if next == 0 {
+			return errors.WithMessagef(xerror.OutOfRange, "job:%v cron:%v never fires. %v", p.ID, p.Cron, xruntime.Location())
+		}
+		p.Expire = next
+	default:
+		return errors.WithMessagef(xerror.Param, "job:%v expire is 0. %v", p.ID, xruntime.Location())
+	}
+	return nil
+}
+
+// 晚于 prev 的下一次触发时刻, 0:不再触发
+func (p *Job) next(prev int64) (int64, error) {
+	if p.Interval > 0 {
+		return prev + p.Interval, nil
+	}
+	if p.Cron == "" {
+		return 0, nil
+	}
+	cron, err := xtimer.ParseCron(p.Cron)
+	if err != nil {
+		return 0, errors.WithMessagef(err, "cron:%v %v", p.Cron, xruntime.Location())
+	}
+	next := cron.Next(time.Unix(prev, 0).In(xtime.GCalendar.GetLocation()))
+	if next.IsZero() {
+		return 0, nil
+	}
+	return next.Unix(), nil
+}
+
+// 截至 now 错过的触发时刻, 按策略. 调用时 Expire <= now
+func (p *Job) misfireTimes(now int64) ([]int64, error) {
+	if p.Misfire == MisfireSkip {
+		return nil, nil
+	}
+	if !p.IsRepeat() {
+		return []int64{p.Expire}, nil
+	}
+	if p.Interval > 0 { // 按间隔, 直接计算
+		count := (now-p.Expire)/p.Interval + 1
+		if p.Misfire == MisfireFireOnce {
+			return []int64{p.Expire + (count-1)*p.Interval}, nil
+		}
+		fireTimes := make([]int64, 0, min(count, MisfireFireAllMax))
+		for i := int64(0); i < count && i < MisfireFireAllMax; i++ {
+			fireTimes = append(fireTimes, p.Expire+i*p.Interval)
+		}
+		return fireTimes, nil
+	}
+	var fireTimes []int64
+	for expire := p.Expire; expire != 0 && expire <= now; {
+		if p.Misfire == MisfireFireOnce {
+			fireTimes = append(fireTimes[:0], expire)
+		} else if len(fireTimes) < MisfireFireAllMax {
+			fireTimes = append(fireTimes, expire)
+		}
+		var err error
+		if expire, err = p.next(expire); err != nil {
+			return nil, err
+		}
+	}
+	return fireTimes, nil
+}
+
+// 晚于 now 的下次到期时间, 0:不再触发
+func (p *Job) nextAfter(now int64) (int64, error) {
+	if !p.IsRepeat() {
+		return 0, nil
+	}
+	if p.Interval > 0 { // 按间隔, 直接计算
+		if now < p.Expire {
+			return p.Expire, nil
+		}
+		return p.Expire + ((now-p.Expire)/p.Interval+1)*p.Interval, nil
+	}
+	expire := p.Expire
+	for expire != 0 && expire <= now {
+		var err error
+		if expire, err = p.next(expire); err != nil {
+			return 0, err
+		}
+	}
+	return expire, nil
+}
@@ -0,0 +1,71 @@
+package durable
+
+import (
+	xcontrol "github.com/75912001/xlib/control"
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	xtimer "github.com/75912001/xlib/timer"
+	"github.com/pkg/errors"
+)
+
+// Options 调度器选项
+type Options struct {
+	store IStore        // 任务存储
+	timer xtimer.ITimer // 定时器 [default]: xtimer.GTimer. 使用定时器的时钟
+	out   xcontrol.IOut // 到期-输出, 回调在其处理协程中执行
+}
+
+// NewOptions 新的Options
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func (p *Options) WithStore(store IStore) *Options {
+	p.store = store
+	return p
+}
+
+func (p *Options) WithTimer(timer xtimer.ITimer) *Options {
+	p.timer = timer
+	return p
+}
+
+func (p *Options) WithIOut(out xcontrol.IOut) *Options {
+	p.out = out
+	return p
+}
+
+func (p *Options) merge(opts ...*Options) *Options {
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.store != nil {
+			p.store = opt.store
+		}
+		if opt.timer != nil {
+			p.timer = opt.timer
+		}
+		if opt.out != nil {
+			p.out = opt.out
+		}
+	}
+	return p
+}
+
+// 配置
+func (p *Options) configure() error {
+	if p.store == nil {
+		return errors.WithMessagef(xerror.Param, "store is nil. %v", xruntime.Location())
+	}
+	if p.timer == nil {
+		p.timer = xtimer.GTimer
+	}
+	if p.timer == nil {
+		return errors.WithMessagef(xerror.Param, "timer is nil. %v", xruntime.Location())
+	}
+	if p.out == nil {
+		return errors.WithMessagef(xerror.Param, "out is nil. %v", xruntime.Location())
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package durable
+
+import (
+	"sync"
+
+	xcontrol "github.com/75912001/xlib/control"
+	xerror "github.com/75912001/xlib/error"
+	xlog "github.com/75912001/xlib/log"
+	xruntime "github.com/75912001/xlib/runtime"
+	xtimer "github.com/75912001/xlib/timer"
+	"github.com/pkg/errors"
+)
+
+var GScheduler *Scheduler
+
+// Scheduler 持久化的任务调度器
+//
+//	先 Register 任务类型, 再 Start 加载存储中的任务
+//	到期后先执行回调, 再更新存储(重复任务保存下次到期时间, 一次性任务删除). 回调后进程崩溃, 重启时会再次触发(至少一次)
+//	运行中回调积压时, 重复任务错过的触发不补发, 下次为晚于当前时间的触发时刻
+type Scheduler struct {
+	options  *Options
+	mu       sync.Mutex
+	jobFuncs map[string]JobFunc // key:任务类型
+	entries  map[string]*entry  // key:任务ID
+	started  bool
+}
+
+// 调度中的任务
+type entry struct {
+	job       *Job
+	second    *xtimer.Second
+	fireTimes []int64 // 补发的触发时刻, nil:正常到期
+}
+
+// NewScheduler 新的调度器
+func NewScheduler(opts ...*Options) *Scheduler {
+	return &Scheduler{
+		options:  NewOptions().merge(opts...),
+		jobFuncs: make(map[string]JobFunc),
+		entries:  make(map[string]*entry),
+	}
+}
+
+// Register 注册任务类型
+func (p *Scheduler) Register(jobType string, jobFunc JobFunc) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.jobFuncs[jobType]; ok {
+		return errors.WithMessagef(xerror.Duplicate, "jobType:%v %v", jobType, xruntime.Location())
+	}
+	p.jobFuncs[jobType] = jobFunc
+	return nil
+}
+
+// Start 加载存储中的任务并开始调度
+//
+//	defaults: 创建时未设置的选项使用 defaults e.g.: 服务的定时器, actor
+//	未注册类型的任务保留在存储中, 不调度
+func (p *Scheduler) Start(defaults ...*Options) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return errors.WithMessagef(xerror.InvalidOperation, "scheduler is started. %v", xruntime.Location())
+	}
+	p.options = NewOptions().merge(defaults...).merge(p.options)
+	if err := p.options.configure(); err != nil {
+		return errors.WithMessagef(err, "configure failed. %v", xruntime.Location())
+	}
+	jobs, err := p.options.store.Load()
+	if err != nil {
+		return errors.WithMessagef(err, "store load failed. %v", xruntime.Location())
+	}
+	now := p.now()
+	for _, job := range jobs {
+		if _, ok := p.jobFuncs[job.Type]; !ok {
+			xlog.PrintfErr("job:%v type:%v unregistered, skip. %v", job.ID, job.Type, xruntime.Location())
+			continue
+		}
+		e := &entry{
+			job: job,
+		}
+		if now < job.Expire {
+			p.arm(e, job.Expire)
+			continue
+		}
+		// 错过的触发
+		if e.fireTimes, err = job.misfireTimes(now); err != nil {
+			return errors.WithMessagef(err, "job:%v %v", job.ID, xruntime.Location())
+		}
+		if len(e.fireTimes) != 0 { // 立即补发
+			p.arm(e, now)
+			continue
+		}
+		if err = p.advance(e, now); err != nil {
+			return errors.WithMessagef(err, "job:%v %v", job.ID, xruntime.Location())
+		}
+	}
+	p.started = true
+	return nil
+}
+
+// Stop 停止调度, 关闭存储. 存储中的任务保留
+func (p *Scheduler) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.started {
+		return nil
+	}
+	for _, e := range p.entries {
+		p.options.timer.DelSecond(e.second)
+	}
+	p.entries = make(map[string]*entry)
+	p.started = false
+	return p.options.store.Close()
+}
+
+// Add 添加任务, 已存在相同ID的任务则替换
+//
+//	Expire 为 0 时: 按 Interval/Cron 计算首次到期时间
+func (p *Scheduler) Add(job *Job) error {
+	job = cloneJob(job)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.started {
+		return errors.WithMessagef(xerror.InvalidOperation, "scheduler is not started. %v", xruntime.Location())
+	}
+	if err := job.check(p.now()); err != nil {
+		return errors.WithMessagef(err, "job check failed. %v", xruntime.Location())
+	}
+	if _, ok := p.jobFuncs[job.Type]; !ok {
+		return errors.WithMessagef(xerror.Unregistered, "job:%v type:%v %v", job.ID, job.Type, xruntime.Location())
+	}
+	if err := p.options.store.Save(job); err != nil {
+		return errors.WithMessagef(err, "store save failed. job:%v %v", job.ID, xruntime.Location())
+	}
+	if old, ok := p.entries[job.ID]; ok {
+		p.options.timer.DelSecond(old.second)
+	}
+	p.arm(&entry{job: job}, job.Expire)
+	return nil
+}
+
+// Cancel 取消任务, 从存储中删除
+func (p *Scheduler) Cancel(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.started {
+		return errors.WithMessagef(xerror.InvalidOperation, "scheduler is not started. %v", xruntime.Location())
+	}
+	if err := p.options.store.Delete(id); err != nil {
+		return errors.WithMessagef(err, "store delete failed. job:%v %v", id, xruntime.Location())
+	}
+	if e, ok := p.entries[id]; ok {
+		p.options.timer.DelSecond(e.second)
+		delete(p.entries, id)
+	}
+	return nil
+}
+
+// Get 获取调度中的任务(副本)
+func (p *Scheduler) Get(id string) (*Job, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.entries[id]
+	if !ok {
+		return nil, false
+	}
+	return cloneJob(e.job), true
+}
+
+// Len 调度中的任务数量
+func (p *Scheduler) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.entries)
+}
+
+// 叠加偏移量的时间戳-秒, 使用定时器的时钟
+func (p *Scheduler) now() int64 {
+	return p.options.timer.ShadowTimestamp()
+}
+
+// 安排到期 [持有锁]
+func (p *Scheduler) arm(e *entry, expire int64) {
+	e.second = p.options.timer.AddSecond(xcontrol.NewCallBack(onJob, p, e), expire, p.options.out)
+	p.entries[e.job.ID] = e
+}
+
+// 触发后, 安排下次到期或删除 [持有锁]
+func (p *Scheduler) advance(e *entry, now int64) error {
+	expire, err := e.job.nextAfter(now)
+	if err != nil {
+		return errors.WithMessagef(err, "job:%v %v", e.job.ID, xruntime.Location())
+	}
+	if expire == 0 {
+		delete(p.entries, e.job.ID)
+		return p.options.store.Delete(e.job.ID)
+	}
+	e.job.Expire = expire
+	e.fireTimes = nil
+	if err = p.options.store.Save(e.job); err != nil {
+		return errors.WithMessagef(err, "store save failed. job:%v %v", e.job.ID, xruntime.Location())
+	}
+	p.arm(e, expire)
+	return nil
+}
+
+// 到期, 在 out 的处理协程中执行
+func onJob(args ...any) error {
+	p := args[0].(*Scheduler)
+	e := args[1].(*entry)
+
+	p.mu.Lock()
+	if p.entries[e.job.ID] != e { // 已取消/替换
+		p.mu.Unlock()
+		return nil
+	}
+	jobFunc := p.jobFuncs[e.job.Type]
+	fireTimes := e.fireTimes
+	if fireTimes == nil {
+		fireTimes = []int64{e.job.Expire}
+	}
+	job := cloneJob(e.job)
+	p.mu.Unlock()
+
+	var errFunc error
+	for _, fireTime := range fireTimes {
+		if err := jobFunc(job, fireTime); err != nil && errFunc == nil {
+			errFunc = errors.WithMessagef(err, "job:%v fireTime:%v %v", job.ID, fireTime, xruntime.Location())
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.entries[e.job.ID] != e { // 回调中取消/替换
+		return errFunc
+	}
+	if err := p.advance(e, p.now()); err != nil {
+		return errors.WithMessagef(err, "job:%v advance failed. %v", e.job.ID, xruntime.Location())
+	}
+	return errFunc
+}
+
+func cloneJob(job *Job) *Job {
+	v := *job
+	v.Data = append([]byte(nil), job.Data...)
+	return &v
+}
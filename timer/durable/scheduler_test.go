@@ -0,0 +1,127 @@
+package durable
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	xclock "github.com/75912001/xlib/clock"
+	xcontrol "github.com/75912001/xlib/control"
+	xtime "github.com/75912001/xlib/time"
+	xtimer "github.com/75912001/xlib/timer"
+)
+
+// 同步执行到期回调
+type syncOut struct{}
+
+func (p *syncOut) Send(events ...any) {
+	for _, v := range events {
+		event := v.(*xcontrol.Event)
+		if event.ISwitch.IsOn() {
+			_ = event.ICallBack.Execute()
+		}
+	}
+}
+
+func newTestScheduler(t *testing.T, timer xtimer.ITimer, path string, jobFunc JobFunc) *Scheduler {
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduler := NewScheduler(NewOptions().WithStore(store).WithTimer(timer).WithIOut(&syncOut{}))
+	if err = scheduler.Register("test", jobFunc); err != nil {
+		t.Fatal(err)
+	}
+	if err = scheduler.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return scheduler
+}
+
+func TestSchedulerVirtualClock(t *testing.T) {
+	clock := xclock.NewVirtual(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	timer := xtimer.NewTimerWithClock(clock)
+	if err := timer.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer timer.Stop()
+	start := clock.Now().Unix()
+	path := filepath.Join(t.TempDir(), "jobs.json")
+
+	var fireTimes []int64
+	jobFunc := func(_ *Job, fireTime int64) error {
+		fireTimes = append(fireTimes, fireTime)
+		return nil
+	}
+	scheduler := newTestScheduler(t, timer, path, jobFunc)
+	if err := scheduler.Add(&Job{ID: "job.1", Type: "test", Interval: 10}); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(25 * time.Second)
+	if len(fireTimes) != 2 || fireTimes[0] != start+10 || fireTimes[1] != start+20 {
+		t.Fatalf("fireTimes:%v start:%v", fireTimes, start)
+	}
+	if err := scheduler.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 停止期间错过 start+30, start+40, start+50. 重新启动时按 MisfireFireOnce 补发最近一次
+	clock.Advance(30 * time.Second)
+	fireTimes = nil
+	scheduler = newTestScheduler(t, timer, path, jobFunc)
+	defer func() { _ = scheduler.Stop() }()
+	clock.Advance(time.Second)
+	if len(fireTimes) != 1 || fireTimes[0] != start+50 {
+		t.Fatalf("fireTimes:%v start:%v", fireTimes, start)
+	}
+	clock.Advance(10 * time.Second)
+	if len(fireTimes) != 2 || fireTimes[1] != start+60 {
+		t.Fatalf("fireTimes:%v start:%v", fireTimes, start)
+	}
+}
+
+// cron 按日历的时区计算, 与进程本地时区无关
+func TestJobCronCalendarLocation(t *testing.T) {
+	calendar, err := xtime.NewCalendar(xtime.NewCalendarOptions().WithTimezone("Asia/Tokyo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(calendar *xtime.Calendar) { xtime.GCalendar = calendar }(xtime.GCalendar)
+	xtime.GCalendar = calendar
+
+	// 每天 09:00 (东京) 即 00:00 (UTC)
+	job := &Job{ID: "job.cron", Type: "test", Cron: "0 9 * * *"}
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	const daySecond = 24 * 60 * 60
+	if err = job.check(day - 1); err != nil {
+		t.Fatal(err)
+	}
+	if job.Expire != day {
+		t.Fatalf("expire:%v want:%v", job.Expire, day)
+	}
+	next, err := job.next(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != day+daySecond {
+		t.Fatalf("next:%v want:%v", next, day+daySecond)
+	}
+
+	job.Misfire = MisfireFireAll
+	fireTimes, err := job.misfireTimes(day + 2*daySecond + 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fireTimes) != 3 || fireTimes[0] != day || fireTimes[1] != day+daySecond || fireTimes[2] != day+2*daySecond {
+		t.Fatalf("fireTimes:%v day:%v", fireTimes, day)
+	}
+	nextAfter, err := job.nextAfter(day + 2*daySecond + 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nextAfter != day+3*daySecond {
+		t.Fatalf("nextAfter:%v want:%v", nextAfter, day+3*daySecond)
+	}
+}
@@ -0,0 +1,134 @@
+package durable
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	"github.com/pkg/errors"
+)
+
+// FileStore 本地文件存储
+//
+//	全部任务以 json 保存在一个文件中, 每次修改先写临时文件再替换(rename), 进程崩溃时文件保持完整
+//	适用于任务数量不多(万级以内)的场景
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	jobs map[string]*Job
+}
+
+// NewFileStore 新的文件存储, 文件存在时读取
+//
+//	path: 文件路径 e.g.: absPath/jobs.json
+func NewFileStore(path string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, errors.WithMessagef(err, "mkdir all failed. path:%v %v", path, xruntime.Location())
+	}
+	store := &FileStore{
+		path: path,
+		jobs: make(map[string]*Job),
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, errors.WithMessagef(err, "read file failed. path:%v %v", path, xruntime.Location())
+	}
+	var jobs []*Job
+	if err = json.Unmarshal(data, &jobs); err != nil {
+		return nil, errors.WithMessagef(xerror.Unmarshal, "path:%v err:%v %v", path, err, xruntime.Location())
+	}
+	for _, job := range jobs {
+		store.jobs[job.ID] = job
+	}
+	return store, nil
+}
+
+func (p *FileStore) Load() ([]*Job, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	jobs := make([]*Job, 0, len(p.jobs))
+	for _, job := range p.jobs {
+		v := *job
+		jobs = append(jobs, &v)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID < jobs[j].ID
+	})
+	return jobs, nil
+}
+
+func (p *FileStore) Save(job *Job) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	old := p.jobs[job.ID]
+	v := *job
+	p.jobs[job.ID] = &v
+	if err := p.flush(); err != nil {
+		if old != nil {
+			p.jobs[job.ID] = old
+		} else {
+			delete(p.jobs, job.ID)
+		}
+		return errors.WithMessagef(err, "job:%v %v", job.ID, xruntime.Location())
+	}
+	return nil
+}
+
+func (p *FileStore) Delete(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	old, ok := p.jobs[id]
+	if !ok {
+		return nil
+	}
+	delete(p.jobs, id)
+	if err := p.flush(); err != nil {
+		p.jobs[id] = old
+		return errors.WithMessagef(err, "job:%v %v", id, xruntime.Location())
+	}
+	return nil
+}
+
+func (p *FileStore) Close() error {
+	return nil
+}
+
+// 写入文件: 临时文件 -> 同步 -> 替换
+func (p *FileStore) flush() error {
+	jobs := make([]*Job, 0, len(p.jobs))
+	for _, job := range p.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID < jobs[j].ID
+	})
+	data, err := json.Marshal(jobs)
+	if err != nil {
+		return errors.WithMessagef(xerror.Marshal, "err:%v %v", err, xruntime.Location())
+	}
+	tmpPath := p.path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.WithMessagef(err, "open file failed. path:%v %v", tmpPath, xruntime.Location())
+	}
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return errors.WithMessagef(err, "write file failed. path:%v %v", tmpPath, xruntime.Location())
+	}
+	if err = os.Rename(tmpPath, p.path); err != nil {
+		return errors.WithMessagef(err, "rename failed. path:%v %v", p.path, xruntime.Location())
+	}
+	return nil
+}
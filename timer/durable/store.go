@@ -0,0 +1,11 @@
+package durable
+
+// IStore 任务存储
+//
+//	由 Scheduler 串行调用
+type IStore interface {
+	Load() ([]*Job, error)  // 加载全部任务
+	Save(job *Job) error    // 保存任务, 已存在则覆盖
+	Delete(id string) error // 删除任务, 不存在时忽略
+	Close() error           // 关闭
+}
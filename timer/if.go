@@ -2,6 +2,7 @@ package timer
 
 import (
 	"context"
+	xclock "github.com/75912001/xlib/clock"
 	xcontrol "github.com/75912001/xlib/control"
 )
 
@@ -17,9 +18,20 @@ type ITimerMillisecond interface {
 	DelMillisecond(millisecond *Millisecond)
 }
 
+// ITimerSchedule 周期定时器. 每次到期向 out 发送事件, 通过 Schedule.Cancel 取消
+type ITimerSchedule interface {
+	AddRepeatSecond(callBackFunc xcontrol.ICallBack, intervalSecond int64, out xcontrol.IOut) (*Schedule, error)           // 固定间隔(秒), 叠加偏移量
+	AddRepeatMillisecond(callBackFunc xcontrol.ICallBack, intervalMillisecond int64, out xcontrol.IOut) (*Schedule, error) // 固定间隔(毫秒)
+	AddCron(callBackFunc xcontrol.ICallBack, spec string, out xcontrol.IOut) (*Schedule, error)                            // cron 表达式 e.g.: "0 5 * * *", 日历时区, 叠加偏移量
+	DelSchedule(schedule *Schedule)
+}
+
 type ITimer interface {
 	Start(ctx context.Context) error
 	Stop()
+	GetClock() xclock.IClock // 时钟. 使用该定时器的组件, 以该时钟计算到期时间
+	ShadowTimestamp() int64  // 叠加偏移量的时间戳-秒, 使用该定时器的时钟
 	ITimerSecond
 	ITimerMillisecond
+	ITimerSchedule
 }
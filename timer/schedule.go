@@ -0,0 +1,170 @@
+package timer
+
+import (
+	"sync/atomic"
+	"time"
+
+	xcontrol "github.com/75912001/xlib/control"
+	xerror "github.com/75912001/xlib/error"
+	xruntime "github.com/75912001/xlib/runtime"
+	xtime "github.com/75912001/xlib/time"
+	"github.com/pkg/errors"
+)
+
+// Schedule 周期定时器(固定间隔/cron)
+//
+//	每次到期向 out 发送 *xcontrol.Event, 在 out 的处理协程中执行回调前安排下一次
+//	错过的触发(e.g.: 处理协程积压)不补发, 下一次为晚于当前时间的触发时刻
+//	秒级/cron 使用定时器时钟叠加偏移量的时间戳, 毫秒级使用定时器时钟的时间
+type Schedule struct {
+	valid         xcontrol.ISwitchButton // 有效(false:已取消)
+	callBack      xcontrol.ICallBack     // 到期-回调函数
+	out           xcontrol.IOut          // 到期-输出
+	timer         *defaultTimer          // 所属定时器
+	next          func(prev int64) int64 // 下次到期时间. prev: 本次到期时间
+	isMillisecond bool                   // 是否毫秒级
+	expire        atomic.Int64           // 当前到期时间 秒/毫秒
+	cron          *Cron                  // cron 表达式, 非 cron 时为 nil
+}
+
+// Cancel 取消, 之后不再触发
+func (p *Schedule) Cancel() {
+	p.valid.Off()
+}
+
+// IsValid 是否有效(未取消)
+func (p *Schedule) IsValid() bool {
+	return p.valid.IsOn()
+}
+
+// GetExpire 下次到期时间 秒/毫秒
+func (p *Schedule) GetExpire() int64 {
+	return p.expire.Load()
+}
+
+// GetCron cron 表达式, 非 cron 时为 nil
+func (p *Schedule) GetCron() *Cron {
+	return p.cron
+}
+
+// 安排到期
+func (p *Schedule) arm(expire int64) {
+	p.expire.Store(expire)
+	callBack := xcontrol.NewCallBack(onSchedule, p)
+	if p.isMillisecond {
+		p.timer.addMillisecond(p.valid, callBack, expire, p.out)
+		return
+	}
+	p.timer.addSecond(p.valid, callBack, expire, p.out)
+}
+
+// 到期, 在 out 的处理协程中执行
+func onSchedule(args ...any) error {
+	p := args[0].(*Schedule)
+	if p.valid.IsOff() {
+		return nil
+	}
+	if next := p.next(p.GetExpire()); 0 < next { // 先安排下一次, 回调出错不影响
+		p.arm(next)
+	}
+	return p.callBack.Execute()
+}
+
+// 固定间隔的下次到期时间, 跳过已错过的
+func nextInterval(prev int64, interval int64, now int64) int64 {
+	next := prev + interval
+	if next <= now {
+		next += ((now-next)/interval + 1) * interval
+	}
+	return next
+}
+
+// AddRepeatSecond 添加秒级重复定时器
+//
+//	参数:
+//		callBackFunc: 回调接口
+//		intervalSecond: 间隔秒数. 首次到期为 当前时间戳(ShadowTimestamp)+间隔
+func (p *defaultTimer) AddRepeatSecond(callBackFunc xcontrol.ICallBack, intervalSecond int64, out xcontrol.IOut) (*Schedule, error) {
+	if intervalSecond <= 0 {
+		return nil, errors.WithMessagef(xerror.Param, "intervalSecond:%v %v", intervalSecond, xruntime.Location())
+	}
+	schedule := &Schedule{
+		valid:    xcontrol.NewSwitchButton(true),
+		callBack: callBackFunc,
+		out:      out,
+		timer:    p,
+		next: func(prev int64) int64 {
+			return nextInterval(prev, intervalSecond, p.ShadowTimestamp())
+		},
+	}
+	schedule.arm(p.ShadowTimestamp() + intervalSecond)
+	return schedule, nil
+}
+
+// AddRepeatMillisecond 添加毫秒级重复定时器
+//
+//	参数:
+//		callBackFunc: 回调接口
+//		intervalMillisecond: 间隔毫秒数. 首次到期为 当前毫秒时间戳+间隔
+func (p *defaultTimer) AddRepeatMillisecond(callBackFunc xcontrol.ICallBack, intervalMillisecond int64, out xcontrol.IOut) (*Schedule, error) {
+	if intervalMillisecond <= 0 {
+		return nil, errors.WithMessagef(xerror.Param, "intervalMillisecond:%v %v", intervalMillisecond, xruntime.Location())
+	}
+	schedule := &Schedule{
+		valid:    xcontrol.NewSwitchButton(true),
+		callBack: callBackFunc,
+		out:      out,
+		timer:    p,
+		next: func(prev int64) int64 {
+			return nextInterval(prev, intervalMillisecond, p.nowMillisecond())
+		},
+		isMillisecond: true,
+	}
+	schedule.arm(p.nowMillisecond() + intervalMillisecond)
+	return schedule, nil
+}
+
+// AddCron 添加 cron 定时器
+//
+//	参数:
+//		callBackFunc: 回调接口
+//		spec: cron 表达式, 见 Cron. 按日历(xtime.GCalendar)的时区, 叠加偏移量(ShadowTimestamp)计算
+func (p *defaultTimer) AddCron(callBackFunc xcontrol.ICallBack, spec string, out xcontrol.IOut) (*Schedule, error) {
+	cron, err := ParseCron(spec)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "spec:%v %v", spec, xruntime.Location())
+	}
+	nextCron := func(prev int64) int64 {
+		from := p.ShadowTimestamp()
+		if from < prev {
+			from = prev
+		}
+		next := cron.Next(time.Unix(from, 0).In(xtime.GCalendar.GetLocation()))
+		if next.IsZero() {
+			return 0
+		}
+		return next.Unix()
+	}
+	first := nextCron(0)
+	if first == 0 {
+		return nil, errors.WithMessagef(xerror.OutOfRange, "spec:%v never fires %v", spec, xruntime.Location())
+	}
+	schedule := &Schedule{
+		valid:    xcontrol.NewSwitchButton(true),
+		callBack: callBackFunc,
+		out:      out,
+		timer:    p,
+		next:     nextCron,
+		cron:     cron,
+	}
+	schedule.arm(first)
+	return schedule, nil
+}
+
+// DelSchedule 删除周期定时器
+func (p *defaultTimer) DelSchedule(schedule *Schedule) {
+	if schedule == nil {
+		return
+	}
+	schedule.Cancel()
+}
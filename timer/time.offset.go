@@ -1,18 +1,28 @@
 package timer
 
 import (
-	"sync/atomic"
-	"time"
+	xclock "github.com/75912001/xlib/clock"
+	xtime "github.com/75912001/xlib/time"
 )
 
-var timestampOffset atomic.Int64 // 时间戳偏移量
+// 时间戳偏移量统一保存在 xtime.GTimeMgr 中
 
-// SetTimestampOffset 设置时间戳偏移量
+// SetTimestampOffset 设置时间戳偏移量, 即 xtime.GTimeMgr.SetTimestampOffset
 func SetTimestampOffset(offset int64) {
-	timestampOffset.Store(offset)
+	xtime.GTimeMgr.SetTimestampOffset(offset)
 }
 
 // ShadowTimestamp 叠加偏移量的时间戳-秒
+//
+//	使用 GTimer 的时钟, 未设置 GTimer 时使用默认时钟 xclock.GClock
 func ShadowTimestamp() int64 {
-	return time.Now().Unix() + timestampOffset.Load()
+	if GTimer != nil {
+		return GTimer.ShadowTimestamp()
+	}
+	return xclock.GClock.Now().Unix() + GetTimestampOffset()
+}
+
+// GetTimestampOffset 获取时间戳偏移量, 即 xtime.GTimeMgr.GetTimestampOffset
+func GetTimestampOffset() int64 {
+	return xtime.GTimeMgr.GetTimestampOffset()
 }
@@ -7,6 +7,7 @@ import (
 	"container/heap"
 	"container/list"
 	"context"
+	xclock "github.com/75912001/xlib/clock"
 	xconfig "github.com/75912001/xlib/config"
 	xcontrol "github.com/75912001/xlib/control"
 	xerror "github.com/75912001/xlib/error"
@@ -19,10 +20,19 @@ import (
 )
 
 // 定时器
+//
+//	真实时钟: 由协程定时扫描
+//	虚拟时钟: 不启动协程, 加入的定时器暂存, 由虚拟时钟 Advance 同步加入并扫描
 type defaultTimer struct {
+	clock     xclock.IClock
+	virtual   xclock.IVirtual // 虚拟时钟, 真实时钟时为 nil
+	pendingMu sync.Mutex      // 保护 pending
+	pending   []any           // 虚拟时钟: 待加入的定时器 *Second/*Millisecond
+
 	secondSlice     [cycleSize]list.List // 时间轮-数组. 秒,数据
 	millisecondList list.List            // 毫秒级-list
 	milliTaskHeap   *MillisecondMinHeap  // 毫秒级-小顶堆
+	milliWheel      *MillisecondWheel    // 毫秒级-分层时间轮
 
 	cancelFunc      context.CancelFunc
 	waitGroup       sync.WaitGroup // Stop 等待信号
@@ -35,10 +45,36 @@ type defaultTimer struct {
 	millisecondCount uint64
 }
 
+// NewTimer 新的定时器, 使用默认时钟 xclock.GClock
 func NewTimer() ITimer {
-	return &defaultTimer{
+	return NewTimerWithClock(xclock.GClock)
+}
+
+// NewTimerWithClock 新的定时器, 使用指定时钟
+func NewTimerWithClock(clock xclock.IClock) ITimer {
+	timer := &defaultTimer{
+		clock:         clock,
 		milliTaskHeap: InitMilliTaskHeap(),
 	}
+	if virtual, ok := clock.(xclock.IVirtual); ok {
+		timer.virtual = virtual
+	}
+	return timer
+}
+
+// GetClock 时钟
+func (p *defaultTimer) GetClock() xclock.IClock {
+	return p.clock
+}
+
+// ShadowTimestamp 叠加偏移量的时间戳-秒
+func (p *defaultTimer) ShadowTimestamp() int64 {
+	return p.clock.Now().Unix() + GetTimestampOffset()
+}
+
+// 当前毫秒时间戳
+func (p *defaultTimer) nowMillisecond() int64 {
+	return p.clock.Now().UnixMilli()
 }
 
 // 每秒更新
@@ -63,22 +99,43 @@ func (p *defaultTimer) funcSecond(ctx context.Context) {
 			xlog.PrintInfo(xerror.GoroutineDone)
 			return
 		case v := <-p.secondChan:
-			s := v.(*Second)
-			duration := s.expire - ShadowTimestamp()
-			if duration < 0 { // 到期
-				duration = 0
-			}
-			cycleIdx := searchCycleIdx(duration)
-			p.pushBackCycle(s, cycleIdx)
-			p.secondCount++
-			sortByExpire(&p.secondSlice[cycleIdx])
+			p.pushSecond(v.(*Second))
 		case <-idleDelay.C:
 			idleDelay.Reset(scanSecondDuration)
-			p.scanSecond(ShadowTimestamp())
+			p.scanSecond(p.ShadowTimestamp())
 		}
 	}
 }
 
+// 加入秒级定时器 [定时器协程]
+func (p *defaultTimer) pushSecond(s *Second) {
+	duration := s.expire - p.ShadowTimestamp()
+	if duration < 0 { // 到期
+		duration = 0
+	}
+	cycleIdx := searchCycleIdx(duration)
+	if p.virtual != nil { // 虚拟时钟: 全部放在第 0 轮, 按到期时间有序, 扫描时不会遗漏
+		cycleIdx = 0
+	}
+	p.pushBackCycle(s, cycleIdx)
+	p.secondCount++
+	sortByExpire(&p.secondSlice[cycleIdx])
+}
+
+// 加入毫秒级定时器 [定时器协程]
+func (p *defaultTimer) pushMillisecond(millisecond *Millisecond) {
+	p.millisecondCount++
+	switch xconfig.GConfigMgr.Timer.GetMillisecondType() {
+	case xtimerconstants.MillisecondTypeList:
+		p.millisecondList.PushBack(millisecond)
+		sortByExpire(&p.millisecondList)
+	case xtimerconstants.MillisecondTypeMinHeap:
+		heap.Push(p.milliTaskHeap, NewMilliTask(millisecond.expire, millisecond))
+	case xtimerconstants.MillisecondTypeWheel:
+		p.milliWheel.Add(millisecond)
+	}
+}
+
 // 每 Millisecond 个毫秒更新
 func (p *defaultTimer) funcMillisecond(ctx context.Context) {
 	defer func() {
@@ -104,28 +161,25 @@ func (p *defaultTimer) funcMillisecond(ctx context.Context) {
 			xlog.PrintInfo(xerror.GoroutineDone)
 			return
 		case v := <-p.milliSecondChan:
-			millisecond := v.(*Millisecond)
-			p.millisecondCount++
-			switch xconfig.GConfigMgr.Timer.GetMillisecondType() {
-			case xtimerconstants.MillisecondTypeList:
-				p.millisecondList.PushBack(millisecond)
-				sortByExpire(&p.millisecondList)
-			case xtimerconstants.MillisecondTypeMinHeap:
-				heap.Push(p.milliTaskHeap, NewMilliTask(millisecond.expire, millisecond))
-			}
+			p.pushMillisecond(v.(*Millisecond))
 		case <-idleDelay.C:
 			nowMillisecond := time.Now().UnixMilli()
 			reset := scanMillisecondDuration - (time.Duration(nowMillisecond)-nextMillisecond)*time.Millisecond
 			idleDelay.Reset(reset)
 
 			nextMillisecond += scanMillisecond
-			p.scanMillisecond(nowMillisecond)
+			p.scanMillisecond(p.nowMillisecond())
 		}
 	}
 }
 
 // Start
 func (p *defaultTimer) Start(ctx context.Context) error {
+	p.milliWheel = NewMillisecondWheel(p.nowMillisecond())
+	if p.virtual != nil { // 虚拟时钟, 由 Advance 驱动
+		p.virtual.Subscribe(p)
+		return nil
+	}
 	ctxWithCancel, cancelFunc := context.WithCancel(ctx)
 	p.cancelFunc = cancelFunc
 
@@ -146,6 +200,10 @@ func (p *defaultTimer) Start(ctx context.Context) error {
 
 // Stop 停止服务
 func (p *defaultTimer) Stop() {
+	if p.virtual != nil {
+		p.virtual.Unsubscribe(p)
+		return
+	}
 	if p.cancelFunc != nil {
 		p.cancelFunc()
 		// 等待 Second, milliSecond goroutine退出.
@@ -162,12 +220,21 @@ func (p *defaultTimer) Stop() {
 //	返回值:
 //		毫秒定时器
 func (p *defaultTimer) AddMillisecond(callBackFunc xcontrol.ICallBack, expireMillisecond int64, out xcontrol.IOut) *Millisecond {
+	return p.addMillisecond(xcontrol.NewSwitchButton(true), callBackFunc, expireMillisecond, out)
+}
+
+// 添加毫秒级定时器, 使用指定的开关
+func (p *defaultTimer) addMillisecond(switchButton xcontrol.ISwitchButton, callBackFunc xcontrol.ICallBack, expireMillisecond int64, out xcontrol.IOut) *Millisecond {
 	t := &Millisecond{
 		ICallBack:     callBackFunc,
-		ISwitchButton: xcontrol.NewSwitchButton(true),
+		ISwitchButton: switchButton,
 		expire:        expireMillisecond,
 		IOut:          out,
 	}
+	if p.virtual != nil {
+		p.addPending(t)
+		return t
+	}
 	p.milliSecondChan <- t
 	return t
 }
@@ -230,8 +297,18 @@ func (p *defaultTimer) scanMillisecond(ms int64) {
 			)
 			p.millisecondCount--
 		}
+	case xtimerconstants.MillisecondTypeWheel:
+		before := p.milliWheel.Len()
+		p.milliWheel.Advance(ms, func(millisecond *Millisecond) {
+			millisecond.IOut.Send(
+				&xcontrol.Event{
+					ISwitch:   millisecond.ISwitchButton,
+					ICallBack: millisecond.ICallBack,
+				},
+			)
+		})
+		p.millisecondCount -= uint64(before - p.milliWheel.Len())
 	}
-
 }
 
 // AddSecond 添加秒级定时器
@@ -242,14 +319,23 @@ func (p *defaultTimer) scanMillisecond(ms int64) {
 //	返回值:
 //		秒定时器
 func (p *defaultTimer) AddSecond(callBackFunc xcontrol.ICallBack, expire int64, out xcontrol.IOut) *Second {
+	return p.addSecond(xcontrol.NewSwitchButton(true), callBackFunc, expire, out)
+}
+
+// 添加秒级定时器, 使用指定的开关
+func (p *defaultTimer) addSecond(switchButton xcontrol.ISwitchButton, callBackFunc xcontrol.ICallBack, expire int64, out xcontrol.IOut) *Second {
 	t := &Second{
 		Millisecond: &Millisecond{
-			ISwitchButton: xcontrol.NewSwitchButton(true),
+			ISwitchButton: switchButton,
 			ICallBack:     callBackFunc,
 			expire:        expire,
 			IOut:          out,
 		},
 	}
+	if p.virtual != nil {
+		p.addPending(t)
+		return t
+	}
 	p.secondChan <- t
 	return t
 }
@@ -0,0 +1,95 @@
+package timer
+
+import (
+	"time"
+
+	xconfig "github.com/75912001/xlib/config"
+	xtimerconstants "github.com/75912001/xlib/timer/constants"
+)
+
+// 虚拟时钟: 暂存加入的定时器, 在 OnAdvance 中加入
+func (p *defaultTimer) addPending(t any) {
+	p.pendingMu.Lock()
+	defer p.pendingMu.Unlock()
+	p.pending = append(p.pending, t)
+}
+
+// 虚拟时钟: 取出暂存的定时器
+func (p *defaultTimer) takePending() []any {
+	p.pendingMu.Lock()
+	defer p.pendingMu.Unlock()
+	pending := p.pending
+	p.pending = nil
+	return pending
+}
+
+// OnAdvance 虚拟时钟推进到 now, 加入暂存的定时器, 扫描到期的定时器
+//
+//	到期事件同步发送到 out. 若 out 同步执行回调, 回调中加入的已到期定时器在本次推进中触发
+func (p *defaultTimer) OnAdvance(now time.Time) {
+	for {
+		pending := p.takePending()
+		for _, v := range pending {
+			switch t := v.(type) {
+			case *Second:
+				p.pushSecond(t)
+			case *Millisecond:
+				p.pushMillisecond(t)
+			}
+		}
+		p.scanMillisecond(now.UnixMilli())
+		p.scanSecond(p.ShadowTimestamp())
+		if len(pending) == 0 {
+			return
+		}
+	}
+}
+
+// NextDeadline 最早的到期时间, 有暂存的定时器时为当前时间
+func (p *defaultTimer) NextDeadline() (time.Time, bool) {
+	p.pendingMu.Lock()
+	pendingLen := len(p.pending)
+	p.pendingMu.Unlock()
+	if pendingLen != 0 {
+		return p.clock.Now(), true
+	}
+	var deadline time.Time
+	found := false
+	if expire, ok := p.nextMillisecondExpire(); ok {
+		deadline, found = time.UnixMilli(expire), true
+	}
+	for e := p.secondSlice[0].Front(); e != nil; e = e.Next() { // 虚拟时钟: 秒级定时器都在第 0 轮, 有序
+		t := e.Value.(*Second)
+		if t.ISwitchButton.IsOff() {
+			continue
+		}
+		// 到期时间叠加了偏移量, 换算回时钟时间
+		secondDeadline := time.Unix(t.expire-GetTimestampOffset(), 0)
+		if !found || secondDeadline.Before(deadline) {
+			deadline, found = secondDeadline, true
+		}
+		break
+	}
+	return deadline, found
+}
+
+// 最早的毫秒级到期时间(可以提前)
+func (p *defaultTimer) nextMillisecondExpire() (int64, bool) {
+	switch xconfig.GConfigMgr.Timer.GetMillisecondType() {
+	case xtimerconstants.MillisecondTypeList:
+		for e := p.millisecondList.Front(); e != nil; e = e.Next() {
+			t := e.Value.(*Millisecond)
+			if t.ISwitchButton.IsOff() {
+				continue
+			}
+			return t.expire, true
+		}
+	case xtimerconstants.MillisecondTypeMinHeap:
+		if p.milliTaskHeap.Len() > 0 {
+			return (*p.milliTaskHeap)[0].expire, true
+		}
+	case xtimerconstants.MillisecondTypeWheel:
+		return p.milliWheel.NextTick()
+	}
+	return 0, false
+}
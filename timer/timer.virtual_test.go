@@ -0,0 +1,83 @@
+package timer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	xclock "github.com/75912001/xlib/clock"
+	xcontrol "github.com/75912001/xlib/control"
+)
+
+// 同步执行到期回调
+type syncOut struct{}
+
+func (p *syncOut) Send(events ...any) {
+	for _, v := range events {
+		event := v.(*xcontrol.Event)
+		if event.ISwitch.IsOn() {
+			_ = event.ICallBack.Execute()
+		}
+	}
+}
+
+func newVirtualTimer(t *testing.T) (*xclock.Virtual, ITimer) {
+	clock := xclock.NewVirtual(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	timer := NewTimerWithClock(clock)
+	if err := timer.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(timer.Stop)
+	return clock, timer
+}
+
+func TestVirtualMillisecond(t *testing.T) {
+	clock, timer := newVirtualTimer(t)
+	var fired int
+	timer.AddMillisecond(xcontrol.NewCallBack(func(...any) error { fired++; return nil }),
+		clock.Now().UnixMilli()+100, &syncOut{})
+	clock.Advance(99 * time.Millisecond)
+	if fired != 0 {
+		t.Fatalf("fired:%v before expire", fired)
+	}
+	clock.Advance(time.Millisecond)
+	if fired != 1 {
+		t.Fatalf("fired:%v want:1", fired)
+	}
+}
+
+func TestVirtualRepeatSecond(t *testing.T) {
+	clock, timer := newVirtualTimer(t)
+	var fired int
+	schedule, err := timer.AddRepeatSecond(xcontrol.NewCallBack(func(...any) error { fired++; return nil }), 2, &syncOut{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(7 * time.Second)
+	if fired != 3 {
+		t.Fatalf("fired:%v want:3", fired)
+	}
+	timer.DelSchedule(schedule)
+	clock.Advance(10 * time.Second)
+	if fired != 3 {
+		t.Fatalf("fired:%v after cancel", fired)
+	}
+}
+
+func TestVirtualShadowTimestamp(t *testing.T) {
+	clock, timer := newVirtualTimer(t)
+	GTimer = timer
+	SetTimestampOffset(10)
+	t.Cleanup(func() {
+		GTimer = nil
+		SetTimestampOffset(0)
+	})
+	start := clock.Now().Unix()
+	if got := ShadowTimestamp(); got != start+10 {
+		t.Fatalf("ShadowTimestamp:%v want:%v", got, start+10)
+	}
+	clock.Advance(5 * time.Second)
+	if got := ShadowTimestamp(); got != start+15 {
+		t.Fatalf("ShadowTimestamp:%v want:%v", got, start+15)
+	}
+}
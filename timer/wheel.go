@@ -0,0 +1,167 @@
+package timer
+
+import "container/list"
+
+// 分层时间轮
+//
+//	刻度 1 毫秒, 4 层: 256 * 64 * 64 * 64 个刻度 约 18.6 小时, 超出的放在最高层最远的槽, 降层时重新计算
+//	加入 O(1): 按到期时间直接放入对应的槽
+//	删除 O(1): 标记(ISwitchButton.Off), 到达该槽或降层时移除, 不扫描其它定时器
+//	同一刻度按加入顺序到期
+const (
+	wheelLevel0Bits = 8
+	wheelLevelNBits = 6
+	wheelLevelCount = 4
+
+	wheelLevel0Size = 1 << wheelLevel0Bits
+	wheelLevelNSize = 1 << wheelLevelNBits
+	wheelLevel0Mask = wheelLevel0Size - 1
+	wheelLevelNMask = wheelLevelNSize - 1
+
+	// 时间轮的最大跨度(刻度)
+	wheelMaxSpan = int64(1) << (wheelLevel0Bits + (wheelLevelCount-1)*wheelLevelNBits)
+)
+
+// MillisecondWheel 毫秒-分层时间轮 [非并发安全, 在定时器协程中使用]
+type MillisecondWheel struct {
+	current int64                        // 下一个待处理的刻度(毫秒时间戳)
+	levels  [wheelLevelCount][]list.List // 各层的槽
+	count   int                          // 定时器数量(含已标记删除, 未移除的)
+}
+
+// NewMillisecondWheel 新的时间轮
+//
+//	nowMillisecond: 当前毫秒时间戳
+func NewMillisecondWheel(nowMillisecond int64) *MillisecondWheel {
+	wheel := &MillisecondWheel{
+		current: nowMillisecond,
+	}
+	wheel.levels[0] = make([]list.List, wheelLevel0Size)
+	for level := 1; level < wheelLevelCount; level++ {
+		wheel.levels[level] = make([]list.List, wheelLevelNSize)
+	}
+	return wheel
+}
+
+// Len 定时器数量(含已标记删除, 未移除的)
+func (p *MillisecondWheel) Len() int {
+	return p.count
+}
+
+// Add 加入定时器
+func (p *MillisecondWheel) Add(millisecond *Millisecond) {
+	p.place(millisecond)
+	p.count++
+}
+
+// 按到期时间放入对应的槽
+func (p *MillisecondWheel) place(millisecond *Millisecond) {
+	expire := millisecond.expire
+	delta := expire - p.current
+	if delta < 0 { // 已到期, 放入下一个刻度
+		expire, delta = p.current, 0
+	}
+	if wheelMaxSpan <= delta { // 超出跨度, 放在最远处, 降层时重新计算
+		expire, delta = p.current+wheelMaxSpan-1, wheelMaxSpan-1
+	}
+	if delta < wheelLevel0Size {
+		p.levels[0][expire&wheelLevel0Mask].PushBack(millisecond)
+		return
+	}
+	for level := 1; level < wheelLevelCount; level++ {
+		shift := wheelLevel0Bits + level*wheelLevelNBits
+		if delta < int64(1)<<shift {
+			idx := (expire >> (shift - wheelLevelNBits)) & wheelLevelNMask
+			p.levels[level][idx].PushBack(millisecond)
+			return
+		}
+	}
+}
+
+// NextTick 下一个需要处理的刻度(可以提前): 第 0 层最近的非空槽, 或上层最近的非空槽降层的刻度
+func (p *MillisecondWheel) NextTick() (int64, bool) {
+	if p.count == 0 {
+		return 0, false
+	}
+	next := p.current + wheelMaxSpan
+	for tick := p.current; tick < p.current+wheelLevel0Size; tick++ { // 第 0 层的定时器都在 1 圈内
+		if p.levels[0][tick&wheelLevel0Mask].Len() != 0 {
+			next = tick
+			break
+		}
+	}
+	for level := 1; level < wheelLevelCount; level++ {
+		shift := wheelLevel0Bits + (level-1)*wheelLevelNBits
+		unit := int64(1) << shift
+		tick := (p.current + unit - 1) &^ (unit - 1) // 该层下一次降层的刻度
+		for i := 0; i < wheelLevelNSize && tick < next; i, tick = i+1, tick+unit {
+			if p.levels[level][(tick>>shift)&wheelLevelNMask].Len() != 0 {
+				next = tick
+				break
+			}
+		}
+	}
+	return next, true
+}
+
+// Advance 推进到 nowMillisecond, 到期的定时器调用 onExpire, 已标记删除的移除
+func (p *MillisecondWheel) Advance(nowMillisecond int64, onExpire func(millisecond *Millisecond)) {
+	if p.count == 0 { // 空, 直接推进
+		if p.current <= nowMillisecond {
+			p.current = nowMillisecond + 1
+		}
+		return
+	}
+	for p.current <= nowMillisecond {
+		if p.current&wheelLevel0Mask != 0 && p.levels[0][p.current&wheelLevel0Mask].Len() == 0 { // 空刻度, 跳到下一个需要处理的刻度
+			next, ok := p.NextTick()
+			if !ok || nowMillisecond < next {
+				p.current = nowMillisecond + 1
+				return
+			}
+			p.current = next
+			continue
+		}
+		if p.current&wheelLevel0Mask == 0 { // 第 0 层转完一圈, 上层逐层降下
+			for level := 1; level < wheelLevelCount; level++ {
+				shift := wheelLevel0Bits + (level-1)*wheelLevelNBits
+				idx := (p.current >> shift) & wheelLevelNMask
+				p.cascade(&p.levels[level][idx])
+				if idx != 0 {
+					break
+				}
+			}
+		}
+		slot := &p.levels[0][p.current&wheelLevel0Mask]
+		for e := slot.Front(); e != nil; e = slot.Front() {
+			millisecond := slot.Remove(e).(*Millisecond)
+			p.count--
+			if millisecond.ISwitchButton.IsOff() {
+				continue
+			}
+			onExpire(millisecond)
+		}
+		p.current++
+	}
+}
+
+// 降层: 槽中的定时器重新放入
+//
+//	先取出整个槽, 重新放入时可能回到该槽(下一圈)
+func (p *MillisecondWheel) cascade(slot *list.List) {
+	if slot.Len() == 0 {
+		return
+	}
+	milliseconds := make([]*Millisecond, 0, slot.Len())
+	for e := slot.Front(); e != nil; e = e.Next() {
+		milliseconds = append(milliseconds, e.Value.(*Millisecond))
+	}
+	slot.Init()
+	for _, millisecond := range milliseconds {
+		if millisecond.ISwitchButton.IsOff() {
+			p.count--
+			continue
+		}
+		p.place(millisecond)
+	}
+}
@@ -0,0 +1,98 @@
+package timer
+
+import (
+	"container/heap"
+	"math/rand"
+	"testing"
+
+	xcontrol "github.com/75912001/xlib/control"
+)
+
+// 到期时间范围 毫秒
+const benchSpanMillisecond = 2000
+
+func newBenchMilliseconds(n int, now int64) []*Millisecond {
+	r := rand.New(rand.NewSource(1))
+	milliseconds := make([]*Millisecond, n)
+	for i := range milliseconds {
+		milliseconds[i] = &Millisecond{
+			ISwitchButton: xcontrol.NewSwitchButton(true),
+			expire:        now + 1 + r.Int63n(benchSpanMillisecond),
+		}
+	}
+	return milliseconds
+}
+
+// 加入
+func BenchmarkMillisecondWheelAdd(b *testing.B) {
+	const now = 1_000_000
+	wheel := NewMillisecondWheel(now)
+	milliseconds := newBenchMilliseconds(b.N, now)
+	b.ResetTimer()
+	for _, millisecond := range milliseconds {
+		wheel.Add(millisecond)
+	}
+}
+
+// 加入, 推进至全部到期
+func BenchmarkMillisecondWheelAddAdvance(b *testing.B) {
+	const now = 1_000_000
+	wheel := NewMillisecondWheel(now)
+	milliseconds := newBenchMilliseconds(b.N, now)
+	var fired int
+	b.ResetTimer()
+	for _, millisecond := range milliseconds {
+		wheel.Add(millisecond)
+	}
+	for tick := int64(now); tick <= now+benchSpanMillisecond; tick++ {
+		wheel.Advance(tick, func(*Millisecond) { fired++ })
+	}
+	b.StopTimer()
+	if fired != b.N {
+		b.Fatalf("fired:%v want:%v", fired, b.N)
+	}
+}
+
+// 加入, 删除一半, 推进至全部到期
+func BenchmarkMillisecondWheelCancel(b *testing.B) {
+	const now = 1_000_000
+	wheel := NewMillisecondWheel(now)
+	milliseconds := newBenchMilliseconds(b.N, now)
+	var fired int
+	b.ResetTimer()
+	for _, millisecond := range milliseconds {
+		wheel.Add(millisecond)
+	}
+	for i := 0; i < len(milliseconds); i += 2 {
+		milliseconds[i].Delete()
+	}
+	for tick := int64(now); tick <= now+benchSpanMillisecond; tick++ {
+		wheel.Advance(tick, func(*Millisecond) { fired++ })
+	}
+	b.StopTimer()
+	if want := b.N / 2; fired != want {
+		b.Fatalf("fired:%v want:%v", fired, want)
+	}
+}
+
+// 小顶堆 加入, 弹出至全部到期. 与 BenchmarkMillisecondWheelAddAdvance 对比
+func BenchmarkMillisecondMinHeapAddPop(b *testing.B) {
+	const now = 1_000_000
+	minHeap := InitMilliTaskHeap()
+	milliseconds := newBenchMilliseconds(b.N, now)
+	var fired int
+	b.ResetTimer()
+	for _, millisecond := range milliseconds {
+		heap.Push(minHeap, NewMilliTask(millisecond.expire, millisecond))
+	}
+	for tick := int64(now); tick <= now+benchSpanMillisecond; tick++ {
+		for 0 < minHeap.Len() && (*minHeap)[0].expire <= tick {
+			heap.Pop(minHeap)
+			fired++
+		}
+	}
+	b.StopTimer()
+	if fired != b.N {
+		b.Fatalf("fired:%v want:%v", fired, b.N)
+	}
+}